@@ -3,16 +3,22 @@ package main
 import (
 	"context"
 	"errors"
+	"expvar"
+	"flag"
 	"fmt"
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"goods-service/internal/config"
+	"goods-service/internal/models"
 	"goods-service/internal/repository"
 	"goods-service/internal/service"
+	transportGrpc "goods-service/internal/transport/grpc"
 	transportHttp "goods-service/internal/transport/http"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -21,43 +27,71 @@ import (
 	"time"
 )
 
-func main() {
-	// Config
-	cfg := config.MustLoad()
+// Варианты хранилища, задаваемые флагом -storage
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+)
 
-	// PostgreSQL
-	pgPool := initPostgres(cfg)
-	defer pgPool.Close()
+// backend Хранилища и доставка событий, с которыми работают сервисы
+type backend struct {
+	goodStore    service.GoodStore
+	projectStore service.ProjectStore
+	outboxStore  service.OutboxStore
+	cache        service.GoodCache
+	idempotency  service.IdempotencyStore
+	rateLimits   service.RateLimitStore
+	authStore    service.AuthStore
+	eventLog     service.EventLog
+	publisher    service.EventPublisher
 
-	// Redis
-	redisClient := initRedis(cfg)
-	defer redisClient.Close()
+	// close Освобождает ресурсы после остановки сервера
+	close func(ctx context.Context)
+}
 
-	// ClickHouse
-	clickhouseConn := initClickhouse(cfg)
-	defer clickhouseConn.Close()
+func main() {
+	storage := flag.String("storage", storagePostgres, "storage backend: postgres or memory")
+	flag.Parse()
 
-	// NATS
-	natsConn := initNATS(cfg)
-	defer natsConn.Close()
+	// Config
+	cfg := config.MustLoad()
 
-	// Repos
-	postgresRepo := repository.NewPostgresRepository(pgPool)
-	redisRepo := repository.NewRedisRepository(redisClient)
-	clickhouseRepo := repository.NewClickhouseRepository(clickhouseConn)
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
 
-	// NATS service
-	natsSubscriber := service.NewNATSSubscriber(natsConn, clickhouseRepo)
-	if err := natsSubscriber.Subscribe(); err != nil {
-		log.Fatalf("failed to start NATS subscriber: %v", err)
+	var b *backend
+	switch *storage {
+	case storagePostgres:
+		b = initExternalBackend(bgCtx, cfg)
+	case storageMemory:
+		b = initMemoryBackend()
+	default:
+		log.Fatalf("unknown storage: %s", *storage)
 	}
 
 	// Service
-	goodService := service.NewGoodService(postgresRepo, redisRepo, clickhouseRepo, natsConn)
+	goodService := service.NewGoodService(b.goodStore, b.cache)
+	projectService := service.NewProjectService(b.projectStore, b.cache)
+	historyService := service.NewHistoryService(b.eventLog)
+	idempotencyService := service.NewIdempotencyService(b.idempotency, cfg.IdempotencyTTL, cfg.IdempotencyLockTTL)
+	authService := initAuth(cfg, b.authStore)
+	rateLimitService := initRateLimit(cfg, b.rateLimits)
+
+	// Outbox relay
+	outboxRelay := service.NewOutboxRelay(b.outboxStore, b.publisher, cfg.OutboxBatchSize, cfg.OutboxRetention)
+	go outboxRelay.Run(bgCtx, cfg.OutboxInterval)
+
+	// Purge of removed goods
+	if cfg.PurgeEnabled {
+		purgeService := service.NewPurgeService(b.goodStore, b.cache,
+			cfg.PurgeRetention, cfg.PurgeBatchSize, cfg.PurgeDryRun)
+		go purgeService.Run(bgCtx, cfg.PurgeInterval)
+	}
 
 	// Handler, Routes
-	handler := transportHttp.NewHandler(goodService)
+	handler := transportHttp.NewHandler(goodService, projectService, historyService, idempotencyService, authService, rateLimitService)
 	router := transportHttp.NewRouter(handler)
+	router.Handle("/debug/vars", expvar.Handler()).Methods(http.MethodGet)
 
 	// HTTP server
 	srv := &http.Server{
@@ -67,17 +101,33 @@ func main() {
 
 	// Start server
 	go func() {
-		log.Printf("starting server on port %s", cfg.HttpPort)
+		log.Printf("starting server on port %s, storage=%s", cfg.HttpPort, *storage)
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("server failed to start: %v", err)
 		}
 	}()
 
+	// gRPC server
+	grpcSrv := transportGrpc.NewServer(goodService, projectService, authService, rateLimitService)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GrpcPort)
+	if err != nil {
+		log.Fatalf("failed to listen gRPC port: %v", err)
+	}
+
+	go func() {
+		log.Printf("starting gRPC server on port %s", cfg.GrpcPort)
+		if err := grpcSrv.Serve(grpcListener); err != nil {
+			log.Fatalf("gRPC server failed to start: %v", err)
+		}
+	}()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	stopBackground()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -85,9 +135,164 @@ func main() {
 		log.Fatalf("server forced to shutdown: %v", err)
 	}
 
+	// Ждём завершения вызовов gRPC, пока не истечёт время на остановку
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcSrv.Stop()
+		log.Println("gRPC server forced to shutdown")
+	}
+
+	b.close(ctx)
+
 	log.Println("server exiting")
 }
 
+// initExternalBackend Подключается к PostgreSQL, Redis, ClickHouse и NATS
+// и запускает запись событий из NATS в ClickHouse
+func initExternalBackend(ctx context.Context, cfg *config.Config) *backend {
+	// PostgreSQL
+	pgPool := initPostgres(cfg)
+
+	// Redis
+	redisClient := initRedis(cfg)
+
+	// ClickHouse
+	clickhouseConn := initClickhouse(cfg)
+
+	// NATS
+	natsConn := initNATS(cfg)
+
+	// Repos
+	postgresRepo := repository.NewPostgresRepository(pgPool)
+	redisRepo := repository.NewRedisRepository(redisClient)
+	clickhouseRepo := repository.NewClickhouseRepository(clickhouseConn)
+
+	// События пишутся в ClickHouse пачками
+	batchWriter := repository.NewClickhouseBatchWriter(clickhouseConn, repository.BatchWriterOptions{
+		BatchSize:     cfg.ChBatchSize,
+		FlushInterval: cfg.ChFlushInterval,
+		MaxRetries:    cfg.ChMaxRetries,
+		RetryDelay:    cfg.ChRetryDelay,
+		SendTimeout:   cfg.ChSendTimeout,
+	})
+	expvar.Publish("clickhouse_batch_writer", expvar.Func(func() interface{} {
+		return batchWriter.Stats()
+	}))
+
+	// NATS service
+	publisher, jetStreamOptions := initEventDelivery(ctx, cfg, natsConn)
+	natsSubscriber := service.NewNATSSubscriber(natsConn, batchWriter, batchWriter.MaxWait(), jetStreamOptions)
+	if err := natsSubscriber.Subscribe(ctx); err != nil {
+		log.Fatalf("failed to start NATS subscriber: %v", err)
+	}
+
+	return &backend{
+		goodStore:    postgresRepo,
+		projectStore: postgresRepo,
+		outboxStore:  postgresRepo,
+		cache:        redisRepo,
+		idempotency:  redisRepo,
+		rateLimits:   redisRepo,
+		authStore:    postgresRepo,
+		eventLog:     clickhouseRepo,
+		publisher:    publisher,
+		close: func(ctx context.Context) {
+			// Дописываем накопленные события до закрытия соединений с NATS и ClickHouse
+			if err := batchWriter.Close(ctx); err != nil {
+				log.Printf("failed to flush ClickHouse batch: %v", err)
+			}
+
+			natsConn.Close()
+			clickhouseConn.Close()
+			redisClient.Close()
+			pgPool.Close()
+		},
+	}
+}
+
+// initMemoryBackend Создаёт хранилища в памяти процесса. События из outbox
+// доставляются сразу в лог событий, внешние сервисы не нужны
+func initMemoryBackend() *backend {
+	memoryRepo := repository.NewMemoryRepository()
+	memoryCache := repository.NewMemoryCache()
+	eventLog := repository.NewMemoryEventLog()
+
+	return &backend{
+		goodStore:    memoryRepo,
+		projectStore: memoryRepo,
+		outboxStore:  memoryRepo,
+		cache:        memoryCache,
+		idempotency:  memoryCache,
+		rateLimits:   memoryCache,
+		authStore:    repository.NewMemoryAuthStore(),
+		eventLog:     eventLog,
+		publisher:    service.NewMemoryPublisher(eventLog),
+		close:        func(ctx context.Context) {},
+	}
+}
+
+// initAuth Создаёт сервис аутентификации. При выключенной аутентификации
+// возвращает nil, и запросы обрабатываются без проверки клиента
+func initAuth(cfg *config.Config, store service.AuthStore) *service.AuthService {
+	if !cfg.AuthEnabled {
+		log.Println("authentication is disabled")
+		return nil
+	}
+
+	opts := service.JWTOptions{
+		Issuer:   cfg.AuthJWTIssuer,
+		Audience: cfg.AuthJWTAudience,
+	}
+	if cfg.AuthJWKSFile != "" {
+		keys, err := service.LoadJWKS(cfg.AuthJWKSFile)
+		if err != nil {
+			log.Fatalf("unable to load JWKS: %v", err)
+		}
+		opts.Keys = keys
+	}
+
+	return service.NewAuthService(store, opts, cfg.AuthCacheTTL)
+}
+
+// initRateLimit Создаёт сервис ограничения частоты запросов. При выключенных
+// лимитах возвращает nil
+func initRateLimit(cfg *config.Config, store service.RateLimitStore) *service.RateLimitService {
+	if !cfg.RateLimitEnabled {
+		log.Println("rate limiting is disabled")
+		return nil
+	}
+
+	var (
+		limits service.RateLimits
+		err    error
+	)
+	for _, limit := range []struct {
+		value  string
+		target *models.RateLimit
+	}{
+		{cfg.RateLimitAddress, &limits.Address},
+		{cfg.RateLimitClientRead, &limits.ClientRead},
+		{cfg.RateLimitClientWrite, &limits.ClientWrite},
+		{cfg.RateLimitProjectRead, &limits.ProjectRead},
+		{cfg.RateLimitProjectWrite, &limits.ProjectWrite},
+	} {
+		if *limit.target, err = service.ParseRateLimit(limit.value); err != nil {
+			log.Fatalf("invalid rate limit config: %v", err)
+		}
+	}
+	if limits.Routes, err = service.ParseRouteRateLimits(cfg.RateLimitRoutes); err != nil {
+		log.Fatalf("invalid rate limit config: %v", err)
+	}
+
+	return service.NewRateLimitService(store, limits)
+}
+
 func initPostgres(cfg *config.Config) *pgxpool.Pool {
 	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		cfg.DbUser,
@@ -143,3 +348,38 @@ func initNATS(cfg *config.Config) *nats.Conn {
 
 	return nc
 }
+
+// initEventDelivery Выбирает способ доставки событий в зависимости от режима NATS.
+// Для core NATS параметры JetStream не возвращаются
+func initEventDelivery(ctx context.Context, cfg *config.Config, nc *nats.Conn) (service.EventPublisher, *service.JetStreamOptions) {
+	switch cfg.NatsMode {
+	case config.NatsModeCore:
+		return service.NewCorePublisher(nc), nil
+	case config.NatsModeJetStream:
+	default:
+		log.Fatalf("unknown NATS mode: %s", cfg.NatsMode)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		log.Fatalf("unable to initialize JetStream: %v", err)
+	}
+
+	opts := &service.JetStreamOptions{
+		Stream:            cfg.NatsStream,
+		Consumer:          cfg.NatsConsumer,
+		MaxDeliver:        cfg.NatsMaxDeliver,
+		AckWait:           cfg.NatsAckWait,
+		RetryDelay:        cfg.NatsRetryDelay,
+		FetchBatch:        cfg.NatsFetchBatch,
+		DeadLetterStream:  cfg.NatsDeadLetterStream,
+		DeadLetterSubject: cfg.NatsDeadLetterSubject,
+	}
+
+	// Поток должен существовать до первой публикации из outbox
+	if err := service.EnsureJetStream(ctx, js, *opts); err != nil {
+		log.Fatalf("unable to create JetStream streams: %v", err)
+	}
+
+	return service.NewJetStreamPublisher(js), opts
+}
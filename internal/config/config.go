@@ -4,10 +4,12 @@ import (
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
 	"log"
+	"time"
 )
 
 type Config struct {
 	HttpPort string `env:"HTTP_PORT" envDefault:"8080"`
+	GrpcPort string `env:"GRPC_PORT" envDefault:"9090"`
 
 	DbHost     string `env:"DB_HOST" envDefault:"postgres"`
 	DbPort     string `env:"DB_PORT" envDefault:"5432"`
@@ -20,8 +22,63 @@ type Config struct {
 
 	ChHost string `env:"CLICKHOUSE_HOST" envDefault:"clickhouse"`
 	ChPort string `env:"CLICKHOUSE_PORT" envDefault:"9000"`
+	// Пакетная запись событий в goods_log
+	ChBatchSize     int           `env:"CLICKHOUSE_BATCH_SIZE" envDefault:"1000"`
+	ChFlushInterval time.Duration `env:"CLICKHOUSE_FLUSH_INTERVAL" envDefault:"1s"`
+	ChMaxRetries    int           `env:"CLICKHOUSE_MAX_RETRIES" envDefault:"3"`
+	ChRetryDelay    time.Duration `env:"CLICKHOUSE_RETRY_DELAY" envDefault:"500ms"`
+	ChSendTimeout   time.Duration `env:"CLICKHOUSE_SEND_TIMEOUT" envDefault:"5s"`
 
 	NatsURL string `env:"NATS_URL" envDefault:"nats://nats:4222"`
+	// NatsMode core - подписка core NATS, jetstream - durable consumer JetStream
+	NatsMode              string        `env:"NATS_MODE" envDefault:"core"`
+	NatsStream            string        `env:"NATS_STREAM" envDefault:"GOODS"`
+	NatsConsumer          string        `env:"NATS_CONSUMER" envDefault:"clickhouse-logger"`
+	NatsMaxDeliver        int           `env:"NATS_MAX_DELIVER" envDefault:"5"`
+	NatsAckWait           time.Duration `env:"NATS_ACK_WAIT" envDefault:"30s"`
+	NatsRetryDelay        time.Duration `env:"NATS_RETRY_DELAY" envDefault:"1s"`
+	NatsFetchBatch        int           `env:"NATS_FETCH_BATCH" envDefault:"100"`
+	NatsDeadLetterStream  string        `env:"NATS_DEAD_LETTER_STREAM" envDefault:"GOODS_DLQ"`
+	NatsDeadLetterSubject string        `env:"NATS_DEAD_LETTER_SUBJECT" envDefault:"dlq.good"`
+
+	OutboxInterval  time.Duration `env:"OUTBOX_INTERVAL" envDefault:"500ms"`
+	OutboxBatchSize int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	OutboxRetention time.Duration `env:"OUTBOX_RETENTION" envDefault:"24h"`
+
+	// Окончательное удаление товаров необратимо, поэтому включается явно.
+	// PurgeDryRun только пишет в лог, какие товары были бы удалены
+	PurgeEnabled   bool          `env:"PURGE_ENABLED" envDefault:"false"`
+	PurgeRetention time.Duration `env:"PURGE_RETENTION" envDefault:"720h"`
+	PurgeInterval  time.Duration `env:"PURGE_INTERVAL" envDefault:"1h"`
+	PurgeBatchSize int           `env:"PURGE_BATCH_SIZE" envDefault:"500"`
+	PurgeDryRun    bool          `env:"PURGE_DRY_RUN" envDefault:"false"`
+
+	// Ответы на запросы с заголовком Idempotency-Key хранятся IdempotencyTTL.
+	// Блокировка ключа выполняющегося запроса продлевается, пока он выполняется,
+	// и истекает через IdempotencyLockTTL, если экземпляр сервиса упал
+	IdempotencyTTL     time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	IdempotencyLockTTL time.Duration `env:"IDEMPOTENCY_LOCK_TTL" envDefault:"1m"`
+
+	// AuthEnabled false отключает проверку API ключей и JWT, только для локального запуска.
+	// JWT принимаются, если задан файл AuthJWKSFile с ключами проверки подписи.
+	// Найденные клиенты кэшируются на AuthCacheTTL, 0 - без кэша
+	AuthEnabled     bool          `env:"AUTH_ENABLED" envDefault:"true"`
+	AuthJWKSFile    string        `env:"AUTH_JWKS_FILE"`
+	AuthJWTIssuer   string        `env:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience string        `env:"AUTH_JWT_AUDIENCE"`
+	AuthCacheTTL    time.Duration `env:"AUTH_CACHE_TTL" envDefault:"30s"`
+
+	// Лимиты запросов клиента и проекта вида <запросов>/<период>, 0 - без ограничения.
+	// RateLimitAddress - лимит запросов с одного адреса, проверяется до аутентификации.
+	// RateLimitRoutes - лимиты отдельных маршрутов через ';' вида
+	// <метод> <путь>=<лимит клиента>[,<лимит проекта>], маршрут расходует свои лимиты вместо общих
+	RateLimitEnabled      bool     `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	RateLimitAddress      string   `env:"RATE_LIMIT_ADDRESS" envDefault:"300/1s"`
+	RateLimitClientRead   string   `env:"RATE_LIMIT_CLIENT_READ" envDefault:"100/1s"`
+	RateLimitClientWrite  string   `env:"RATE_LIMIT_CLIENT_WRITE" envDefault:"20/1s"`
+	RateLimitProjectRead  string   `env:"RATE_LIMIT_PROJECT_READ" envDefault:"200/1s"`
+	RateLimitProjectWrite string   `env:"RATE_LIMIT_PROJECT_WRITE" envDefault:"50/1s"`
+	RateLimitRoutes       []string `env:"RATE_LIMIT_ROUTES" envSeparator:";"`
 }
 
 func MustLoad() *Config {
@@ -37,3 +94,8 @@ func MustLoad() *Config {
 
 	return &cfg
 }
+
+const (
+	NatsModeCore      = "core"
+	NatsModeJetStream = "jetstream"
+)
@@ -1,25 +1,92 @@
 package models
 
-import "time"
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Типы событий об изменении товаров. Совпадают с темами NATS
+const (
+	EventGoodCreated       = "good.created"
+	EventGoodUpdated       = "good.updated"
+	EventGoodDeleted       = "good.deleted"
+	EventGoodRestored      = "good.restored"
+	EventGoodReprioritized = "good.reprioritized"
+	EventGoodPurged        = "good.purged"
+)
+
+// Названия полей товара в списке изменённых полей события
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldPriority    = "priority"
+	FieldRemoved     = "removed"
+)
 
 type ClickhouseEvent struct {
+	// EventID Идентификатор события, сохраняется при повторных доставках.
+	// По нему goods_log отбрасывает повторно записанные события
+	EventID     string    `json:"EventId,omitempty"`
 	ID          int       `json:"Id"`
 	ProjectID   int       `json:"ProjectId"`
 	Name        string    `json:"Name"`
 	Description string    `json:"Description"`
 	Priority    int       `json:"Priority"`
 	Removed     bool      `json:"Removed"`
+	EventType   string    `json:"EventType"`
 	EventTime   time.Time `json:"EventTime"`
+
+	// ChangedFields Поля, значения которых изменились в результате операции
+	ChangedFields []string `json:"ChangedFields,omitempty"`
+	// Предыдущие значения изменённых полей. nil, если поле не менялось
+	PrevName        *string `json:"PrevName,omitempty"`
+	PrevDescription *string `json:"PrevDescription,omitempty"`
+	PrevPriority    *int    `json:"PrevPriority,omitempty"`
+	PrevRemoved     *bool   `json:"PrevRemoved,omitempty"`
+
+	// Actor Клиент, выполнивший изменение (см. Identity.Actor). Пусто для фоновых задач
+	Actor string `json:"Actor,omitempty"`
 }
 
-func NewClickhouseEvent(good *Good) *ClickhouseEvent {
-	return &ClickhouseEvent{
+// NewClickhouseEvent Создаёт событие по текущему состоянию товара.
+// Если передано предыдущее состояние, в событие записываются изменённые поля и их прежние значения
+func NewClickhouseEvent(eventType string, good *Good, previous *Good) *ClickhouseEvent {
+	event := &ClickhouseEvent{
+		EventID:     uuid.NewString(),
 		ID:          good.ID,
 		ProjectID:   good.ProjectID,
 		Name:        good.Name,
 		Description: good.Description,
 		Priority:    good.Priority,
 		Removed:     good.Removed,
+		EventType:   eventType,
 		EventTime:   time.Now(),
 	}
+
+	if previous == nil {
+		return event
+	}
+
+	if previous.Name != good.Name {
+		event.ChangedFields = append(event.ChangedFields, FieldName)
+		event.PrevName = ptr(previous.Name)
+	}
+	if previous.Description != good.Description {
+		event.ChangedFields = append(event.ChangedFields, FieldDescription)
+		event.PrevDescription = ptr(previous.Description)
+	}
+	if previous.Priority != good.Priority {
+		event.ChangedFields = append(event.ChangedFields, FieldPriority)
+		event.PrevPriority = ptr(previous.Priority)
+	}
+	if previous.Removed != good.Removed {
+		event.ChangedFields = append(event.ChangedFields, FieldRemoved)
+		event.PrevRemoved = ptr(previous.Removed)
+	}
+
+	return event
+}
+
+func ptr[T any](value T) *T {
+	return &value
 }
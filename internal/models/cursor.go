@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// GoodsCursor Позиция в выдаче товаров для постраничного обхода по ключу
+// (project_id, priority, id). Клиенту передаётся в непрозрачном виде
+type GoodsCursor struct {
+	ProjectID int  `json:"p"`
+	Priority  int  `json:"pr"`
+	ID        int  `json:"id"`
+	Desc      bool `json:"d,omitempty"`
+}
+
+// NewGoodsCursor Создаёт курсор, указывающий на позицию сразу после товара
+func NewGoodsCursor(good *Good, desc bool) *GoodsCursor {
+	return &GoodsCursor{
+		ProjectID: good.ProjectID,
+		Priority:  good.Priority,
+		ID:        good.ID,
+		Desc:      desc,
+	}
+}
+
+// Encode Кодирует курсор в непрозрачную строку
+func (c *GoodsCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeGoodsCursor Декодирует курсор из строки, полученной от клиента
+func DecodeGoodsCursor(value string) (*GoodsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor GoodsCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.ProjectID <= 0 || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
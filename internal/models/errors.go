@@ -3,3 +3,12 @@ package models
 import "errors"
 
 var ErrNotFound = errors.New("not found")
+
+// ErrBatchAborted Пакет с признаком атомарности отменён из-за ошибок отдельных элементов
+var ErrBatchAborted = errors.New("batch aborted")
+
+// ErrDuplicateExternalKey Товар с таким внешним ключом уже есть в проекте
+var ErrDuplicateExternalKey = errors.New("duplicate external key")
+
+// ErrVersionMismatch Версия товара изменилась с момента его чтения клиентом
+var ErrVersionMismatch = errors.New("version mismatch")
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Поля, по которым допускается сортировка списка товаров
+const (
+	SortByPriority  = "priority"
+	SortByCreatedAt = "createdAt"
+	SortByName      = "name"
+	SortByID        = "id"
+)
+
+// GoodsFilter Параметры выборки товаров проекта
+type GoodsFilter struct {
+	ProjectID      int
+	Name           string     // Подстрока названия, без учёта регистра
+	PriorityFrom   *int       // Нижняя граница приоритета включительно
+	PriorityTo     *int       // Верхняя граница приоритета включительно
+	CreatedFrom    *time.Time // Нижняя граница даты создания включительно
+	CreatedTo      *time.Time // Верхняя граница даты создания включительно
+	IncludeRemoved bool       // Включать удалённые товары в выдачу
+	SortBy         string     // Поле сортировки, по умолчанию приоритет
+	SortDesc       bool       // Сортировка по убыванию
+	Limit          int
+	Offset         int
+
+	// Keyset Постраничный обход по курсору вместо LIMIT/OFFSET.
+	// Допускается только при сортировке по приоритету
+	Keyset bool
+	// After Курсор, после которого начинается страница. nil - первая страница
+	After *GoodsCursor
+}
+
+// HasConditions Проверяет, сужает ли фильтр выборку сильнее, чем до проекта
+func (f *GoodsFilter) HasConditions() bool {
+	return f.Name != "" ||
+		f.PriorityFrom != nil ||
+		f.PriorityTo != nil ||
+		f.CreatedFrom != nil ||
+		f.CreatedTo != nil
+}
+
+// IsValidSortField Проверяет, что по полю допускается сортировка
+func IsValidSortField(field string) bool {
+	switch field {
+	case SortByPriority, SortByCreatedAt, SortByName, SortByID:
+		return true
+	}
+
+	return false
+}
+
+// HistoryFilter Параметры выборки истории изменений товаров из лога событий
+type HistoryFilter struct {
+	ProjectID int
+	GoodID    int        // 0 - лента изменений всех товаров проекта
+	From      *time.Time // Нижняя граница времени события включительно
+	To        *time.Time // Верхняя граница времени события включительно
+	Desc      bool       // Сначала новые события
+	Limit     int
+	Offset    int
+}
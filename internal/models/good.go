@@ -3,11 +3,38 @@ package models
 import "time"
 
 type Good struct {
-	ID          int       `json:"id" db:"id"`
-	ProjectID   int       `json:"projectId" db:"project_id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	Priority    int       `json:"priority" db:"priority"`
-	Removed     bool      `json:"removed" db:"removed"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	ID          int        `json:"id" db:"id"`
+	ProjectID   int        `json:"projectId" db:"project_id"`
+	ExternalKey string     `json:"externalKey,omitempty" db:"external_key"` // Ключ товара во внешней системе, уникален в проекте
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	Priority    int        `json:"priority" db:"priority"`
+	Removed     bool       `json:"removed" db:"removed"`
+	RemovedAt   *time.Time `json:"removedAt,omitempty" db:"removed_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	Version     int        `json:"version" db:"version"` // Увеличивается при каждом изменении товара
+}
+
+// GoodPatch Частичное изменение товара в духе JSON merge patch. nil - поле не меняется
+type GoodPatch struct {
+	Name        *string
+	Description *string
+}
+
+// Apply Возвращает товар с применённым изменением
+func (p GoodPatch) Apply(good Good) Good {
+	if p.Name != nil {
+		good.Name = *p.Name
+	}
+	if p.Description != nil {
+		good.Description = *p.Description
+	}
+
+	return good
+}
+
+// GoodChange Состояние товара до и после изменения
+type GoodChange struct {
+	Before Good
+	After  Good
 }
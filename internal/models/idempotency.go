@@ -0,0 +1,24 @@
+package models
+
+import "errors"
+
+// ErrIdempotencyKeyReused Ключ идемпотентности уже использован для другого запроса
+var ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+// ErrIdempotencyKeyInProgress Запрос с тем же ключом идемпотентности ещё выполняется
+var ErrIdempotencyKeyInProgress = errors.New("request with the idempotency key is in progress")
+
+// ErrIdempotencyKeyNotOwned Ключ идемпотентности больше не зарезервирован запросом:
+// резерв истёк и ключ мог занять другой запрос
+var ErrIdempotencyKeyNotOwned = errors.New("idempotency key is not owned by the request")
+
+// IdempotencyRecord Запрос, выполненный с ключом идемпотентности, и ответ на него.
+// Пока запрос выполняется, Completed = false и ответ не заполнен
+type IdempotencyRecord struct {
+	RequestHash string              `json:"requestHash"` // Хэш метода, адреса и тела запроса
+	Owner       string              `json:"owner"`       // Случайный токен запроса, зарезервировавшего ключ
+	Completed   bool                `json:"completed"`
+	StatusCode  int                 `json:"statusCode,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Способы аутентификации клиента
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+)
+
+// Уровни доступа к проекту. Запись включает чтение
+const (
+	AccessRead  = "read"
+	AccessWrite = "write"
+)
+
+// AllProjects Идентификатор проекта в ProjectGrant, дающий доступ ко всем проектам
+const AllProjects = 0
+
+// ErrUnauthenticated Клиент не передал учётные данные или они не прошли проверку
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// APIKey API ключ клиента. Сам ключ не хранится, только его SHA-256 хэш
+type APIKey struct {
+	ID        int        `db:"id"`
+	Name      string     `db:"name"`
+	Subject   string     `db:"subject"` // Клиент, которому выдан ключ
+	CreatedAt time.Time  `db:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at"` // nil - ключ действует
+}
+
+// ProjectGrant Доступ клиента к проекту
+type ProjectGrant struct {
+	ProjectID int    `db:"project_id"` // AllProjects - доступ ко всем проектам
+	Access    string `db:"access"`
+}
+
+// Identity Аутентифицированный клиент и проекты, к которым у него есть доступ
+type Identity struct {
+	Subject string // Владелец API ключа или sub токена
+	Method  string // Способ аутентификации
+	Grants  []ProjectGrant
+}
+
+// CanRead Проверяет доступ на чтение проекта. AllProjects - ко всем проектам
+func (i *Identity) CanRead(projectID int) bool {
+	return i.can(projectID, AccessRead)
+}
+
+// CanWrite Проверяет доступ на изменение проекта. AllProjects - всех проектов
+func (i *Identity) CanWrite(projectID int) bool {
+	return i.can(projectID, AccessWrite)
+}
+
+func (i *Identity) can(projectID int, access string) bool {
+	for _, grant := range i.Grants {
+		if grant.ProjectID != AllProjects && grant.ProjectID != projectID {
+			continue
+		}
+		if grant.Access == AccessWrite || grant.Access == access {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Actor Клиент в событиях об изменении товаров: способ аутентификации и субъект
+func (i *Identity) Actor() string {
+	return i.Method + ":" + i.Subject
+}
+
+type identityKey struct{}
+
+// WithIdentity Возвращает контекст запроса клиента identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext Возвращает клиента запроса, nil - запрос без аутентификации
+func IdentityFromContext(ctx context.Context) *Identity {
+	identity, _ := ctx.Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// ActorFromContext Возвращает клиента запроса для записи в событие,
+// пустую строку для запросов без аутентификации и фоновых задач
+func ActorFromContext(ctx context.Context) string {
+	if identity := IdentityFromContext(ctx); identity != nil {
+		return identity.Actor()
+	}
+
+	return ""
+}
@@ -0,0 +1,42 @@
+package models
+
+import "errors"
+
+// ErrExternalKeyRemoved Внешний ключ принадлежит удалённому товару
+var ErrExternalKeyRemoved = errors.New("external key belongs to a removed good")
+
+// MaxImportErrors Максимальное количество ошибок строк, сохраняемых в результате импорта
+const MaxImportErrors = 100
+
+// ImportRow Строка импорта товаров
+type ImportRow struct {
+	Line int   // Номер строки в файле начиная с 1
+	Good Good  // Внешний ключ, название и описание товара
+	Err  error // Ошибка проверки строки, строка не импортируется
+}
+
+// ImportLineError Ошибка строки импорта
+type ImportLineError struct {
+	Line int
+	Err  error
+}
+
+// ImportResult Результат импорта товаров. Изменения сохраняются, только если
+// ни в одной строке нет ошибок
+type ImportResult struct {
+	Applied    bool
+	Created    int
+	Updated    int
+	Unchanged  int
+	ErrorCount int               // Общее количество строк с ошибками
+	Errors     []ImportLineError // Первые MaxImportErrors ошибок
+	UpdatedIDs []int             // Товары, название или описание которых изменилось
+}
+
+// AddError Учитывает ошибку строки
+func (r *ImportResult) AddError(line int, err error) {
+	r.ErrorCount++
+	if len(r.Errors) < MaxImportErrors {
+		r.Errors = append(r.Errors, ImportLineError{Line: line, Err: err})
+	}
+}
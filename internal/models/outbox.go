@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OutboxMessage Событие, ожидающее отправки в NATS
+type OutboxMessage struct {
+	ID        int64
+	Subject   string
+	Payload   []byte
+	Attempts  int
+	CreatedAt time.Time
+}
@@ -5,5 +5,6 @@ import "time"
 type Project struct {
 	ID        int       `json:"id" db:"id"`
 	Name      string    `json:"name" db:"name"`
+	Removed   bool      `json:"removed" db:"removed"`
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
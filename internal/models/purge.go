@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PurgeReport Результат прохода очистки удалённых товаров
+type PurgeReport struct {
+	DryRun        bool        `json:"dryRun"`
+	RemovedBefore time.Time   `json:"removedBefore"`
+	Purged        int         `json:"purged"`    // Количество удалённых (или подлежащих удалению) товаров
+	ByProject     map[int]int `json:"byProject"` // Количество по проектам
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// RateLimit Лимит запросов по алгоритму token bucket: бакет вмещает Requests
+// запросов и равномерно пополняется на Requests за Period. Нулевой лимит не ограничивает
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited Проверяет, что лимит не задан
+func (l RateLimit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// RateLimitBucket Бакет клиента или проекта с его лимитом
+type RateLimitBucket struct {
+	Key   string
+	Limit RateLimit
+}
+
+// RateLimitState Состояние бакета после запроса
+type RateLimitState struct {
+	Remaining  int           // Целых токенов в бакете
+	RetryAfter time.Duration // Через сколько появится токен, 0 - токен есть
+	Reset      time.Duration // Через сколько бакет заполнится полностью
+}
+
+// RateLimitResult Результат проверки лимитов запроса по самому строгому из бакетов
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
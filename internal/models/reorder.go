@@ -0,0 +1,19 @@
+package models
+
+import "errors"
+
+// ErrInvalidReorder Список товаров не совпадает с неудалёнными товарами проекта
+var ErrInvalidReorder = errors.New("reorder list does not match project goods")
+
+// GoodsReorder Изменение порядка товаров проекта одной операцией.
+// Задаётся либо полным упорядоченным списком IDs, либо списком перемещений Moves
+type GoodsReorder struct {
+	IDs   []int      `json:"ids,omitempty"`   // Все неудалённые товары проекта в новом порядке
+	Moves []GoodMove `json:"moves,omitempty"` // Перемещения, применяемые по очереди
+}
+
+// GoodMove Перемещение товара на позицию NewPriority
+type GoodMove struct {
+	ID          int `json:"id"`
+	NewPriority int `json:"newPriority"`
+}
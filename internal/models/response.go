@@ -7,5 +7,6 @@ type PriorityResponse struct {
 type PriorityItem struct {
 	ID        int `json:"id"`
 	Priority  int `json:"priority"`
+	Version   int `json:"version"`
 	ProjectID int `json:"-"` // Не включён в JSON, используется для логов в NATS
 }
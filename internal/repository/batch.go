@@ -0,0 +1,106 @@
+package repository
+
+import "goods-service/internal/models"
+
+// checkVersion Проверяет, что товар хранится в ожидаемой версии. Версия 0 не проверяется
+func checkVersion(expected, actual int) error {
+	if expected != 0 && expected != actual {
+		return models.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// matchBatchGoods Сопоставляет товары пачки с найденными в хранилище.
+// Возвращает индексы найденных товаров и ошибку по каждому товару пачки:
+// models.ErrNotFound для отсутствующих и повторно указанных товаров,
+// models.ErrVersionMismatch для товаров с указанной устаревшей версией
+func matchBatchGoods(goods []models.Good, stored []models.Good) ([]int, []error) {
+	versions := make(map[int]int, len(stored))
+	for _, good := range stored {
+		versions[good.ID] = good.Version
+	}
+
+	found := make([]int, 0, len(goods))
+	errs := make([]error, len(goods))
+	for i := range goods {
+		version, ok := versions[goods[i].ID]
+		if !ok {
+			errs[i] = models.ErrNotFound
+			continue
+		}
+		if err := checkVersion(goods[i].Version, version); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		// Повторное изменение того же товара в пачке не выполняется
+		delete(versions, goods[i].ID)
+		found = append(found, i)
+	}
+
+	return found, errs
+}
+
+// patchGood Применяет изменение к товару. changed = false, если название
+// и описание не изменились: такой товар не сохраняется и сохраняет версию
+func patchGood(good models.Good, patch models.GoodPatch) (updated models.Good, changed bool) {
+	updated = patch.Apply(good)
+	return updated, updated.Name != good.Name || updated.Description != good.Description
+}
+
+// planImportChunk Делит строки импорта на новые товары и изменения существующих
+// по их внешнему ключу. Строки без изменений и строки с ключом удалённого товара
+// учитываются в результате
+func planImportChunk(
+	chunk []models.ImportRow,
+	existing map[string]models.Good,
+	result *models.ImportResult,
+) ([]models.Good, []models.GoodChange) {
+	var created []models.Good
+	var changes []models.GoodChange
+	for _, row := range chunk {
+		stored, ok := existing[row.Good.ExternalKey]
+		switch {
+		case !ok:
+			created = append(created, row.Good)
+		case stored.Removed:
+			result.AddError(row.Line, models.ErrExternalKeyRemoved)
+		case stored.Name == row.Good.Name && stored.Description == row.Good.Description:
+			result.Unchanged++
+		default:
+			after := stored
+			after.Name = row.Good.Name
+			after.Description = row.Good.Description
+			after.Version++
+			changes = append(changes, models.GoodChange{Before: stored, After: after})
+			result.UpdatedIDs = append(result.UpdatedIDs, stored.ID)
+		}
+	}
+
+	result.Created += len(created)
+	result.Updated += len(changes)
+
+	return created, changes
+}
+
+// newBatchGoods Возвращает индексы товаров пачки, которые можно создать, и ошибку
+// по каждому товару: models.ErrDuplicateExternalKey для внешних ключей, которые
+// уже есть в проекте (existing) или повторяются в пачке
+func newBatchGoods(goods []models.Good, existing map[string]bool) ([]int, []error) {
+	valid := make([]int, 0, len(goods))
+	errs := make([]error, len(goods))
+	keys := make(map[string]bool, len(goods))
+	for i := range goods {
+		key := goods[i].ExternalKey
+		if key != "" && (existing[key] || keys[key]) {
+			errs[i] = models.ErrDuplicateExternalKey
+			continue
+		}
+
+		keys[key] = true
+		valid = append(valid, i)
+	}
+
+	return valid, errs
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"goods-service/internal/models"
-	"time"
+	"strings"
 )
 
 type ClickhouseRepository struct {
@@ -15,19 +15,122 @@ func NewClickhouseRepository(conn clickhouse.Conn) *ClickhouseRepository {
 	return &ClickhouseRepository{conn: conn}
 }
 
-func (r *ClickhouseRepository) LogGoodEvent(ctx context.Context, good *models.Good) error {
+// LogGoodEvent Записывает одно событие. Для потока событий используется ClickhouseBatchWriter
+func (r *ClickhouseRepository) LogGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error {
+	query := insertGoodsLogQuery + ` VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	return r.conn.Exec(ctx, query, goodsLogRow(event)...)
+}
+
+// GetHistory Возвращает события из лога по фильтру, упорядоченные по времени события.
+// FINAL отбрасывает повторно записанные события, ещё не схлопнутые слиянием
+func (r *ClickhouseRepository) GetHistory(ctx context.Context, filter models.HistoryFilter) ([]models.ClickhouseEvent, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 10
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	where, args := buildHistoryFilter(filter)
+
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+
 	query := `
-        INSERT INTO goods_log (
-            Id, ProjectId, Name, Description, Priority, Removed, EventTime
-        ) VALUES (?, ?, ?, ?, ?, ?, ?)`
-
-	return r.conn.Exec(ctx, query,
-		good.ID,
-		good.ProjectID,
-		good.Name,
-		good.Description,
-		good.Priority,
-		good.Removed,
-		time.Now(),
-	)
+        SELECT
+            Id, ProjectId, Name, Description, Priority, Removed, EventTime,
+            EventType, ChangedFields, PrevName, PrevDescription, PrevPriority, PrevRemoved, Actor
+        FROM goods_log FINAL
+        WHERE ` + where + `
+        ORDER BY EventTime ` + direction + `, LoggedAt ` + direction + `, Id ` + direction + `
+        LIMIT ? OFFSET ?`
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]models.ClickhouseEvent, 0, filter.Limit)
+	for rows.Next() {
+		var (
+			event         models.ClickhouseEvent
+			id, projectID int32
+			priority      int32
+			prevPriority  *int32
+		)
+		err := rows.Scan(
+			&id,
+			&projectID,
+			&event.Name,
+			&event.Description,
+			&priority,
+			&event.Removed,
+			&event.EventTime,
+			&event.EventType,
+			&event.ChangedFields,
+			&event.PrevName,
+			&event.PrevDescription,
+			&prevPriority,
+			&event.PrevRemoved,
+			&event.Actor,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.ID = int(id)
+		event.ProjectID = int(projectID)
+		event.Priority = int(priority)
+		if prevPriority != nil {
+			value := int(*prevPriority)
+			event.PrevPriority = &value
+		}
+
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// CountHistory Возвращает количество событий в логе, попадающих под фильтр
+func (r *ClickhouseRepository) CountHistory(ctx context.Context, filter models.HistoryFilter) (int, error) {
+	where, args := buildHistoryFilter(filter)
+
+	var count uint64
+	err := r.conn.QueryRow(ctx, `
+        SELECT count()
+        FROM goods_log FINAL
+        WHERE `+where, args...).Scan(&count)
+
+	return int(count), err
+}
+
+// buildHistoryFilter Формирует условие WHERE и его аргументы по фильтру истории
+func buildHistoryFilter(filter models.HistoryFilter) (string, []interface{}) {
+	conditions := []string{"ProjectId = ?"}
+	args := []interface{}{int32(filter.ProjectID)}
+
+	if filter.GoodID != 0 {
+		conditions = append(conditions, "Id = ?")
+		args = append(args, int32(filter.GoodID))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "EventTime >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "EventTime <= ?")
+		args = append(args, *filter.To)
+	}
+
+	return strings.Join(conditions, " AND "), args
 }
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/google/uuid"
+	"goods-service/internal/models"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrBatchWriterClosed = errors.New("clickhouse batch writer is closed")
+
+const insertGoodsLogQuery = `
+        INSERT INTO goods_log (
+            EventId, Id, ProjectId, Name, Description, Priority, Removed, EventTime,
+            EventType, ChangedFields, PrevName, PrevDescription, PrevPriority, PrevRemoved, Actor
+        )`
+
+// BatchWriterOptions Параметры пакетной записи событий в ClickHouse
+type BatchWriterOptions struct {
+	BatchSize     int           // Размер пачки, при достижении которого она отправляется
+	FlushInterval time.Duration // Максимальное время ожидания неполной пачки
+	MaxRetries    int           // Количество повторных попыток отправки пачки
+	RetryDelay    time.Duration // Задержка перед повторной попыткой
+	SendTimeout   time.Duration // Время ожидания одной попытки отправки пачки
+}
+
+// MaxWait Наибольшее время от добавления события в пачку до результата её записи
+// с учётом всех попыток. Ожидающий результат должен ждать не меньше
+func (o BatchWriterOptions) MaxWait() time.Duration {
+	wait := o.FlushInterval + time.Duration(o.MaxRetries+1)*o.SendTimeout
+	for attempt := 1; attempt <= o.MaxRetries; attempt++ {
+		wait += o.RetryDelay * time.Duration(attempt)
+	}
+
+	return wait
+}
+
+// BatchWriterStats Метрики пакетной записи
+type BatchWriterStats struct {
+	Batches       int64         `json:"batches"`       // Успешно записанные пачки
+	Events        int64         `json:"events"`        // Успешно записанные события
+	FailedBatches int64         `json:"failedBatches"` // Пачки, не записанные после всех попыток
+	Retries       int64         `json:"retries"`       // Повторные попытки записи
+	LastBatchSize int           `json:"lastBatchSize"`
+	LastLatency   time.Duration `json:"lastLatency"` // Время записи последней пачки
+	MaxLatency    time.Duration `json:"maxLatency"`
+	TotalLatency  time.Duration `json:"totalLatency"`
+}
+
+type pendingEvent struct {
+	event  *models.ClickhouseEvent
+	result chan error // nil, если результат записи никто не ждёт
+}
+
+// ClickhouseBatchWriter Копит события и записывает их в goods_log пачками
+// по размеру или по интервалу. Вызывающий получает результат после записи пачки
+type ClickhouseBatchWriter struct {
+	conn clickhouse.Conn
+	opts BatchWriterOptions
+
+	queue chan *pendingEvent
+	stop  chan struct{}
+	done  chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	statsMu sync.Mutex
+	stats   BatchWriterStats
+}
+
+func NewClickhouseBatchWriter(conn clickhouse.Conn, opts BatchWriterOptions) *ClickhouseBatchWriter {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.SendTimeout <= 0 {
+		opts.SendTimeout = 10 * time.Second
+	}
+
+	w := &ClickhouseBatchWriter{
+		conn:  conn,
+		opts:  opts,
+		queue: make(chan *pendingEvent, opts.BatchSize*2),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// MaxWait Наибольшее время ожидания результата LogGoodEvent, см. BatchWriterOptions.MaxWait
+func (w *ClickhouseBatchWriter) MaxWait() time.Duration {
+	return w.opts.MaxWait()
+}
+
+// LogGoodEvent Добавляет событие в пачку и ждёт, пока пачка будет записана
+func (w *ClickhouseBatchWriter) LogGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error {
+	pending := &pendingEvent{event: event, result: make(chan error, 1)}
+	if err := w.enqueue(ctx, pending); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-pending.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EnqueueGoodEvent Добавляет событие в пачку, не дожидаясь её записи.
+// Если очередь заполнена, ждёт освобождения места
+func (w *ClickhouseBatchWriter) EnqueueGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error {
+	return w.enqueue(ctx, &pendingEvent{event: event})
+}
+
+func (w *ClickhouseBatchWriter) enqueue(ctx context.Context, pending *pendingEvent) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrBatchWriterClosed
+	}
+
+	select {
+	case w.queue <- pending:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close Прекращает приём событий и записывает накопленные события
+func (w *ClickhouseBatchWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats Возвращает текущие метрики записи
+func (w *ClickhouseBatchWriter) Stats() BatchWriterStats {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+
+	return w.stats
+}
+
+func (w *ClickhouseBatchWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*pendingEvent, 0, w.opts.BatchSize)
+	for {
+		select {
+		case pending := <-w.queue:
+			batch = append(batch, pending)
+			if len(batch) >= w.opts.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-w.stop:
+			// Новые события больше не поступают, дописываем оставшиеся
+			for {
+				select {
+				case pending := <-w.queue:
+					batch = append(batch, pending)
+					if len(batch) >= w.opts.BatchSize {
+						w.flush(batch)
+						batch = batch[:0]
+					}
+				default:
+					if len(batch) > 0 {
+						w.flush(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush Записывает пачку с повторными попытками и сообщает результат ожидающим
+func (w *ClickhouseBatchWriter) flush(batch []*pendingEvent) {
+	start := time.Now()
+
+	var err error
+	for attempt := 0; attempt <= w.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			w.recordRetry()
+			time.Sleep(w.opts.RetryDelay * time.Duration(attempt))
+		}
+
+		if err = w.send(batch); err == nil {
+			break
+		}
+
+		log.Printf("error writing batch to ClickHouse: size=%d, attempt=%d, err=%v", len(batch), attempt+1, err)
+	}
+
+	w.recordFlush(len(batch), time.Since(start), err)
+
+	for _, pending := range batch {
+		if pending.result != nil {
+			pending.result <- err
+		}
+	}
+}
+
+func (w *ClickhouseBatchWriter) send(batch []*pendingEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.SendTimeout)
+	defer cancel()
+
+	chBatch, err := w.conn.PrepareBatch(ctx, insertGoodsLogQuery)
+	if err != nil {
+		return err
+	}
+
+	for _, pending := range batch {
+		if err := chBatch.Append(goodsLogRow(pending.event)...); err != nil {
+			chBatch.Abort()
+			return err
+		}
+	}
+
+	return chBatch.Send()
+}
+
+func (w *ClickhouseBatchWriter) recordRetry() {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+
+	w.stats.Retries++
+}
+
+func (w *ClickhouseBatchWriter) recordFlush(size int, latency time.Duration, err error) {
+	w.statsMu.Lock()
+	defer w.statsMu.Unlock()
+
+	w.stats.LastBatchSize = size
+	w.stats.LastLatency = latency
+	w.stats.TotalLatency += latency
+	if latency > w.stats.MaxLatency {
+		w.stats.MaxLatency = latency
+	}
+
+	if err != nil {
+		w.stats.FailedBatches++
+		return
+	}
+
+	w.stats.Batches++
+	w.stats.Events += int64(size)
+}
+
+// goodsLogRow Преобразует событие в значения колонок goods_log
+func goodsLogRow(event *models.ClickhouseEvent) []interface{} {
+	changedFields := event.ChangedFields
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	var prevPriority *int32
+	if event.PrevPriority != nil {
+		value := int32(*event.PrevPriority)
+		prevPriority = &value
+	}
+
+	// Событие без идентификатора записывается без дедупликации
+	eventID := event.EventID
+	if eventID == "" {
+		eventID = uuid.NewString()
+	}
+
+	return []interface{}{
+		eventID,
+		int32(event.ID),
+		int32(event.ProjectID),
+		event.Name,
+		event.Description,
+		int32(event.Priority),
+		event.Removed,
+		event.EventTime,
+		event.EventType,
+		changedFields,
+		event.PrevName,
+		event.PrevDescription,
+		prevPriority,
+		event.PrevRemoved,
+		event.Actor,
+	}
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"goods-service/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClickhouseConn Принимает пачки goods_log. Первые failures отправок завершаются ошибкой
+type fakeClickhouseConn struct {
+	clickhouse.Conn
+
+	mu       sync.Mutex
+	failures int
+	sends    int
+	batches  [][][]interface{}
+}
+
+func (c *fakeClickhouseConn) PrepareBatch(context.Context, string, ...driver.PrepareBatchOption) (driver.Batch, error) {
+	return &fakeClickhouseBatch{conn: c}, nil
+}
+
+func (c *fakeClickhouseConn) snapshot() (sends int, batches [][][]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.sends, append([][][]interface{}(nil), c.batches...)
+}
+
+type fakeClickhouseBatch struct {
+	driver.Batch
+
+	conn *fakeClickhouseConn
+	rows [][]interface{}
+}
+
+func (b *fakeClickhouseBatch) Append(values ...interface{}) error {
+	b.rows = append(b.rows, values)
+	return nil
+}
+
+func (b *fakeClickhouseBatch) Abort() error {
+	return nil
+}
+
+func (b *fakeClickhouseBatch) Send() error {
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+
+	b.conn.sends++
+	if b.conn.failures != 0 {
+		if b.conn.failures > 0 {
+			b.conn.failures--
+		}
+		return errors.New("clickhouse unavailable")
+	}
+
+	b.conn.batches = append(b.conn.batches, b.rows)
+	return nil
+}
+
+func newTestBatchWriter(t *testing.T, conn *fakeClickhouseConn, opts BatchWriterOptions) *ClickhouseBatchWriter {
+	t.Helper()
+
+	w := NewClickhouseBatchWriter(conn, opts)
+	t.Cleanup(func() { w.Close(context.Background()) })
+
+	return w
+}
+
+func testEvent(id int) *models.ClickhouseEvent {
+	return models.NewClickhouseEvent(models.EventGoodCreated, &models.Good{ID: id, ProjectID: 1, Name: "good"}, nil)
+}
+
+// logEvents Записывает события параллельно и возвращает ошибки в порядке событий
+func logEvents(w *ClickhouseBatchWriter, count int) []error {
+	errs := make([]error, count)
+
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = w.LogGoodEvent(context.Background(), testEvent(i+1))
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func TestClickhouseBatchWriter_FlushBySize(t *testing.T) {
+	conn := &fakeClickhouseConn{}
+	w := newTestBatchWriter(t, conn, BatchWriterOptions{BatchSize: 2, FlushInterval: time.Hour})
+
+	for i, err := range logEvents(w, 4) {
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+	}
+
+	_, batches := conn.snapshot()
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 2 {
+		t.Fatalf("expected 2 batches of 2 events, got %v", batches)
+	}
+	if stats := w.Stats(); stats.Batches != 2 || stats.Events != 4 || stats.LastBatchSize != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestClickhouseBatchWriter_FlushByInterval(t *testing.T) {
+	conn := &fakeClickhouseConn{}
+	w := newTestBatchWriter(t, conn, BatchWriterOptions{BatchSize: 100, FlushInterval: 20 * time.Millisecond})
+
+	event := testEvent(1)
+	if err := w.LogGoodEvent(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	_, batches := conn.snapshot()
+	if len(batches) != 1 || len(batches[0]) != 1 {
+		t.Fatalf("expected 1 batch of 1 event, got %v", batches)
+	}
+	// Идентификатор события записывается первой колонкой
+	if batches[0][0][0] != event.EventID {
+		t.Errorf("expected event id %q, got %v", event.EventID, batches[0][0][0])
+	}
+}
+
+func TestClickhouseBatchWriter_FlushOnClose(t *testing.T) {
+	conn := &fakeClickhouseConn{}
+	w := NewClickhouseBatchWriter(conn, BatchWriterOptions{BatchSize: 100, FlushInterval: time.Hour})
+
+	for i := 1; i <= 3; i++ {
+		if err := w.EnqueueGoodEvent(context.Background(), testEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, batches := conn.snapshot()
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("expected 1 batch of 3 events, got %v", batches)
+	}
+
+	if err := w.EnqueueGoodEvent(context.Background(), testEvent(4)); !errors.Is(err, ErrBatchWriterClosed) {
+		t.Errorf("expected ErrBatchWriterClosed, got %v", err)
+	}
+}
+
+func TestClickhouseBatchWriter_Retry(t *testing.T) {
+	conn := &fakeClickhouseConn{failures: 1}
+	w := newTestBatchWriter(t, conn, BatchWriterOptions{BatchSize: 1, MaxRetries: 2, RetryDelay: time.Millisecond})
+
+	if err := w.LogGoodEvent(context.Background(), testEvent(1)); err != nil {
+		t.Fatalf("expected event to be written after retry, got %v", err)
+	}
+	if sends, batches := conn.snapshot(); sends != 2 || len(batches) != 1 {
+		t.Fatalf("expected 2 sends and 1 batch, got %d sends, %v", sends, batches)
+	}
+	if stats := w.Stats(); stats.Retries != 1 || stats.Batches != 1 || stats.FailedBatches != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestClickhouseBatchWriter_RetryGivesUp(t *testing.T) {
+	conn := &fakeClickhouseConn{failures: -1}
+	w := newTestBatchWriter(t, conn, BatchWriterOptions{BatchSize: 1, MaxRetries: 2, RetryDelay: time.Millisecond})
+
+	if err := w.LogGoodEvent(context.Background(), testEvent(1)); err == nil {
+		t.Fatal("expected error after all retries")
+	}
+	if sends, batches := conn.snapshot(); sends != 3 || len(batches) != 0 {
+		t.Fatalf("expected 3 sends and no batches, got %d sends, %v", sends, batches)
+	}
+	if stats := w.Stats(); stats.Retries != 2 || stats.Batches != 0 || stats.FailedBatches != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestBatchWriterOptions_MaxWait(t *testing.T) {
+	opts := BatchWriterOptions{
+		FlushInterval: time.Second,
+		MaxRetries:    3,
+		RetryDelay:    500 * time.Millisecond,
+		SendTimeout:   5 * time.Second,
+	}
+
+	// Интервал, 4 попытки отправки и задержки 0.5s + 1s + 1.5s
+	if got, want := opts.MaxWait(), 24*time.Second; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,618 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goods-service/internal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryRepository Хранилище товаров, проектов и outbox в памяти процесса.
+// Повторяет правила PostgresRepository: приоритеты в рамках проекта,
+// мягкое удаление и запись событий в outbox вместе с изменением.
+// Предназначено для локального запуска и тестов
+type MemoryRepository struct {
+	mu sync.Mutex
+
+	goods    map[int]*models.Good
+	projects map[int]*models.Project
+	outbox   []*memoryOutboxMessage
+
+	lastGoodID    int
+	lastProjectID int
+	lastOutboxID  int64
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	r := &MemoryRepository{
+		goods:    make(map[int]*models.Good),
+		projects: make(map[int]*models.Project),
+	}
+
+	// Как и миграция PostgreSQL, создаём первый проект
+	r.lastProjectID = 1
+	r.projects[1] = &models.Project{ID: 1, Name: "Первая запись", CreatedAt: time.Now()}
+
+	return r
+}
+
+func (r *MemoryRepository) CreateGood(ctx context.Context, good *models.Good) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Вставка происходит только в существующий неудалённый проект
+	if !r.projectExists(good.ProjectID) {
+		return models.ErrNotFound
+	}
+	if good.ExternalKey != "" && r.goodByExternalKey(good.ProjectID, good.ExternalKey) != nil {
+		return models.ErrDuplicateExternalKey
+	}
+
+	r.lastGoodID++
+	good.ID = r.lastGoodID
+	good.Priority = r.maxPriority(good.ProjectID) + 1
+	good.Removed = false
+	good.RemovedAt = nil
+	good.CreatedAt = time.Now()
+	good.Version = 1
+
+	stored := *good
+	r.goods[good.ID] = &stored
+
+	return r.enqueueEvents(ctx, models.NewClickhouseEvent(models.EventGoodCreated, good, nil))
+}
+
+// UpdateGood Применяет к товару изменение patch с проверкой версии,
+// как и PostgresRepository.UpdateGood. Возвращает состояние товара до изменения
+func (r *MemoryRepository) UpdateGood(ctx context.Context, good *models.Good, patch models.GoodPatch) (*models.Good, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.activeGood(good.ID, good.ProjectID)
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	if err := checkVersion(good.Version, stored.Version); err != nil {
+		return nil, err
+	}
+
+	previous := *stored
+	updated, changed := patchGood(previous, patch)
+	if !changed {
+		*good = previous
+		return &previous, nil
+	}
+
+	stored.Name = updated.Name
+	stored.Description = updated.Description
+	stored.Version++
+	*good = *stored
+
+	if err := r.enqueueEvents(ctx, models.NewClickhouseEvent(models.EventGoodUpdated, good, &previous)); err != nil {
+		return nil, err
+	}
+
+	return &previous, nil
+}
+
+// CreateGoods Создаёт пачку товаров проекта, как и PostgresRepository.CreateGoods
+func (r *MemoryRepository) CreateGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error) {
+	if len(goods) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(projectID) {
+		return nil, models.ErrNotFound
+	}
+
+	existing := make(map[string]bool, len(goods))
+	for i := range goods {
+		key := goods[i].ExternalKey
+		if key != "" && r.goodByExternalKey(projectID, key) != nil {
+			existing[key] = true
+		}
+	}
+
+	valid, errs := newBatchGoods(goods, existing)
+	if atomic && len(valid) < len(goods) {
+		return errs, models.ErrBatchAborted
+	}
+	if len(valid) == 0 {
+		return errs, nil
+	}
+
+	created := make([]models.Good, 0, len(valid))
+	for _, i := range valid {
+		created = append(created, goods[i])
+	}
+	if err := r.insertGoods(ctx, projectID, created); err != nil {
+		return nil, err
+	}
+	for j, i := range valid {
+		goods[i] = created[j]
+	}
+
+	return errs, nil
+}
+
+// insertGoods Добавляет товары в конец списка проекта в порядке goods и записывает события создания
+func (r *MemoryRepository) insertGoods(ctx context.Context, projectID int, goods []models.Good) error {
+	lastPriority := r.maxPriority(projectID)
+	createdAt := time.Now()
+	events := make([]*models.ClickhouseEvent, 0, len(goods))
+	for i := range goods {
+		good := &goods[i]
+
+		r.lastGoodID++
+		good.ID = r.lastGoodID
+		good.ProjectID = projectID
+		good.Priority = lastPriority + i + 1
+		good.Removed = false
+		good.RemovedAt = nil
+		good.CreatedAt = createdAt
+		good.Version = 1
+
+		stored := *good
+		r.goods[good.ID] = &stored
+		events = append(events, models.NewClickhouseEvent(models.EventGoodCreated, good, nil))
+	}
+
+	return r.enqueueEvents(ctx, events...)
+}
+
+// UpdateGoods Обновляет пачку товаров проекта, как и PostgresRepository.UpdateGoods
+func (r *MemoryRepository) UpdateGoods(ctx context.Context, projectID int, goods []models.Good, patches []models.GoodPatch, atomic bool) ([]error, error) {
+	return r.changeGoods(ctx, projectID, goods, atomic, func(i int, stored *models.Good) *models.ClickhouseEvent {
+		previous := *stored
+		updated, changed := patchGood(previous, patches[i])
+		if !changed {
+			goods[i] = previous
+			return nil
+		}
+
+		stored.Name = updated.Name
+		stored.Description = updated.Description
+		stored.Version++
+		goods[i] = *stored
+
+		return models.NewClickhouseEvent(models.EventGoodUpdated, &goods[i], &previous)
+	})
+}
+
+// RemoveGoods Отмечает удалёнными пачку товаров проекта, как и PostgresRepository.RemoveGoods
+func (r *MemoryRepository) RemoveGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error) {
+	removedAt := time.Now()
+	return r.changeGoods(ctx, projectID, goods, atomic, func(i int, stored *models.Good) *models.ClickhouseEvent {
+		previous := *stored
+		stored.Removed = true
+		stored.RemovedAt = &removedAt
+		stored.Version++
+		goods[i] = *stored
+
+		return models.NewClickhouseEvent(models.EventGoodDeleted, &goods[i], &previous)
+	})
+}
+
+// changeGoods Применяет change к найденным неудалённым товарам пачки и записывает
+// события. change получает индекс товара в goods и хранимый товар и возвращает
+// событие либо nil, если товар не изменился
+func (r *MemoryRepository) changeGoods(
+	ctx context.Context,
+	projectID int,
+	goods []models.Good,
+	atomic bool,
+	change func(i int, stored *models.Good) *models.ClickhouseEvent,
+) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := make([]models.Good, 0, len(goods))
+	for i := range goods {
+		if good, ok := r.activeGood(goods[i].ID, projectID); ok {
+			stored = append(stored, *good)
+		}
+	}
+
+	found, errs := matchBatchGoods(goods, stored)
+	if atomic && len(found) < len(goods) {
+		return errs, models.ErrBatchAborted
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(found))
+	for _, i := range found {
+		if event := change(i, r.goods[goods[i].ID]); event != nil {
+			events = append(events, event)
+		}
+	}
+
+	return errs, r.enqueueEvents(ctx, events...)
+}
+
+// ReprioritizeGoods Перемещает товар на позицию newPriority среди неудалённых
+// товаров проекта и нумерует их подряд, как и PostgresRepository.ReprioritizeGoods.
+// Возвращает только товары, приоритет которых изменился
+func (r *MemoryRepository) ReprioritizeGoods(ctx context.Context, id, projectID, newPriority, version int) ([]models.GoodChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(projectID) {
+		return nil, models.ErrNotFound
+	}
+
+	changes, err := reprioritizeGood(r.orderedProjectGoods(projectID), id, newPriority, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, r.applyPriorityChanges(ctx, changes)
+}
+
+// ReorderGoods Меняет порядок товаров проекта одной операцией, как и PostgresRepository.ReorderGoods
+func (r *MemoryRepository) ReorderGoods(ctx context.Context, projectID int, reorder models.GoodsReorder) ([]models.GoodChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(projectID) {
+		return nil, models.ErrNotFound
+	}
+
+	changes, err := applyGoodsReorder(r.orderedProjectGoods(projectID), reorder)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, r.applyPriorityChanges(ctx, changes)
+}
+
+func (r *MemoryRepository) ListGoods(ctx context.Context, filter models.GoodsFilter) ([]models.Good, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 10
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	goods := r.filterGoods(filter, !filter.IncludeRemoved)
+
+	less := goodsLess(filter.SortBy)
+	if filter.Keyset {
+		less = goodsLess(models.SortByPriority)
+	}
+	sort.Slice(goods, func(i, j int) bool {
+		if filter.SortDesc {
+			return less(&goods[j], &goods[i])
+		}
+		return less(&goods[i], &goods[j])
+	})
+
+	if filter.Keyset {
+		// Обход по ключу (priority, id), как и в PostgreSQL
+		if filter.After != nil {
+			after := models.Good{ID: filter.After.ID, Priority: filter.After.Priority}
+			start := sort.Search(len(goods), func(i int) bool {
+				if filter.SortDesc {
+					return less(&goods[i], &after)
+				}
+				return less(&after, &goods[i])
+			})
+			goods = goods[start:]
+		}
+	} else {
+		if filter.Offset >= len(goods) {
+			return []models.Good{}, nil
+		}
+		goods = goods[filter.Offset:]
+	}
+
+	if len(goods) > filter.Limit {
+		goods = goods[:filter.Limit]
+	}
+
+	return goods, nil
+}
+
+// CountGoods Возвращает количество неудалённых и удалённых товаров,
+// попадающих под фильтр. Пагинация и сортировка фильтра не учитываются
+func (r *MemoryRepository) CountGoods(ctx context.Context, filter models.GoodsFilter) (total int, removed int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, good := range r.filterGoods(filter, false) {
+		if good.Removed {
+			removed++
+		} else {
+			total++
+		}
+	}
+
+	return total, removed, nil
+}
+
+func (r *MemoryRepository) GetGood(ctx context.Context, id, projectID int) (*models.Good, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.activeGood(id, projectID)
+	if !ok {
+		return nil, nil
+	}
+
+	good := *stored
+	return &good, nil
+}
+
+// MarkAsRemoved Отмечает товар удалённым с проверкой версии, как и PostgresRepository.MarkAsRemoved
+func (r *MemoryRepository) MarkAsRemoved(ctx context.Context, good *models.Good) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.activeGood(good.ID, good.ProjectID)
+	if !ok {
+		return models.ErrNotFound
+	}
+	if err := checkVersion(good.Version, stored.Version); err != nil {
+		return err
+	}
+
+	previous := *stored
+	removedAt := time.Now()
+	stored.Removed = true
+	stored.RemovedAt = &removedAt
+	stored.Version++
+	*good = *stored
+
+	return r.enqueueEvents(ctx, models.NewClickhouseEvent(models.EventGoodDeleted, good, &previous))
+}
+
+// RestoreGood Снимает отметку об удалении с товара.
+// Восстановленный товар получает приоритет в конце списка проекта.
+// Возвращает состояние товара до восстановления
+func (r *MemoryRepository) RestoreGood(ctx context.Context, good *models.Good) (*models.Good, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.goods[good.ID]
+	if !ok || stored.ProjectID != good.ProjectID || !stored.Removed || !r.projectExists(good.ProjectID) {
+		return nil, models.ErrNotFound
+	}
+
+	previous := *stored
+	stored.Priority = r.maxPriority(good.ProjectID) + 1
+	stored.Removed = false
+	stored.RemovedAt = nil
+	stored.Version++
+	*good = *stored
+
+	if err := r.enqueueEvents(ctx, models.NewClickhouseEvent(models.EventGoodRestored, good, &previous)); err != nil {
+		return nil, err
+	}
+
+	return &previous, nil
+}
+
+// ListPurgeableGoods Возвращает товары, удалённые раньше removedBefore, с id больше afterID
+func (r *MemoryRepository) ListPurgeableGoods(ctx context.Context, removedBefore time.Time, afterID, limit int) ([]models.Good, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	goods := r.purgeableGoods(removedBefore, afterID)
+	if len(goods) > limit {
+		goods = goods[:limit]
+	}
+
+	return goods, nil
+}
+
+// PurgeRemovedGoods Физически удаляет пачку товаров, удалённых раньше removedBefore
+func (r *MemoryRepository) PurgeRemovedGoods(ctx context.Context, removedBefore time.Time, limit int) ([]models.Good, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	goods := r.purgeableGoods(removedBefore, 0)
+	if len(goods) > limit {
+		goods = goods[:limit]
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(goods))
+	for i := range goods {
+		delete(r.goods, goods[i].ID)
+		events = append(events, models.NewClickhouseEvent(models.EventGoodPurged, &goods[i], nil))
+	}
+	if err := r.enqueueEvents(ctx, events...); err != nil {
+		return nil, err
+	}
+
+	return goods, nil
+}
+
+func (r *MemoryRepository) CheckGoodExists(ctx context.Context, id, projectID int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.activeGood(id, projectID)
+	return ok, nil
+}
+
+// activeGood Возвращает неудалённый товар проекта
+func (r *MemoryRepository) activeGood(id, projectID int) (*models.Good, bool) {
+	good, ok := r.goods[id]
+	if !ok || good.ProjectID != projectID || good.Removed {
+		return nil, false
+	}
+
+	return good, true
+}
+
+// goodByExternalKey Возвращает товар проекта с внешним ключом key, в том числе удалённый
+func (r *MemoryRepository) goodByExternalKey(projectID int, key string) *models.Good {
+	for _, good := range r.goods {
+		if good.ProjectID == projectID && good.ExternalKey == key {
+			return good
+		}
+	}
+
+	return nil
+}
+
+// projectGoods Возвращает неудалённые товары проекта
+func (r *MemoryRepository) projectGoods(projectID int) []*models.Good {
+	var goods []*models.Good
+	for _, good := range r.goods {
+		if good.ProjectID == projectID && !good.Removed {
+			goods = append(goods, good)
+		}
+	}
+
+	return goods
+}
+
+// orderedProjectGoods Возвращает копии неудалённых товаров проекта, упорядоченные по приоритету
+func (r *MemoryRepository) orderedProjectGoods(projectID int) []models.Good {
+	goods := make([]models.Good, 0)
+	for _, good := range r.projectGoods(projectID) {
+		goods = append(goods, *good)
+	}
+
+	less := goodsLess(models.SortByPriority)
+	sort.Slice(goods, func(i, j int) bool {
+		return less(&goods[i], &goods[j])
+	})
+
+	return goods
+}
+
+// applyPriorityChanges Сохраняет новые приоритеты и записывает события в outbox
+func (r *MemoryRepository) applyPriorityChanges(ctx context.Context, changes []models.GoodChange) error {
+	events := make([]*models.ClickhouseEvent, 0, len(changes))
+	for i := range changes {
+		change := &changes[i]
+		stored := r.goods[change.After.ID]
+		stored.Priority = change.After.Priority
+		stored.Version = change.After.Version
+		events = append(events, models.NewClickhouseEvent(models.EventGoodReprioritized, &change.After, &change.Before))
+	}
+
+	return r.enqueueEvents(ctx, events...)
+}
+
+// maxPriority Возвращает наибольший приоритет неудалённых товаров проекта
+func (r *MemoryRepository) maxPriority(projectID int) int {
+	maxPriority := 0
+	for _, good := range r.projectGoods(projectID) {
+		if good.Priority > maxPriority {
+			maxPriority = good.Priority
+		}
+	}
+
+	return maxPriority
+}
+
+// filterGoods Возвращает копии товаров, попадающих под условия фильтра
+func (r *MemoryRepository) filterGoods(filter models.GoodsFilter, activeOnly bool) []models.Good {
+	name := strings.ToLower(filter.Name)
+
+	goods := make([]models.Good, 0)
+	for _, good := range r.goods {
+		if good.ProjectID != filter.ProjectID {
+			continue
+		}
+		if activeOnly && good.Removed {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(good.Name), name) {
+			continue
+		}
+		if filter.PriorityFrom != nil && good.Priority < *filter.PriorityFrom {
+			continue
+		}
+		if filter.PriorityTo != nil && good.Priority > *filter.PriorityTo {
+			continue
+		}
+		if filter.CreatedFrom != nil && good.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && good.CreatedAt.After(*filter.CreatedTo) {
+			continue
+		}
+		goods = append(goods, *good)
+	}
+
+	return goods
+}
+
+// purgeableGoods Возвращает копии товаров, удалённых раньше removedBefore, упорядоченные по id
+func (r *MemoryRepository) purgeableGoods(removedBefore time.Time, afterID int) []models.Good {
+	var goods []models.Good
+	for _, good := range r.goods {
+		if good.Removed && good.RemovedAt != nil && good.RemovedAt.Before(removedBefore) && good.ID > afterID {
+			goods = append(goods, *good)
+		}
+	}
+
+	sort.Slice(goods, func(i, j int) bool {
+		return goods[i].ID < goods[j].ID
+	})
+
+	return goods
+}
+
+// goodsLess Возвращает функцию сравнения товаров по полю сортировки.
+// При равенстве поля товары упорядочиваются по id
+func goodsLess(sortBy string) func(a, b *models.Good) bool {
+	return func(a, b *models.Good) bool {
+		switch sortBy {
+		case models.SortByCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		case models.SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case models.SortByID:
+		default:
+			if a.Priority != b.Priority {
+				return a.Priority < b.Priority
+			}
+		}
+
+		return a.ID < b.ID
+	}
+}
+
+// enqueueEvents Записывает события в outbox. Вызывается под блокировкой
+// вместе с изменением, как и в транзакции PostgreSQL. В события записывается клиент запроса
+func (r *MemoryRepository) enqueueEvents(ctx context.Context, events ...*models.ClickhouseEvent) error {
+	actor := models.ActorFromContext(ctx)
+	for _, event := range events {
+		event.Actor = actor
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error marshaling event: %v", err)
+		}
+
+		r.lastOutboxID++
+		now := time.Now()
+		r.outbox = append(r.outbox, &memoryOutboxMessage{
+			OutboxMessage: models.OutboxMessage{
+				ID:        r.lastOutboxID,
+				Subject:   event.EventType,
+				Payload:   payload,
+				CreatedAt: now,
+			},
+			nextAttemptAt: now,
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"sync"
+	"time"
+)
+
+// MemoryAuthStore API ключи и доступ клиентов к проектам в памяти процесса.
+// Повторяет правила PostgresRepository, ключи и доступ добавляются методами
+// AddAPIKey и GrantProject
+type MemoryAuthStore struct {
+	mu sync.Mutex
+
+	keys   map[string]*models.APIKey        // Ключи по хэшу
+	grants map[string][]models.ProjectGrant // Доступ по субъекту
+
+	lastKeyID int
+}
+
+func NewMemoryAuthStore() *MemoryAuthStore {
+	return &MemoryAuthStore{
+		keys:   make(map[string]*models.APIKey),
+		grants: make(map[string][]models.ProjectGrant),
+	}
+}
+
+// AddAPIKey Сохраняет ключ key под SHA-256 хэшем keyHash
+func (s *MemoryAuthStore) AddAPIKey(key *models.APIKey, keyHash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastKeyID++
+	key.ID = s.lastKeyID
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	stored := *key
+	s.keys[keyHash] = &stored
+}
+
+// GrantProject Выдаёт клиенту subject доступ к проекту, заменяя прежний доступ к нему
+func (s *MemoryAuthStore) GrantProject(subject string, grant models.ProjectGrant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grants := s.grants[subject]
+	for i := range grants {
+		if grants[i].ProjectID == grant.ProjectID {
+			grants[i] = grant
+			return
+		}
+	}
+	s.grants[subject] = append(grants, grant)
+}
+
+func (s *MemoryAuthStore) GetAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[keyHash]
+	if !ok || key.RevokedAt != nil {
+		return nil, models.ErrNotFound
+	}
+
+	found := *key
+	return &found, nil
+}
+
+func (s *MemoryAuthStore) GetProjectGrants(ctx context.Context, subject string) ([]models.ProjectGrant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]models.ProjectGrant(nil), s.grants[subject]...), nil
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"sync"
+	"time"
+)
+
+type memoryCacheEntry struct {
+	good        *models.Good
+	count       int
+	idempotency *models.IdempotencyRecord
+	bucket      *memoryBucket
+	expiresAt   time.Time
+}
+
+// MemoryCache Кэш товаров и счетчиков проектов, хранилище ключей идемпотентности
+// и бакетов ограничения запросов в памяти процесса. Ключи и время жизни записей
+// совпадают с RedisRepository
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+func (c *MemoryCache) SetGood(ctx context.Context, good *models.Good) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := *good
+	c.entries[goodKey(good.ID, good.ProjectID)] = memoryCacheEntry{
+		good:      &cached,
+		expiresAt: time.Now().Add(time.Minute),
+	}
+
+	return nil
+}
+
+func (c *MemoryCache) GetGood(ctx context.Context, id, projectID int) (*models.Good, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.get(goodKey(id, projectID))
+	if !ok || entry.good == nil {
+		return nil, nil
+	}
+
+	good := *entry.good
+	return &good, nil
+}
+
+func (c *MemoryCache) InvalidateGood(ctx context.Context, id, projectID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, goodKey(id, projectID))
+	return nil
+}
+
+// SetGoods Кэширует пачку товаров
+func (c *MemoryCache) SetGoods(ctx context.Context, goods []models.Good) error {
+	for i := range goods {
+		if err := c.SetGood(ctx, &goods[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidateGoods Инвалидирует кэш пачки товаров
+func (c *MemoryCache) InvalidateGoods(ctx context.Context, goods []models.Good) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range goods {
+		delete(c.entries, goodKey(goods[i].ID, goods[i].ProjectID))
+	}
+
+	return nil
+}
+
+// GetCounts Возвращает счетчики неудаленных и удаленных записей проекта.
+// found = false, если счетчиков нет в кэше
+func (c *MemoryCache) GetCounts(ctx context.Context, projectID int) (total int, removed int, found bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	totalEntry, totalOk := c.get(totalCountKey(projectID))
+	removedEntry, removedOk := c.get(removedCountKey(projectID))
+	if !totalOk || !removedOk {
+		return 0, 0, false, nil
+	}
+
+	return totalEntry.count, removedEntry.count, true, nil
+}
+
+// CountsGeneration Возвращает поколение счетчиков проекта. Его нужно прочитать
+// до подсчета записей и передать в SetCounts
+func (c *MemoryCache) CountsGeneration(ctx context.Context, projectID int) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, _ := c.get(countsGenerationKey(projectID))
+	return int64(entry.count), nil
+}
+
+// SetCounts Сохраняет счетчики неудаленных и удаленных записей проекта, если после
+// чтения поколения generation они не изменялись. Иначе счетчики не сохраняются
+func (c *MemoryCache) SetCounts(ctx context.Context, projectID, total, removed int, generation int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, _ := c.get(countsGenerationKey(projectID)); int64(entry.count) != generation {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(countTTL)
+	c.entries[totalCountKey(projectID)] = memoryCacheEntry{count: total, expiresAt: expiresAt}
+	c.entries[removedCountKey(projectID)] = memoryCacheEntry{count: removed, expiresAt: expiresAt}
+
+	return nil
+}
+
+// AdjustCounts Изменяет счетчики проекта, только если оба есть в кэше.
+// Иначе удаляет оба, чтобы при чтении они были загружены заново
+func (c *MemoryCache) AdjustCounts(ctx context.Context, projectID, totalDelta, removedDelta int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextCountsGeneration(projectID)
+	totalKey, removedKey := totalCountKey(projectID), removedCountKey(projectID)
+	totalEntry, totalOk := c.get(totalKey)
+	removedEntry, removedOk := c.get(removedKey)
+	if !totalOk || !removedOk {
+		delete(c.entries, totalKey)
+		delete(c.entries, removedKey)
+		return nil
+	}
+
+	totalEntry.count += totalDelta
+	removedEntry.count += removedDelta
+	c.entries[totalKey] = totalEntry
+	c.entries[removedKey] = removedEntry
+
+	return nil
+}
+
+// InvalidateCounts Инвалидирует кэш счетчиков проекта
+func (c *MemoryCache) InvalidateCounts(ctx context.Context, projectID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextCountsGeneration(projectID)
+	delete(c.entries, totalCountKey(projectID))
+	delete(c.entries, removedCountKey(projectID))
+	return nil
+}
+
+// nextCountsGeneration Увеличивает поколение счетчиков проекта
+func (c *MemoryCache) nextCountsGeneration(projectID int) {
+	key := countsGenerationKey(projectID)
+	entry, _ := c.get(key)
+	c.entries[key] = memoryCacheEntry{count: entry.count + 1, expiresAt: time.Now().Add(countTTL)}
+}
+
+// ReserveIdempotencyKey Сохраняет record под ключом идемпотентности, если его ещё нет.
+// Возвращает nil, если ключ зарезервирован, иначе запись, сохранённую под ключом
+func (c *MemoryCache) ReserveIdempotencyKey(
+	ctx context.Context,
+	key string,
+	record *models.IdempotencyRecord,
+	ttl time.Duration,
+) (*models.IdempotencyRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.get(idempotencyKey(key)); ok {
+		existing := *entry.idempotency
+		return &existing, nil
+	}
+
+	stored := *record
+	c.entries[idempotencyKey(key)] = memoryCacheEntry{idempotency: &stored, expiresAt: time.Now().Add(ttl)}
+
+	return nil, nil
+}
+
+// SaveIdempotencyKey Сохраняет запись под ключом идемпотентности, зарезервированным record.Owner
+func (c *MemoryCache) SaveIdempotencyKey(ctx context.Context, key string, record *models.IdempotencyRecord, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ownedIdempotencyKey(key, record.Owner); !ok {
+		return models.ErrIdempotencyKeyNotOwned
+	}
+
+	stored := *record
+	c.entries[idempotencyKey(key)] = memoryCacheEntry{idempotency: &stored, expiresAt: time.Now().Add(ttl)}
+
+	return nil
+}
+
+// ExtendIdempotencyKey Продлевает резерв ключа идемпотентности запросом owner
+func (c *MemoryCache) ExtendIdempotencyKey(ctx context.Context, key, owner string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.ownedIdempotencyKey(key, owner)
+	if !ok || entry.idempotency.Completed {
+		return models.ErrIdempotencyKeyNotOwned
+	}
+
+	entry.expiresAt = time.Now().Add(ttl)
+	c.entries[idempotencyKey(key)] = entry
+
+	return nil
+}
+
+// ReleaseIdempotencyKey Удаляет ключ идемпотентности, зарезервированный owner
+func (c *MemoryCache) ReleaseIdempotencyKey(ctx context.Context, key, owner string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ownedIdempotencyKey(key, owner); !ok {
+		return models.ErrIdempotencyKeyNotOwned
+	}
+
+	delete(c.entries, idempotencyKey(key))
+	return nil
+}
+
+// ownedIdempotencyKey Возвращает запись ключа, если он зарезервирован owner. Вызывается под блокировкой
+func (c *MemoryCache) ownedIdempotencyKey(key, owner string) (memoryCacheEntry, bool) {
+	entry, ok := c.get(idempotencyKey(key))
+	if !ok || entry.idempotency == nil || entry.idempotency.Owner != owner {
+		return memoryCacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// get Возвращает запись, если срок её жизни не истёк
+func (c *MemoryCache) get(key string) (memoryCacheEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return memoryCacheEntry{}, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return memoryCacheEntry{}, false
+	}
+
+	return entry, true
+}
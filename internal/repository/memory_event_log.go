@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"sort"
+	"sync"
+)
+
+// MemoryEventLog Лог событий об изменении товаров в памяти процесса.
+// Заменяет goods_log в ClickHouse при локальном запуске
+type MemoryEventLog struct {
+	mu     sync.Mutex
+	events []models.ClickhouseEvent
+}
+
+func NewMemoryEventLog() *MemoryEventLog {
+	return &MemoryEventLog{}
+}
+
+func (l *MemoryEventLog) LogGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, *event)
+	return nil
+}
+
+// GetHistory Возвращает события из лога по фильтру, упорядоченные по времени события
+func (l *MemoryEventLog) GetHistory(ctx context.Context, filter models.HistoryFilter) ([]models.ClickhouseEvent, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 10
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	l.mu.Lock()
+	events := l.filterEvents(filter)
+	l.mu.Unlock()
+
+	// Порядок записи в лог используется так же, как LoggedAt в ClickHouse
+	if filter.Desc {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if filter.Desc {
+			return events[i].EventTime.After(events[j].EventTime)
+		}
+		return events[i].EventTime.Before(events[j].EventTime)
+	})
+
+	if filter.Offset >= len(events) {
+		return []models.ClickhouseEvent{}, nil
+	}
+	events = events[filter.Offset:]
+	if len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+
+	return events, nil
+}
+
+// CountHistory Возвращает количество событий в логе, попадающих под фильтр
+func (l *MemoryEventLog) CountHistory(ctx context.Context, filter models.HistoryFilter) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.filterEvents(filter)), nil
+}
+
+// filterEvents Возвращает копии событий, попадающих под фильтр, в порядке записи
+func (l *MemoryEventLog) filterEvents(filter models.HistoryFilter) []models.ClickhouseEvent {
+	events := make([]models.ClickhouseEvent, 0)
+	for _, event := range l.events {
+		if event.ProjectID != filter.ProjectID {
+			continue
+		}
+		if filter.GoodID != 0 && event.ID != filter.GoodID {
+			continue
+		}
+		if filter.From != nil && event.EventTime.Before(*filter.From) {
+			continue
+		}
+		if filter.To != nil && event.EventTime.After(*filter.To) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"goods-service/internal/models"
+	"io"
+)
+
+// ImportGoods Создаёт или обновляет товары проекта по внешнему ключу,
+// как и PostgresRepository.ImportGoods
+func (r *MemoryRepository) ImportGoods(
+	ctx context.Context,
+	projectID int,
+	next func() (*models.ImportRow, error),
+) (*models.ImportResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(projectID) {
+		return nil, models.ErrNotFound
+	}
+
+	existing := make(map[string]models.Good)
+	for _, good := range r.goods {
+		if good.ProjectID == projectID && good.ExternalKey != "" {
+			existing[good.ExternalKey] = *good
+		}
+	}
+
+	var rows []models.ImportRow
+	result := &models.ImportResult{}
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row.Err != nil {
+			result.AddError(row.Line, row.Err)
+			continue
+		}
+		rows = append(rows, *row)
+	}
+
+	created, changes := planImportChunk(rows, existing, result)
+	if result.ErrorCount > 0 {
+		return result, nil
+	}
+
+	if err := r.insertGoods(ctx, projectID, created); err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(changes))
+	for i := range changes {
+		stored := r.goods[changes[i].After.ID]
+		stored.Name = changes[i].After.Name
+		stored.Description = changes[i].After.Description
+		stored.Version = changes[i].After.Version
+		events = append(events, models.NewClickhouseEvent(models.EventGoodUpdated, &changes[i].After, &changes[i].Before))
+	}
+	if err := r.enqueueEvents(ctx, events...); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
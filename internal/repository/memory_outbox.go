@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"math"
+	"time"
+)
+
+// memoryOutboxMessage Событие outbox вместе с состоянием отправки
+type memoryOutboxMessage struct {
+	models.OutboxMessage
+	lastError     string
+	nextAttemptAt time.Time
+	sentAt        *time.Time
+}
+
+// ProcessOutbox Передаёт пачку неотправленных событий в publish и фиксирует
+// результат по тем же правилам, что и PostgresRepository.ProcessOutbox.
+// Возвращает количество обработанных сообщений
+func (r *MemoryRepository) ProcessOutbox(
+	ctx context.Context,
+	limit int,
+	publish func(messages []models.OutboxMessage) []error,
+) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var pending []*memoryOutboxMessage
+	for _, message := range r.outbox {
+		if len(pending) == limit {
+			break
+		}
+		if message.sentAt == nil && !message.nextAttemptAt.After(now) {
+			pending = append(pending, message)
+		}
+	}
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	messages := make([]models.OutboxMessage, len(pending))
+	for i, message := range pending {
+		messages[i] = message.OutboxMessage
+	}
+
+	results := publish(messages)
+
+	now = time.Now()
+	for i, message := range pending {
+		message.Attempts++
+
+		if i < len(results) && results[i] == nil {
+			message.sentAt = &now
+			message.lastError = ""
+			continue
+		}
+
+		message.lastError = "not published"
+		if i < len(results) {
+			message.lastError = results[i].Error()
+		}
+
+		backoff := math.Min(math.Pow(2, float64(message.Attempts-1)), maxOutboxBackoffSeconds)
+		message.nextAttemptAt = now.Add(time.Duration(backoff) * time.Second)
+	}
+
+	return len(pending), nil
+}
+
+// DeleteSentOutbox Удаляет отправленные события, отправленные раньше before
+func (r *MemoryRepository) DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	kept := r.outbox[:0]
+	for _, message := range r.outbox {
+		if message.sentAt != nil && message.sentAt.Before(before) {
+			deleted++
+			continue
+		}
+		kept = append(kept, message)
+	}
+	r.outbox = kept
+
+	return deleted, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"sort"
+	"time"
+)
+
+func (r *MemoryRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastProjectID++
+	project.ID = r.lastProjectID
+	project.Removed = false
+	project.CreatedAt = time.Now()
+
+	stored := *project
+	r.projects[project.ID] = &stored
+
+	return nil
+}
+
+func (r *MemoryRepository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(id) {
+		return nil, models.ErrNotFound
+	}
+
+	project := *r.projects[id]
+	return &project, nil
+}
+
+func (r *MemoryRepository) ListProjects(ctx context.Context, limit, offset int) ([]models.Project, error) {
+	if limit == 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	projects := make([]models.Project, 0, len(r.projects))
+	for _, project := range r.projects {
+		if !project.Removed {
+			projects = append(projects, *project)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+
+	if offset >= len(projects) {
+		return []models.Project{}, nil
+	}
+	projects = projects[offset:]
+	if len(projects) > limit {
+		projects = projects[:limit]
+	}
+
+	return projects, nil
+}
+
+func (r *MemoryRepository) GetProjectsCount(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, project := range r.projects {
+		if !project.Removed {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *MemoryRepository) RenameProject(ctx context.Context, project *models.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(project.ID) {
+		return models.ErrNotFound
+	}
+
+	stored := r.projects[project.ID]
+	stored.Name = project.Name
+	*project = *stored
+
+	return nil
+}
+
+// RemoveProject Помечает проект удалённым вместе со всеми его товарами.
+// Возвращает товары, которые были удалены вместе с проектом
+func (r *MemoryRepository) RemoveProject(ctx context.Context, id int) ([]models.Good, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.projectExists(id) {
+		return nil, models.ErrNotFound
+	}
+	r.projects[id].Removed = true
+
+	removedAt := time.Now()
+	var removedGoods []models.Good
+	var events []*models.ClickhouseEvent
+	for _, good := range r.projectGoods(id) {
+		previous := *good
+		good.Removed = true
+		good.RemovedAt = &removedAt
+		good.Version++
+
+		removed := *good
+		removedGoods = append(removedGoods, removed)
+		events = append(events, models.NewClickhouseEvent(models.EventGoodDeleted, &removed, &previous))
+	}
+
+	if err := r.enqueueEvents(ctx, events...); err != nil {
+		return nil, err
+	}
+
+	return removedGoods, nil
+}
+
+func (r *MemoryRepository) CheckProjectExists(ctx context.Context, id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.projectExists(id), nil
+}
+
+// projectExists Проверяет, что проект существует и не удалён
+func (r *MemoryRepository) projectExists(id int) bool {
+	project, ok := r.projects[id]
+	return ok && !project.Removed
+}
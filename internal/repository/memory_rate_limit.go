@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"math"
+	"time"
+)
+
+// memoryBucket Токены бакета на момент updatedAt
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// TakeTokens Забирает по токену из каждого бакета, только если токен есть во всех.
+// Алгоритм совпадает со скриптом RedisRepository
+func (c *MemoryCache) TakeTokens(ctx context.Context, buckets []models.RateLimitBucket) (bool, []models.RateLimitState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	tokens := make([]float64, len(buckets))
+	allowed := true
+	for i, bucket := range buckets {
+		capacity := float64(bucket.Limit.Requests)
+		tokens[i] = capacity
+		if entry, ok := c.get(rateLimitKey(bucket.Key)); ok {
+			elapsed := now.Sub(entry.bucket.updatedAt)
+			tokens[i] = math.Min(capacity, entry.bucket.tokens+capacity*float64(elapsed)/float64(bucket.Limit.Period))
+		}
+		if tokens[i] < 1 {
+			allowed = false
+		}
+	}
+
+	states := make([]models.RateLimitState, 0, len(buckets))
+	for i, bucket := range buckets {
+		available := tokens[i]
+		if allowed {
+			available--
+		}
+
+		capacity := float64(bucket.Limit.Requests)
+		period := float64(bucket.Limit.Period)
+		state := models.RateLimitState{
+			Remaining: int(available),
+			Reset:     time.Duration(math.Ceil((capacity - available) * period / capacity)),
+		}
+		if available < 1 {
+			state.RetryAfter = time.Duration(math.Ceil((1 - available) * period / capacity))
+		}
+
+		c.entries[rateLimitKey(bucket.Key)] = memoryCacheEntry{
+			bucket:    &memoryBucket{tokens: available, updatedAt: now},
+			expiresAt: now.Add(state.Reset + time.Second),
+		}
+		states = append(states, state)
+	}
+
+	return allowed, states, nil
+}
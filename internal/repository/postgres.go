@@ -3,9 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"goods-service/internal/models"
+	"strings"
+	"time"
 )
 
 type PostgresRepository struct {
@@ -17,171 +21,510 @@ func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
 }
 
 func (r *PostgresRepository) CreateGood(ctx context.Context, good *models.Good) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Вставка происходит только в существующий неудалённый проект.
+	// Блокировка проекта исключает одинаковые приоритеты при параллельной вставке
+	if err = lockProject(ctx, tx, good.ProjectID); err != nil {
+		return err
+	}
+
 	query := `
-        INSERT INTO goods (project_id, name, description, priority)
-        VALUES ($1, $2, $3, (
+        INSERT INTO goods (project_id, external_key, name, description, priority)
+        SELECT $1, $4, $2, $3, (
             SELECT COALESCE(MAX(priority), 0) + 1 
             FROM goods 
             WHERE project_id = $1 AND NOT removed
-        ))
-        RETURNING id, priority, created_at`
+        )
+        RETURNING id, priority, created_at, version`
 
-	return r.pool.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		good.ProjectID,
 		good.Name,
 		good.Description,
-	).Scan(&good.ID, &good.Priority, &good.CreatedAt)
+		good.ExternalKey,
+	).Scan(&good.ID, &good.Priority, &good.CreatedAt, &good.Version)
+	if isForeignKeyViolation(err) {
+		return models.ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return models.ErrDuplicateExternalKey
+	}
+	if err != nil {
+		return err
+	}
+
+	// Событие для логирования в ClickHouse отправляется через outbox
+	if err = r.enqueueEvents(ctx, tx, models.NewClickhouseEvent(models.EventGoodCreated, good, nil)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-func (r *PostgresRepository) UpdateGood(ctx context.Context, good *models.Good) error {
+// UpdateGood Применяет к товару good.ID изменение patch и заполняет good новым
+// состоянием. Если good.Version не 0, товар обновляется, только если хранится
+// в этой версии, иначе возвращается models.ErrVersionMismatch. Если patch ничего
+// не меняет, версия не увеличивается и событие не записывается.
+// Возвращает состояние товара до изменения
+func (r *PostgresRepository) UpdateGood(ctx context.Context, good *models.Good, patch models.GoodPatch) (*models.Good, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	previous := models.Good{ID: good.ID, ProjectID: good.ProjectID}
+	err = tx.QueryRow(ctx, `
+		SELECT external_key, name, description, priority, removed, created_at, version
+		FROM goods
+		WHERE id = $1 AND project_id = $2 AND NOT removed
+		FOR UPDATE`,
+		good.ID, good.ProjectID,
+	).Scan(&previous.ExternalKey, &previous.Name, &previous.Description, &previous.Priority, &previous.Removed, &previous.CreatedAt, &previous.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err = checkVersion(good.Version, previous.Version); err != nil {
+		return nil, err
+	}
+
+	updated, changed := patchGood(previous, patch)
+	if !changed {
+		*good = previous
+		return &previous, tx.Commit(ctx)
+	}
+
 	query := `
         UPDATE goods 
-        SET name = $1, description = $2
+        SET name = $1, description = $2, version = version + 1
         WHERE id = $3 AND project_id = $4
-        RETURNING name, description, priority, created_at`
+        RETURNING external_key, name, description, priority, created_at, version`
 
 	err = tx.QueryRow(ctx, query,
-		good.Name,
-		good.Description,
+		updated.Name,
+		updated.Description,
 		good.ID,
 		good.ProjectID,
-	).Scan(&good.Name, &good.Description, &good.Priority, &good.CreatedAt)
+	).Scan(&good.ExternalKey, &good.Name, &good.Description, &good.Priority, &good.CreatedAt, &good.Version)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if err = r.enqueueEvents(ctx, tx, models.NewClickhouseEvent(models.EventGoodUpdated, good, &previous)); err != nil {
+		return nil, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return &previous, nil
 }
 
-func (r *PostgresRepository) ReprioritizeGoods(ctx context.Context, id, projectID, newPriority int) ([]models.Good, error) {
+// CreateGoods Создаёт пачку товаров проекта в одной транзакции.
+// Приоритеты назначаются в конце списка проекта в порядке goods.
+// Возвращает ошибку по каждому товару в порядке goods, nil - товар создан,
+// models.ErrDuplicateExternalKey - внешний ключ уже есть в проекте или повторяется
+// в пачке. Если atomic и хотя бы один товар не создан, изменения не сохраняются
+// и возвращается models.ErrBatchAborted
+func (r *PostgresRepository) CreateGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error) {
+	if len(goods) == 0 {
+		return nil, nil
+	}
+
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	// Проверяем существование записи
-	exists, err := r.CheckGoodExists(ctx, id, projectID)
-	if err != nil {
+	// Блокировка проекта исключает вставку тех же внешних ключей между проверкой и вставкой
+	if err = lockProject(ctx, tx, projectID); err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, models.ErrNotFound
-	}
 
-	// Обновляем приоритеты у всех записей с большим или равным приоритетом
-	_, err = tx.Exec(ctx, `
-		UPDATE goods
-		SET priority = priority + 1
-		WHERE project_id = $1
-		AND priority >= $2
-		AND id != $3
-		AND NOT removed`,
-		projectID, newPriority, id)
+	existing, err := existingExternalKeys(ctx, tx, projectID, goods)
 	if err != nil {
 		return nil, err
 	}
 
-	// Устанавливаем новый приоритет целевой записи
-	_, err = tx.Exec(ctx, `
-		UPDATE goods
-		SET priority = $1
-		WHERE id = $2 AND project_id = $3`,
-		newPriority, id, projectID)
-	if err != nil {
+	valid, errs := newBatchGoods(goods, existing)
+	if atomic && len(valid) < len(goods) {
+		return errs, models.ErrBatchAborted
+	}
+	if len(valid) == 0 {
+		return errs, nil
+	}
+
+	created := make([]models.Good, 0, len(valid))
+	for _, i := range valid {
+		created = append(created, goods[i])
+	}
+	if err = r.insertGoods(ctx, tx, projectID, created); err != nil {
 		return nil, err
 	}
 
-	// Обновляем приоритет у остальных записей
-	_, err = tx.Exec(ctx, `
-		UPDATE goods
-		SET priority = priority + 1
-		WHERE id != $1
-		AND project_id != $2
-		AND priority >= $3
-		AND NOT removed`,
-		id, projectID, newPriority)
-	if err != nil {
+	if err = tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
-	// Получаем все обновлённые записи
+	for j, i := range valid {
+		goods[i] = created[j]
+	}
+
+	return errs, nil
+}
+
+// existingExternalKeys Возвращает непустые внешние ключи goods, которые уже есть
+// у товаров проекта, в том числе удалённых
+func existingExternalKeys(ctx context.Context, tx pgx.Tx, projectID int, goods []models.Good) (map[string]bool, error) {
+	keys := make([]string, 0, len(goods))
+	for i := range goods {
+		if goods[i].ExternalKey != "" {
+			keys = append(keys, goods[i].ExternalKey)
+		}
+	}
+
+	existing := make(map[string]bool)
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
 	rows, err := tx.Query(ctx, `
-		SELECT id, project_id, name, description, priority, removed
+		SELECT external_key
 		FROM goods
-		WHERE priority >= $1
-		AND NOT removed
-		ORDER BY priority`,
-		newPriority)
+		WHERE project_id = $1 AND external_key = ANY($2)`,
+		projectID, keys)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var updatedPriorities []models.Good
 	for rows.Next() {
-		var item models.Good
-		err := rows.Scan(
-			&item.ID,
-			&item.ProjectID,
-			&item.Name,
-			&item.Description,
-			&item.Priority,
-			&item.Removed)
-		if err != nil {
+		var key string
+		if err = rows.Scan(&key); err != nil {
 			return nil, err
 		}
-		updatedPriorities = append(updatedPriorities, item)
+		existing[key] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// UpdateGoods Применяет к пачке товаров проекта изменения patches[i] в одной
+// транзакции и заполняет goods новым состоянием, как и UpdateGood.
+// Возвращает ошибку по каждому товару в порядке goods, nil - товар обновлён.
+// Товары с ненулевой версией обновляются, только если хранятся в этой версии.
+// Если atomic и хотя бы один товар не найден, изменения не сохраняются
+// и возвращается models.ErrBatchAborted
+func (r *PostgresRepository) UpdateGoods(ctx context.Context, projectID int, goods []models.Good, patches []models.GoodPatch, atomic bool) ([]error, error) {
+	return r.changeGoods(ctx, projectID, goods, atomic, func(tx pgx.Tx, found []int, previous map[int]models.Good) error {
+		updated := make([]models.Good, 0, len(found))
+		for _, i := range found {
+			if good, changed := patchGood(previous[goods[i].ID], patches[i]); changed {
+				updated = append(updated, good)
+			}
+		}
+		if len(updated) == 0 {
+			return nil
+		}
+
+		return updateGoodTexts(ctx, tx, updated)
+	}, func(i int, previous models.Good) *models.ClickhouseEvent {
+		updated, changed := patchGood(previous, patches[i])
+		if !changed {
+			goods[i] = previous
+			return nil
+		}
+
+		updated.Version++
+		goods[i] = updated
+
+		return models.NewClickhouseEvent(models.EventGoodUpdated, &goods[i], &previous)
+	})
+}
+
+// RemoveGoods Отмечает удалёнными пачку товаров проекта в одной транзакции.
+// Ошибки по товарам и признак atomic обрабатываются так же, как в UpdateGoods
+func (r *PostgresRepository) RemoveGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error) {
+	removedAt := make(map[int]*time.Time, len(goods))
+	return r.changeGoods(ctx, projectID, goods, atomic, func(tx pgx.Tx, found []int, _ map[int]models.Good) error {
+		ids := make([]int, 0, len(found))
+		for _, i := range found {
+			ids = append(ids, goods[i].ID)
+		}
+
+		rows, err := tx.Query(ctx, `
+			UPDATE goods
+			SET removed = true, removed_at = NOW(), version = version + 1
+			WHERE id = ANY($1)
+			RETURNING id, removed_at`,
+			ids)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int
+			var at time.Time
+			if err := rows.Scan(&id, &at); err != nil {
+				return err
+			}
+			removedAt[id] = &at
+		}
+
+		return rows.Err()
+	}, func(i int, previous models.Good) *models.ClickhouseEvent {
+		removed := previous
+		removed.Removed = true
+		removed.RemovedAt = removedAt[previous.ID]
+		removed.Version++
+		goods[i] = removed
+
+		return models.NewClickhouseEvent(models.EventGoodDeleted, &goods[i], &previous)
+	})
+}
+
+// changeGoods Блокирует неудалённые товары проекта из goods, сохраняет изменения
+// найденных функцией apply и записывает события, сформированные функцией event.
+// apply получает индексы найденных товаров и их состояние до изменения по id.
+// event получает индекс товара в goods и его состояние до изменения, заполняет
+// товар итоговым состоянием и возвращает событие либо nil, если товар не изменился
+func (r *PostgresRepository) changeGoods(
+	ctx context.Context,
+	projectID int,
+	goods []models.Good,
+	atomic bool,
+	apply func(tx pgx.Tx, found []int, previous map[int]models.Good) error,
+	event func(i int, previous models.Good) *models.ClickhouseEvent,
+) ([]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	ids := make([]int, 0, len(goods))
+	for i := range goods {
+		ids = append(ids, goods[i].ID)
+	}
+
+	// Строки блокируются в порядке id, чтобы параллельные пачки не взаимоблокировались
+	rows, err := tx.Query(ctx, `
+		SELECT id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version
+		FROM goods
+		WHERE project_id = $1 AND id = ANY($2) AND NOT removed
+		ORDER BY id
+		FOR UPDATE`,
+		projectID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := collectGoods(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	found, errs := matchBatchGoods(goods, stored)
+	if atomic && len(found) < len(goods) {
+		return errs, models.ErrBatchAborted
+	}
+	if len(found) == 0 {
+		return errs, nil
+	}
+
+	previous := make(map[int]models.Good, len(stored))
+	for _, good := range stored {
+		previous[good.ID] = good
+	}
+
+	if err = apply(tx, found, previous); err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(found))
+	for _, i := range found {
+		if e := event(i, previous[goods[i].ID]); e != nil {
+			events = append(events, e)
+		}
+	}
+	if err = r.enqueueEvents(ctx, tx, events...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
+
+// ReprioritizeGoods Перемещает товар на позицию newPriority среди неудалённых товаров
+// проекта. Приоритеты товаров проекта пересчитываются подряд начиная с 1, позиция
+// за пределами списка означает конец списка. Если version не 0, товар перемещается,
+// только если хранится в этой версии. Возвращает только товары, приоритет
+// которых изменился
+func (r *PostgresRepository) ReprioritizeGoods(ctx context.Context, id, projectID, newPriority, version int) ([]models.GoodChange, error) {
+	return r.reorderProject(ctx, projectID, func(goods []models.Good) ([]models.GoodChange, error) {
+		return reprioritizeGood(goods, id, newPriority, version)
+	})
+}
+
+// ReorderGoods Меняет порядок товаров проекта в одной транзакции. По каждому
+// товару, приоритет которого изменился, записывается одно событие с итоговым приоритетом
+func (r *PostgresRepository) ReorderGoods(ctx context.Context, projectID int, reorder models.GoodsReorder) ([]models.GoodChange, error) {
+	return r.reorderProject(ctx, projectID, func(goods []models.Good) ([]models.GoodChange, error) {
+		return applyGoodsReorder(goods, reorder)
+	})
+}
+
+// reorderProject Блокирует проект и его неудалённые товары, вычисляет новые
+// приоритеты функцией reorder и сохраняет изменившиеся вместе с событиями
+func (r *PostgresRepository) reorderProject(
+	ctx context.Context,
+	projectID int,
+	reorder func(goods []models.Good) ([]models.GoodChange, error),
+) ([]models.GoodChange, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = lockProject(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+
+	// После блокировки проекта выборка видит все зафиксированные изменения его товаров
+	rows, err := tx.Query(ctx, `
+		SELECT id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version
+		FROM goods
+		WHERE project_id = $1 AND NOT removed
+		ORDER BY priority, id
+		FOR UPDATE`,
+		projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	goods, err := collectGoods(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := reorder(goods)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return changes, tx.Commit(ctx)
+	}
+
+	ids := make([]int, 0, len(changes))
+	priorities := make([]int, 0, len(changes))
+	events := make([]*models.ClickhouseEvent, 0, len(changes))
+	for i := range changes {
+		change := &changes[i]
+		ids = append(ids, change.After.ID)
+		priorities = append(priorities, change.After.Priority)
+		events = append(events, models.NewClickhouseEvent(models.EventGoodReprioritized, &change.After, &change.Before))
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE goods
+		SET priority = changed.priority, version = goods.version + 1
+		FROM unnest($1::int[], $2::int[]) AS changed(id, priority)
+		WHERE goods.id = changed.id`,
+		ids, priorities)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.enqueueEvents(ctx, tx, events...); err != nil {
+		return nil, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return nil, err
 	}
 
-	return updatedPriorities, nil
+	return changes, nil
 }
 
-func (r *PostgresRepository) ListGoods(ctx context.Context, limit, offset int) ([]models.Good, error) {
-	if limit == 0 {
-		limit = 10
+func (r *PostgresRepository) ListGoods(ctx context.Context, filter models.GoodsFilter) ([]models.Good, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 10
 	}
-	if offset < 0 {
-		offset = 0
+	if filter.Offset < 0 {
+		filter.Offset = 0
 	}
 
-	query := `
-        SELECT id, project_id, name, description, priority, removed, created_at
+	where, args := buildGoodsFilter(filter, !filter.IncludeRemoved)
+
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	var query string
+	if filter.Keyset {
+		// Обход по ключу (project_id, priority, id) использует индекс idx_goods_project_priority
+		if filter.After != nil {
+			where += fmt.Sprintf(" AND (priority, id) %s ($%d, $%d)", comparison, len(args)+1, len(args)+2)
+			args = append(args, filter.After.Priority, filter.After.ID)
+		}
+
+		query = fmt.Sprintf(`
+        SELECT id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version
+        FROM goods
+        WHERE %s
+        ORDER BY priority %s, id %s
+        LIMIT $%d`,
+			where, direction, direction, len(args)+1)
+		args = append(args, filter.Limit)
+	} else {
+		// id добавляется в сортировку, чтобы порядок страниц был стабильным
+		query = fmt.Sprintf(`
+        SELECT id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version
         FROM goods
-        WHERE removed = false
-        LIMIT $1 OFFSET $2`
+        WHERE %s
+        ORDER BY %s %s, id %s
+        LIMIT $%d OFFSET $%d`,
+			where, sortColumn(filter.SortBy), direction, direction, len(args)+1, len(args)+2)
+		args = append(args, filter.Limit, filter.Offset)
+	}
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var goods []models.Good
+	goods := make([]models.Good, 0, filter.Limit)
 	for rows.Next() {
 		var good models.Good
 		err := rows.Scan(
 			&good.ID,
 			&good.ProjectID,
+			&good.ExternalKey,
 			&good.Name,
 			&good.Description,
 			&good.Priority,
 			&good.Removed,
+			&good.RemovedAt,
 			&good.CreatedAt,
+			&good.Version,
 		)
 		if err != nil {
 			return nil, err
@@ -196,9 +539,26 @@ func (r *PostgresRepository) ListGoods(ctx context.Context, limit, offset int) (
 	return goods, nil
 }
 
+// CountGoods Возвращает количество неудалённых и удалённых товаров,
+// попадающих под фильтр. Пагинация и сортировка фильтра не учитываются
+func (r *PostgresRepository) CountGoods(ctx context.Context, filter models.GoodsFilter) (total int, removed int, err error) {
+	where, args := buildGoodsFilter(filter, false)
+
+	query := fmt.Sprintf(`
+        SELECT
+            COUNT(*) FILTER (WHERE NOT removed),
+            COUNT(*) FILTER (WHERE removed)
+        FROM goods
+        WHERE %s`, where)
+
+	err = r.pool.QueryRow(ctx, query, args...).Scan(&total, &removed)
+
+	return total, removed, err
+}
+
 func (r *PostgresRepository) GetGood(ctx context.Context, id, projectID int) (*models.Good, error) {
 	query := `
-        SELECT id, project_id, name, description, priority, removed, created_at
+        SELECT id, project_id, external_key, name, description, priority, removed, created_at, version
         FROM goods
         WHERE id = $1 AND project_id = $2 AND removed = false`
 
@@ -206,11 +566,13 @@ func (r *PostgresRepository) GetGood(ctx context.Context, id, projectID int) (*m
 	err := r.pool.QueryRow(ctx, query, id, projectID).Scan(
 		&good.ID,
 		&good.ProjectID,
+		&good.ExternalKey,
 		&good.Name,
 		&good.Description,
 		&good.Priority,
 		&good.Removed,
 		&good.CreatedAt,
+		&good.Version,
 	)
 
 	if err != nil {
@@ -223,6 +585,8 @@ func (r *PostgresRepository) GetGood(ctx context.Context, id, projectID int) (*m
 	return &good, nil
 }
 
+// MarkAsRemoved Отмечает товар удалённым. Если good.Version не 0, товар удаляется,
+// только если хранится в этой версии, иначе возвращается models.ErrVersionMismatch
 func (r *PostgresRepository) MarkAsRemoved(ctx context.Context, good *models.Good) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
@@ -231,26 +595,45 @@ func (r *PostgresRepository) MarkAsRemoved(ctx context.Context, good *models.Goo
 	defer tx.Rollback(ctx)
 
 	// Проверка записи
-	exists, err := r.CheckGoodExists(ctx, good.ID, good.ProjectID)
+	var version int
+	err = tx.QueryRow(ctx, `
+		SELECT version
+		FROM goods
+		WHERE id = $1 AND project_id = $2 AND NOT removed
+		FOR UPDATE`,
+		good.ID, good.ProjectID,
+	).Scan(&version)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrNotFound
+		}
 		return err
 	}
-	if !exists {
-		return errors.New("good not found")
+
+	if err = checkVersion(good.Version, version); err != nil {
+		return err
 	}
 
 	query := `
         UPDATE goods 
-        SET removed = true 
+        SET removed = true, removed_at = NOW(), version = version + 1
         WHERE id = $1 AND project_id = $2 AND removed = false
-        RETURNING name, description, priority`
+        RETURNING external_key, name, description, priority, removed, removed_at, created_at, version`
 
 	err = tx.QueryRow(ctx, query, good.ID, good.ProjectID).Scan(
-		&good.Name, &good.Description, &good.Priority)
+		&good.ExternalKey, &good.Name, &good.Description, &good.Priority, &good.Removed, &good.RemovedAt, &good.CreatedAt, &good.Version)
 	if err != nil {
 		return err
 	}
 
+	previous := *good
+	previous.Removed = false
+	previous.RemovedAt = nil
+	previous.Version = version
+	if err = r.enqueueEvents(ctx, tx, models.NewClickhouseEvent(models.EventGoodDeleted, good, &previous)); err != nil {
+		return err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -258,26 +641,138 @@ func (r *PostgresRepository) MarkAsRemoved(ctx context.Context, good *models.Goo
 	return nil
 }
 
-func (r *PostgresRepository) GetTotalCount(ctx context.Context) (int, error) {
-	var count int
-	err := r.pool.QueryRow(ctx, `
-        SELECT COUNT(*) 
-        FROM goods 
-        WHERE removed = false`,
-	).Scan(&count)
+// RestoreGood Снимает отметку об удалении с товара.
+// Восстановленный товар получает приоритет в конце списка проекта.
+// Возвращает состояние товара до восстановления
+func (r *PostgresRepository) RestoreGood(ctx context.Context, good *models.Good) (*models.Good, error) {
+	query := `
+        WITH previous AS (
+            SELECT id, priority, removed_at, version
+            FROM goods
+            WHERE id = $1 AND project_id = $2 AND removed
+            FOR UPDATE
+        )
+        UPDATE goods
+        SET removed = false, removed_at = NULL, version = goods.version + 1, priority = (
+            SELECT COALESCE(MAX(priority), 0) + 1
+            FROM goods
+            WHERE project_id = $2 AND NOT removed
+        )
+        FROM previous
+        WHERE goods.id = previous.id
+        RETURNING goods.external_key, goods.name, goods.description, goods.priority, goods.removed, goods.removed_at, goods.created_at,
+            goods.version, previous.priority, previous.removed_at, previous.version`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Товар восстанавливается только в неудалённый проект
+	if err = lockProject(ctx, tx, good.ProjectID); err != nil {
+		return nil, err
+	}
+
+	previous := models.Good{ID: good.ID, ProjectID: good.ProjectID, Removed: true}
+	err = tx.QueryRow(ctx, query, good.ID, good.ProjectID).Scan(
+		&good.ExternalKey,
+		&good.Name,
+		&good.Description,
+		&good.Priority,
+		&good.Removed,
+		&good.RemovedAt,
+		&good.CreatedAt,
+		&good.Version,
+		&previous.Priority,
+		&previous.RemovedAt,
+		&previous.Version,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	previous.ExternalKey = good.ExternalKey
+	previous.Name = good.Name
+	previous.Description = good.Description
+	previous.CreatedAt = good.CreatedAt
+
+	if err = r.enqueueEvents(ctx, tx, models.NewClickhouseEvent(models.EventGoodRestored, good, &previous)); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &previous, nil
+}
+
+// ListPurgeableGoods Возвращает товары, удалённые раньше removedBefore, с id больше afterID
+func (r *PostgresRepository) ListPurgeableGoods(ctx context.Context, removedBefore time.Time, afterID, limit int) ([]models.Good, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version
+		FROM goods
+		WHERE removed
+		AND removed_at < $1
+		AND id > $2
+		ORDER BY id
+		LIMIT $3`,
+		removedBefore, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
 
-	return count, err
+	return collectGoods(rows)
 }
 
-func (r *PostgresRepository) GetRemovedCount(ctx context.Context) (int, error) {
-	var count int
-	err := r.pool.QueryRow(ctx, `
-        SELECT COUNT(*) 
-        FROM goods 
-        WHERE removed = true`,
-	).Scan(&count)
+// PurgeRemovedGoods Физически удаляет пачку товаров, удалённых раньше removedBefore.
+// Строки, заблокированные другими транзакциями, пропускаются
+func (r *PostgresRepository) PurgeRemovedGoods(ctx context.Context, removedBefore time.Time, limit int) ([]models.Good, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		DELETE FROM goods
+		WHERE id IN (
+			SELECT id
+			FROM goods
+			WHERE removed
+			AND removed_at < $1
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version`,
+		removedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
 
-	return count, err
+	goods, err := collectGoods(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(goods))
+	for i := range goods {
+		events = append(events, models.NewClickhouseEvent(models.EventGoodPurged, &goods[i], nil))
+	}
+	if err = r.enqueueEvents(ctx, tx, events...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return goods, nil
 }
 
 func (r *PostgresRepository) CheckGoodExists(ctx context.Context, id, projectId int) (bool, error) {
@@ -298,3 +793,203 @@ func (r *PostgresRepository) CheckGoodExists(ctx context.Context, id, projectId
 
 	return exists, nil
 }
+
+// insertGoods Вставляет товары в конец списка проекта в порядке goods, заполняет
+// их сохранёнными значениями и записывает события создания. Проект должен быть
+// заблокирован в транзакции tx
+func (r *PostgresRepository) insertGoods(ctx context.Context, tx pgx.Tx, projectID int, goods []models.Good) error {
+	if len(goods) == 0 {
+		return nil
+	}
+
+	var lastPriority int
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(MAX(priority), 0)
+		FROM goods
+		WHERE project_id = $1 AND NOT removed`,
+		projectID).Scan(&lastPriority)
+	if err != nil {
+		return err
+	}
+
+	externalKeys := make([]string, 0, len(goods))
+	names := make([]string, 0, len(goods))
+	descriptions := make([]string, 0, len(goods))
+	for i := range goods {
+		externalKeys = append(externalKeys, goods[i].ExternalKey)
+		names = append(names, goods[i].Name)
+		descriptions = append(descriptions, goods[i].Description)
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO goods (project_id, external_key, name, description, priority)
+		SELECT $1, item.external_key, item.name, item.description, $5 + item.ord
+		FROM unnest($2::text[], $3::text[], $4::text[]) WITH ORDINALITY AS item(external_key, name, description, ord)
+		ORDER BY item.ord
+		RETURNING id, priority, created_at, version`,
+		projectID, externalKeys, names, descriptions, lastPriority)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// Порядок RETURNING не гарантирован, товар находится по назначенному приоритету
+	for rows.Next() {
+		var id, priority, version int
+		var createdAt time.Time
+		if err = rows.Scan(&id, &priority, &createdAt, &version); err != nil {
+			return err
+		}
+
+		good := &goods[priority-lastPriority-1]
+		good.ID = id
+		good.ProjectID = projectID
+		good.Priority = priority
+		good.CreatedAt = createdAt
+		good.Version = version
+	}
+	if err = rows.Err(); err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrDuplicateExternalKey
+		}
+		return err
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(goods))
+	for i := range goods {
+		events = append(events, models.NewClickhouseEvent(models.EventGoodCreated, &goods[i], nil))
+	}
+
+	return r.enqueueEvents(ctx, tx, events...)
+}
+
+// updateGoodTexts Сохраняет название и описание товаров одним запросом и увеличивает их версию
+func updateGoodTexts(ctx context.Context, tx pgx.Tx, goods []models.Good) error {
+	ids := make([]int, 0, len(goods))
+	names := make([]string, 0, len(goods))
+	descriptions := make([]string, 0, len(goods))
+	for i := range goods {
+		ids = append(ids, goods[i].ID)
+		names = append(names, goods[i].Name)
+		descriptions = append(descriptions, goods[i].Description)
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE goods
+		SET name = item.name, description = item.description, version = goods.version + 1
+		FROM unnest($1::int[], $2::text[], $3::text[]) AS item(id, name, description)
+		WHERE goods.id = item.id`,
+		ids, names, descriptions)
+
+	return err
+}
+
+// lockProject Блокирует неудалённый проект до конца транзакции. Изменения
+// порядка товаров одного проекта выполняются последовательно
+func lockProject(ctx context.Context, tx pgx.Tx, projectID int) error {
+	var id int
+	err := tx.QueryRow(ctx, `
+		SELECT id
+		FROM projects
+		WHERE id = $1 AND NOT removed
+		FOR NO KEY UPDATE`,
+		projectID).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNotFound
+	}
+
+	return err
+}
+
+// isUniqueViolation Проверяет, что ошибка вызвана нарушением уникальности
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation Проверяет, что ошибка вызвана нарушением внешнего ключа
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// buildGoodsFilter Формирует условие WHERE и его аргументы по фильтру товаров
+func buildGoodsFilter(filter models.GoodsFilter, activeOnly bool) (string, []interface{}) {
+	conditions := []string{"project_id = $1"}
+	args := []interface{}{filter.ProjectID}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if activeOnly {
+		conditions = append(conditions, "NOT removed")
+	}
+	if filter.Name != "" {
+		addCondition(`name ILIKE '%%' || $%d || '%%'`, likeEscaper.Replace(filter.Name))
+	}
+	if filter.PriorityFrom != nil {
+		addCondition("priority >= $%d", *filter.PriorityFrom)
+	}
+	if filter.PriorityTo != nil {
+		addCondition("priority <= $%d", *filter.PriorityTo)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at <= $%d", *filter.CreatedTo)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// likeEscaper Экранирует спецсимволы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// sortColumn Возвращает колонку для сортировки по полю модели
+func sortColumn(sortBy string) string {
+	switch sortBy {
+	case models.SortByCreatedAt:
+		return "created_at"
+	case models.SortByName:
+		return "name"
+	case models.SortByID:
+		return "id"
+	default:
+		return "priority"
+	}
+}
+
+// collectGoods Считывает товары из результата запроса и закрывает его
+func collectGoods(rows pgx.Rows) ([]models.Good, error) {
+	defer rows.Close()
+
+	var goods []models.Good
+	for rows.Next() {
+		var good models.Good
+		err := rows.Scan(
+			&good.ID,
+			&good.ProjectID,
+			&good.ExternalKey,
+			&good.Name,
+			&good.Description,
+			&good.Priority,
+			&good.Removed,
+			&good.RemovedAt,
+			&good.CreatedAt,
+			&good.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		goods = append(goods, good)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return goods, nil
+}
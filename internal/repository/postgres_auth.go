@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"goods-service/internal/models"
+)
+
+// GetAPIKey Возвращает действующий API ключ по SHA-256 хэшу. Если ключа нет
+// или он отозван, возвращает models.ErrNotFound
+func (r *PostgresRepository) GetAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+        SELECT id, name, subject, created_at, revoked_at
+        FROM api_keys
+        WHERE key_hash = $1 AND revoked_at IS NULL`
+
+	var key models.APIKey
+	err := r.pool.QueryRow(ctx, query, keyHash).Scan(
+		&key.ID,
+		&key.Name,
+		&key.Subject,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+// GetProjectGrants Возвращает доступ клиента subject к проектам
+func (r *PostgresRepository) GetProjectGrants(ctx context.Context, subject string) ([]models.ProjectGrant, error) {
+	query := `
+        SELECT project_id, access
+        FROM project_grants
+        WHERE subject = $1
+        ORDER BY project_id`
+
+	rows, err := r.pool.Query(ctx, query, subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []models.ProjectGrant
+	for rows.Next() {
+		var grant models.ProjectGrant
+		if err := rows.Scan(&grant.ProjectID, &grant.Access); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
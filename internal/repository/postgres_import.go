@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"goods-service/internal/models"
+	"io"
+)
+
+// importChunkSize Количество строк импорта, сохраняемых одним запросом
+const importChunkSize = 500
+
+// ImportGoods Создаёт или обновляет товары проекта по внешнему ключу в одной
+// транзакции. Строки читаются функцией next до io.EOF и сохраняются частями.
+// next вызывается под блокировкой проекта и не должна ждать клиента.
+// Новые товары получают приоритеты в конце списка в порядке строк. Строки с ошибкой
+// проверки и строки с ключом удалённого товара попадают в ошибки результата,
+// при наличии ошибок изменения не сохраняются
+func (r *PostgresRepository) ImportGoods(
+	ctx context.Context,
+	projectID int,
+	next func() (*models.ImportRow, error),
+) (*models.ImportResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err = lockProject(ctx, tx, projectID); err != nil {
+		return nil, err
+	}
+
+	result := &models.ImportResult{}
+	chunk := make([]models.ImportRow, 0, importChunkSize)
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row.Err != nil {
+			result.AddError(row.Line, row.Err)
+			continue
+		}
+
+		chunk = append(chunk, *row)
+		if len(chunk) < importChunkSize {
+			continue
+		}
+
+		if err = r.importChunk(ctx, tx, projectID, chunk, result); err != nil {
+			return nil, err
+		}
+		chunk = chunk[:0]
+	}
+
+	if err = r.importChunk(ctx, tx, projectID, chunk, result); err != nil {
+		return nil, err
+	}
+
+	if result.ErrorCount > 0 {
+		return result, nil
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+// importChunk Сохраняет часть строк импорта и учитывает их в результате
+func (r *PostgresRepository) importChunk(
+	ctx context.Context,
+	tx pgx.Tx,
+	projectID int,
+	chunk []models.ImportRow,
+	result *models.ImportResult,
+) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(chunk))
+	for _, row := range chunk {
+		keys = append(keys, row.Good.ExternalKey)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version
+		FROM goods
+		WHERE project_id = $1 AND external_key = ANY($2)
+		ORDER BY id
+		FOR UPDATE`,
+		projectID, keys)
+	if err != nil {
+		return err
+	}
+
+	stored, err := collectGoods(rows)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]models.Good, len(stored))
+	for _, good := range stored {
+		existing[good.ExternalKey] = good
+	}
+
+	created, changes := planImportChunk(chunk, existing, result)
+
+	if err = r.insertGoods(ctx, tx, projectID, created); err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	updated := make([]models.Good, 0, len(changes))
+	events := make([]*models.ClickhouseEvent, 0, len(changes))
+	for i := range changes {
+		updated = append(updated, changes[i].After)
+		events = append(events, models.NewClickhouseEvent(models.EventGoodUpdated, &changes[i].After, &changes[i].Before))
+	}
+
+	if err = updateGoodTexts(ctx, tx, updated); err != nil {
+		return err
+	}
+
+	return r.enqueueEvents(ctx, tx, events...)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"goods-service/internal/models"
+	"time"
+)
+
+// maxOutboxBackoffSeconds Максимальная задержка перед повторной отправкой события
+const maxOutboxBackoffSeconds = 300
+
+// enqueueEvents Записывает события в outbox в рамках транзакции изменения.
+// Тема сообщения совпадает с типом события. В события записывается клиент запроса
+func (r *PostgresRepository) enqueueEvents(ctx context.Context, tx pgx.Tx, events ...*models.ClickhouseEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	actor := models.ActorFromContext(ctx)
+	subjects := make([]string, 0, len(events))
+	payloads := make([]string, 0, len(events))
+	for _, event := range events {
+		event.Actor = actor
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("error marshaling event: %v", err)
+		}
+
+		subjects = append(subjects, event.EventType)
+		payloads = append(payloads, string(payload))
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO outbox (subject, payload)
+		SELECT subject, payload
+		FROM unnest($1::text[], $2::jsonb[]) AS t(subject, payload)`,
+		subjects, payloads)
+
+	return err
+}
+
+// ProcessOutbox Блокирует пачку неотправленных событий, передаёт их в publish
+// и фиксирует результат. publish возвращает ошибку для каждого сообщения
+// в том же порядке, nil - сообщение доставлено. Недоставленные сообщения
+// получают экспоненциально растущую задержку перед следующей попыткой.
+// Возвращает количество обработанных сообщений
+func (r *PostgresRepository) ProcessOutbox(
+	ctx context.Context,
+	limit int,
+	publish func(messages []models.OutboxMessage) []error,
+) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, subject, payload, attempts, created_at
+		FROM outbox
+		WHERE sent_at IS NULL
+		AND next_attempt_at <= NOW()
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`,
+		limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var messages []models.OutboxMessage
+	for rows.Next() {
+		var message models.OutboxMessage
+		err := rows.Scan(
+			&message.ID,
+			&message.Subject,
+			&message.Payload,
+			&message.Attempts,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		messages = append(messages, message)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	results := publish(messages)
+
+	var sentIDs, failedIDs []int64
+	var failedErrors []string
+	for i, message := range messages {
+		if i < len(results) && results[i] == nil {
+			sentIDs = append(sentIDs, message.ID)
+			continue
+		}
+
+		errText := "not published"
+		if i < len(results) {
+			errText = results[i].Error()
+		}
+		failedIDs = append(failedIDs, message.ID)
+		failedErrors = append(failedErrors, errText)
+	}
+
+	if len(sentIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE outbox
+			SET sent_at = NOW(), attempts = attempts + 1, last_error = NULL
+			WHERE id = ANY($1)`,
+			sentIDs)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(failedIDs) > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE outbox
+			SET attempts = outbox.attempts + 1,
+				last_error = failed.error,
+				next_attempt_at = NOW() + LEAST(POWER(2, outbox.attempts), $3) * INTERVAL '1 second'
+			FROM unnest($1::bigint[], $2::text[]) AS failed(id, error)
+			WHERE outbox.id = failed.id`,
+			failedIDs, failedErrors, maxOutboxBackoffSeconds)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return len(messages), nil
+}
+
+// DeleteSentOutbox Удаляет отправленные события, отправленные раньше before
+func (r *PostgresRepository) DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM outbox
+		WHERE sent_at IS NOT NULL
+		AND sent_at < $1`,
+		before)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
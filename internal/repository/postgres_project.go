@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"goods-service/internal/models"
+)
+
+func (r *PostgresRepository) CreateProject(ctx context.Context, project *models.Project) error {
+	query := `
+        INSERT INTO projects (name)
+        VALUES ($1)
+        RETURNING id, removed, created_at`
+
+	return r.pool.QueryRow(ctx, query, project.Name).
+		Scan(&project.ID, &project.Removed, &project.CreatedAt)
+}
+
+func (r *PostgresRepository) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	query := `
+        SELECT id, name, removed, created_at
+        FROM projects
+        WHERE id = $1 AND NOT removed`
+
+	var project models.Project
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Removed,
+		&project.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (r *PostgresRepository) ListProjects(ctx context.Context, limit, offset int) ([]models.Project, error) {
+	if limit == 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	query := `
+        SELECT id, name, removed, created_at
+        FROM projects
+        WHERE NOT removed
+        ORDER BY id
+        LIMIT $1 OFFSET $2`
+
+	rows, err := r.pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := make([]models.Project, 0, limit)
+	for rows.Next() {
+		var project models.Project
+		err := rows.Scan(
+			&project.ID,
+			&project.Name,
+			&project.Removed,
+			&project.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (r *PostgresRepository) GetProjectsCount(ctx context.Context) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+        SELECT COUNT(*)
+        FROM projects
+        WHERE NOT removed`,
+	).Scan(&count)
+
+	return count, err
+}
+
+func (r *PostgresRepository) RenameProject(ctx context.Context, project *models.Project) error {
+	query := `
+        UPDATE projects
+        SET name = $1
+        WHERE id = $2 AND NOT removed
+        RETURNING name, removed, created_at`
+
+	err := r.pool.QueryRow(ctx, query, project.Name, project.ID).
+		Scan(&project.Name, &project.Removed, &project.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNotFound
+	}
+
+	return err
+}
+
+// RemoveProject Помечает проект удалённым вместе со всеми его товарами.
+// Возвращает товары, которые были удалены вместе с проектом
+func (r *PostgresRepository) RemoveProject(ctx context.Context, id int) ([]models.Good, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE projects
+		SET removed = true
+		WHERE id = $1 AND NOT removed`,
+		id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, models.ErrNotFound
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE goods
+		SET removed = true, removed_at = NOW(), version = version + 1
+		WHERE project_id = $1 AND NOT removed
+		RETURNING id, project_id, external_key, name, description, priority, removed, removed_at, created_at, version`,
+		id)
+	if err != nil {
+		return nil, err
+	}
+
+	removedGoods, err := collectGoods(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.ClickhouseEvent, 0, len(removedGoods))
+	for i := range removedGoods {
+		previous := removedGoods[i]
+		previous.Removed = false
+		previous.RemovedAt = nil
+		previous.Version--
+		events = append(events, models.NewClickhouseEvent(models.EventGoodDeleted, &removedGoods[i], &previous))
+	}
+	if err = r.enqueueEvents(ctx, tx, events...); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return removedGoods, nil
+}
+
+func (r *PostgresRepository) CheckProjectExists(ctx context.Context, id int) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM projects
+			WHERE id = $1
+			AND NOT removed
+		)`
+
+	err := r.pool.QueryRow(ctx, query, id).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
@@ -7,15 +7,79 @@ import (
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"goods-service/internal/models"
+	"strconv"
 	"time"
 )
 
 const (
-	totalCountKey   = "goods:total_count"
-	removedCountKey = "goods:removed_count"
-	countTTL        = time.Minute
+	totalCountKeyFormat       = "goods:%d:total_count"
+	removedCountKeyFormat     = "goods:%d:removed_count"
+	countsGenerationKeyFormat = "goods:%d:counts_generation"
+	countTTL                  = time.Hour
+	idempotencyKeyFormat      = "idempotency:%s"
 )
 
+// adjustCountsScript Изменяет оба счетчика проекта, только если они уже есть в кэше.
+// Если одного из счетчиков нет, удаляет оба, чтобы при чтении они были загружены заново.
+// Поколение счетчиков KEYS[3] увеличивается в любом случае, чтобы счетчики,
+// посчитанные до изменения, не были сохранены после него
+var adjustCountsScript = redis.NewScript(`
+redis.call("INCR", KEYS[3])
+redis.call("PEXPIRE", KEYS[3], ARGV[3])
+if redis.call("EXISTS", KEYS[1]) == 1 and redis.call("EXISTS", KEYS[2]) == 1 then
+	redis.call("INCRBY", KEYS[1], ARGV[1])
+	redis.call("INCRBY", KEYS[2], ARGV[2])
+	return 1
+end
+redis.call("DEL", KEYS[1], KEYS[2])
+return 0
+`)
+
+// setCountsScript Сохраняет счетчики ARGV[1] и ARGV[2] на ARGV[4] миллисекунд, только если
+// поколение счетчиков KEYS[3] не изменилось с ARGV[3]. Отсутствующее поколение равно 0
+var setCountsScript = redis.NewScript(`
+if (redis.call("GET", KEYS[3]) or "0") ~= ARGV[3] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[4])
+redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[4])
+return 1
+`)
+
+// Скрипты изменения ключа идемпотентности выполняются, только если ключ
+// зарезервирован запросом с токеном ARGV[1], и возвращают 0, если это не так
+const idempotencyOwnerCheck = `
+local stored = redis.call("GET", KEYS[1])
+if not stored then
+	return 0
+end
+local record = cjson.decode(stored)
+if record.owner ~= ARGV[1] then
+	return 0
+end
+`
+
+// saveIdempotencyScript Заменяет запись ARGV[2] со временем жизни ARGV[3] миллисекунд
+var saveIdempotencyScript = redis.NewScript(idempotencyOwnerCheck + `
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// extendIdempotencyScript Продлевает резерв незавершённого запроса на ARGV[2] миллисекунд
+var extendIdempotencyScript = redis.NewScript(idempotencyOwnerCheck + `
+if record.completed then
+	return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1
+`)
+
+// releaseIdempotencyScript Удаляет ключ
+var releaseIdempotencyScript = redis.NewScript(idempotencyOwnerCheck + `
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
 type RedisRepository struct {
 	client *redis.Client
 }
@@ -30,12 +94,12 @@ func (r *RedisRepository) SetGood(ctx context.Context, good *models.Good) error
 		return err
 	}
 
-	key := r.getGoodKey(good.ID, good.ProjectID)
+	key := goodKey(good.ID, good.ProjectID)
 	return r.client.Set(ctx, key, data, time.Minute).Err()
 }
 
 func (r *RedisRepository) GetGood(ctx context.Context, id, projectID int) (*models.Good, error) {
-	key := r.getGoodKey(id, projectID)
+	key := goodKey(id, projectID)
 	data, err := r.client.Get(ctx, key).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
@@ -53,43 +117,192 @@ func (r *RedisRepository) GetGood(ctx context.Context, id, projectID int) (*mode
 }
 
 func (r *RedisRepository) InvalidateGood(ctx context.Context, id, projectID int) error {
-	key := r.getGoodKey(id, projectID)
+	key := goodKey(id, projectID)
 	return r.client.Del(ctx, key).Err()
 }
 
-func (r *RedisRepository) GetTotalCount(ctx context.Context) (int, error) {
-	val, err := r.client.Get(ctx, totalCountKey).Int()
-	if errors.Is(err, redis.Nil) {
-		return 0, errors.New("total count not found in cache")
+// SetGoods Кэширует пачку товаров одним конвейером команд
+func (r *RedisRepository) SetGoods(ctx context.Context, goods []models.Good) error {
+	if len(goods) == 0 {
+		return nil
 	}
-	return val, err
+
+	pipe := r.client.Pipeline()
+	for i := range goods {
+		data, err := json.Marshal(&goods[i])
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, goodKey(goods[i].ID, goods[i].ProjectID), data, time.Minute)
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-func (r *RedisRepository) SetTotalCount(ctx context.Context, count int) error {
-	return r.client.Set(ctx, totalCountKey, count, countTTL).Err()
+// InvalidateGoods Инвалидирует кэш пачки товаров одной командой
+func (r *RedisRepository) InvalidateGoods(ctx context.Context, goods []models.Good) error {
+	if len(goods) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(goods))
+	for i := range goods {
+		keys = append(keys, goodKey(goods[i].ID, goods[i].ProjectID))
+	}
+
+	return r.client.Del(ctx, keys...).Err()
 }
 
-func (r *RedisRepository) GetRemovedCount(ctx context.Context) (int, error) {
-	val, err := r.client.Get(ctx, removedCountKey).Int()
+// GetCounts Возвращает счетчики неудаленных и удаленных записей проекта.
+// found = false, если счетчиков нет в кэше
+func (r *RedisRepository) GetCounts(ctx context.Context, projectID int) (total int, removed int, found bool, err error) {
+	values, err := r.client.MGet(ctx, totalCountKey(projectID), removedCountKey(projectID)).Result()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	counts := make([]int, len(values))
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return 0, 0, false, nil
+		}
+
+		counts[i], err = strconv.Atoi(str)
+		if err != nil {
+			return 0, 0, false, err
+		}
+	}
+
+	return counts[0], counts[1], true, nil
+}
+
+// CountsGeneration Возвращает поколение счетчиков проекта. Его нужно прочитать
+// до подсчета записей и передать в SetCounts
+func (r *RedisRepository) CountsGeneration(ctx context.Context, projectID int) (int64, error) {
+	generation, err := r.client.Get(ctx, countsGenerationKey(projectID)).Int64()
 	if errors.Is(err, redis.Nil) {
-		return 0, errors.New("removed count not found in cache")
+		return 0, nil
 	}
-	return val, err
+
+	return generation, err
 }
 
-func (r *RedisRepository) SetRemovedCount(ctx context.Context, count int) error {
-	return r.client.Set(ctx, removedCountKey, count, countTTL).Err()
+// SetCounts Сохраняет счетчики неудаленных и удаленных записей проекта, если после
+// чтения поколения generation они не изменялись. Иначе счетчики не сохраняются
+func (r *RedisRepository) SetCounts(ctx context.Context, projectID, total, removed int, generation int64) error {
+	keys := []string{totalCountKey(projectID), removedCountKey(projectID), countsGenerationKey(projectID)}
+	return setCountsScript.Run(ctx, r.client, keys, total, removed, generation, countTTL.Milliseconds()).Err()
 }
 
-// InvalidateCounts Инвалидирует кэш счетчиков
-func (r *RedisRepository) InvalidateCounts(ctx context.Context) error {
-	pipe := r.client.Pipeline()
-	pipe.Del(ctx, totalCountKey)
-	pipe.Del(ctx, removedCountKey)
+// AdjustCounts Изменяет счетчики проекта на заданные величины
+func (r *RedisRepository) AdjustCounts(ctx context.Context, projectID, totalDelta, removedDelta int) error {
+	keys := []string{totalCountKey(projectID), removedCountKey(projectID), countsGenerationKey(projectID)}
+	return adjustCountsScript.Run(ctx, r.client, keys, totalDelta, removedDelta, countTTL.Milliseconds()).Err()
+}
+
+// InvalidateCounts Инвалидирует кэш счетчиков проекта
+func (r *RedisRepository) InvalidateCounts(ctx context.Context, projectID int) error {
+	pipe := r.client.TxPipeline()
+	pipe.Incr(ctx, countsGenerationKey(projectID))
+	pipe.Expire(ctx, countsGenerationKey(projectID), countTTL)
+	pipe.Del(ctx, totalCountKey(projectID), removedCountKey(projectID))
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
-func (r *RedisRepository) getGoodKey(id, projectID int) string {
+// ReserveIdempotencyKey Сохраняет record под ключом идемпотентности, если его ещё нет.
+// Возвращает nil, если ключ зарезервирован, иначе запись, сохранённую под ключом
+func (r *RedisRepository) ReserveIdempotencyKey(
+	ctx context.Context,
+	key string,
+	record *models.IdempotencyRecord,
+	ttl time.Duration,
+) (*models.IdempotencyRecord, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ключ может истечь между SETNX и GET, тогда резервирование повторяется
+	for attempt := 0; attempt < 3; attempt++ {
+		reserved, err := r.client.SetNX(ctx, idempotencyKey(key), data, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		stored, err := r.client.Get(ctx, idempotencyKey(key)).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var existing models.IdempotencyRecord
+		if err := json.Unmarshal(stored, &existing); err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	return nil, fmt.Errorf("failed to reserve idempotency key %s", key)
+}
+
+// SaveIdempotencyKey Сохраняет запись под ключом идемпотентности, зарезервированным record.Owner
+func (r *RedisRepository) SaveIdempotencyKey(ctx context.Context, key string, record *models.IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return runIdempotencyScript(ctx, r.client, saveIdempotencyScript, key, record.Owner, data, ttl.Milliseconds())
+}
+
+// ExtendIdempotencyKey Продлевает резерв ключа идемпотентности запросом owner
+func (r *RedisRepository) ExtendIdempotencyKey(ctx context.Context, key, owner string, ttl time.Duration) error {
+	return runIdempotencyScript(ctx, r.client, extendIdempotencyScript, key, owner, ttl.Milliseconds())
+}
+
+// ReleaseIdempotencyKey Удаляет ключ идемпотентности, зарезервированный owner
+func (r *RedisRepository) ReleaseIdempotencyKey(ctx context.Context, key, owner string) error {
+	return runIdempotencyScript(ctx, r.client, releaseIdempotencyScript, key, owner)
+}
+
+// runIdempotencyScript Выполняет скрипт изменения ключа от имени owner
+func runIdempotencyScript(ctx context.Context, client *redis.Client, script *redis.Script, key, owner string, args ...interface{}) error {
+	done, err := script.Run(ctx, client, []string{idempotencyKey(key)}, append([]interface{}{owner}, args...)...).Int()
+	if err != nil {
+		return err
+	}
+	if done == 0 {
+		return models.ErrIdempotencyKeyNotOwned
+	}
+
+	return nil
+}
+
+// goodKey Ключ кэша товара
+func goodKey(id, projectID int) string {
 	return fmt.Sprintf("good:%d:%d", projectID, id)
 }
+
+func totalCountKey(projectID int) string {
+	return fmt.Sprintf(totalCountKeyFormat, projectID)
+}
+
+func removedCountKey(projectID int) string {
+	return fmt.Sprintf(removedCountKeyFormat, projectID)
+}
+
+func countsGenerationKey(projectID int) string {
+	return fmt.Sprintf(countsGenerationKeyFormat, projectID)
+}
+
+func idempotencyKey(key string) string {
+	return fmt.Sprintf(idempotencyKeyFormat, key)
+}
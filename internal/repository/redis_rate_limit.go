@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"goods-service/internal/models"
+	"time"
+)
+
+const rateLimitKeyFormat = "ratelimit:%s"
+
+// takeTokensScript Пополняет бакеты по времени Redis, общему для всех экземпляров,
+// и забирает по токену из каждого, только если токен есть во всех.
+// ARGV - пары ёмкость бакета и период пополнения в миллисекундах.
+// Возвращает признак списания и для каждого бакета остаток, время до появления
+// токена и время до заполнения в миллисекундах
+var takeTokensScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local tokens = {}
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[i * 2 - 1])
+	local period = tonumber(ARGV[i * 2])
+	local bucket = redis.call("HMGET", key, "tokens", "ts")
+	local available = tonumber(bucket[1]) or capacity
+	local updated = tonumber(bucket[2]) or now
+	available = math.min(capacity, available + math.max(0, now - updated) * capacity / period)
+	if available < 1 then
+		allowed = 0
+	end
+	tokens[i] = available
+end
+
+local result = {allowed}
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[i * 2 - 1])
+	local period = tonumber(ARGV[i * 2])
+	local available = tokens[i] - allowed
+	local retry = 0
+	if available < 1 then
+		retry = math.ceil((1 - available) * period / capacity)
+	end
+	local reset = math.ceil((capacity - available) * period / capacity)
+
+	redis.call("HSET", key, "tokens", tostring(available), "ts", now)
+	redis.call("PEXPIRE", key, reset + 1000)
+
+	table.insert(result, math.floor(available))
+	table.insert(result, retry)
+	table.insert(result, reset)
+end
+return result
+`)
+
+// TakeTokens Забирает по токену из каждого бакета, только если токен есть во всех
+func (r *RedisRepository) TakeTokens(ctx context.Context, buckets []models.RateLimitBucket) (bool, []models.RateLimitState, error) {
+	keys := make([]string, 0, len(buckets))
+	args := make([]interface{}, 0, len(buckets)*2)
+	for _, bucket := range buckets {
+		keys = append(keys, rateLimitKey(bucket.Key))
+		args = append(args, bucket.Limit.Requests, bucket.Limit.Period.Milliseconds())
+	}
+
+	values, err := takeTokensScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return false, nil, err
+	}
+	if len(values) != 1+len(buckets)*3 {
+		return false, nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	states := make([]models.RateLimitState, 0, len(buckets))
+	for i := range buckets {
+		state := values[1+i*3:]
+		states = append(states, models.RateLimitState{
+			Remaining:  int(state[0]),
+			RetryAfter: time.Duration(state[1]) * time.Millisecond,
+			Reset:      time.Duration(state[2]) * time.Millisecond,
+		})
+	}
+
+	return values[0] == 1, states, nil
+}
+
+func rateLimitKey(key string) string {
+	return fmt.Sprintf(rateLimitKeyFormat, key)
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"goods-service/internal/models"
+	"testing"
+	"time"
+)
+
+func newTestRedisRepository(t *testing.T) (*RedisRepository, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisRepository(client), mr
+}
+
+func TestRedisRepository_Good(t *testing.T) {
+	repo, mr := newTestRedisRepository(t)
+	ctx := context.Background()
+
+	good := &models.Good{ID: 5, ProjectID: 2, Name: "good", Priority: 3, Version: 4}
+	if err := repo.SetGood(ctx, good); err != nil {
+		t.Fatalf("failed to set good: %v", err)
+	}
+	if !mr.Exists("good:2:5") {
+		t.Fatal("expected key good:2:5")
+	}
+
+	cached, err := repo.GetGood(ctx, 5, 2)
+	if err != nil || cached == nil || cached.Name != "good" || cached.Priority != 3 || cached.Version != 4 {
+		t.Fatalf("unexpected cached good: %+v, err=%v", cached, err)
+	}
+
+	// Запись живёт одну минуту
+	mr.FastForward(time.Minute + time.Second)
+	if cached, err := repo.GetGood(ctx, 5, 2); err != nil || cached != nil {
+		t.Errorf("expected expired good, got %+v, err=%v", cached, err)
+	}
+
+	if err := repo.SetGood(ctx, good); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InvalidateGood(ctx, 5, 2); err != nil {
+		t.Fatalf("failed to invalidate good: %v", err)
+	}
+	if cached, err := repo.GetGood(ctx, 5, 2); err != nil || cached != nil {
+		t.Errorf("expected invalidated good, got %+v, err=%v", cached, err)
+	}
+}
+
+func TestRedisRepository_Goods(t *testing.T) {
+	repo, mr := newTestRedisRepository(t)
+	ctx := context.Background()
+
+	goods := []models.Good{
+		{ID: 1, ProjectID: 1, Name: "first"},
+		{ID: 2, ProjectID: 1, Name: "second"},
+		{ID: 3, ProjectID: 2, Name: "third"},
+	}
+	if err := repo.SetGoods(ctx, goods); err != nil {
+		t.Fatalf("failed to set goods: %v", err)
+	}
+	for _, key := range []string{"good:1:1", "good:1:2", "good:2:3"} {
+		if !mr.Exists(key) {
+			t.Errorf("expected key %s", key)
+		}
+	}
+	if ttl := mr.TTL("good:2:3"); ttl != time.Minute {
+		t.Errorf("expected ttl of one minute, got %v", ttl)
+	}
+
+	if err := repo.InvalidateGoods(ctx, goods[:2]); err != nil {
+		t.Fatalf("failed to invalidate goods: %v", err)
+	}
+	if mr.Exists("good:1:1") || mr.Exists("good:1:2") || !mr.Exists("good:2:3") {
+		t.Errorf("expected only good:2:3 to remain, got keys %v", mr.Keys())
+	}
+
+	if err := repo.InvalidateGoods(ctx, nil); err != nil {
+		t.Errorf("unexpected error for empty batch: %v", err)
+	}
+}
+
+func TestRedisRepository_Counts(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, repo *RedisRepository, mr *miniredis.Miniredis)
+		adjust      [2]int
+		wantFound   bool
+		wantTotal   int
+		wantRemoved int
+	}{
+		{
+			name: "adjusts cached counts",
+			setup: func(t *testing.T, repo *RedisRepository, _ *miniredis.Miniredis) {
+				if err := repo.SetCounts(context.Background(), 1, 10, 2, 0); err != nil {
+					t.Fatal(err)
+				}
+			},
+			adjust:      [2]int{-1, 1},
+			wantFound:   true,
+			wantTotal:   9,
+			wantRemoved: 3,
+		},
+		{
+			name:   "does not create missing counts",
+			setup:  func(*testing.T, *RedisRepository, *miniredis.Miniredis) {},
+			adjust: [2]int{1, 0},
+		},
+		{
+			name: "drops counts if one of them is missing",
+			setup: func(t *testing.T, repo *RedisRepository, mr *miniredis.Miniredis) {
+				if err := repo.SetCounts(context.Background(), 1, 10, 2, 0); err != nil {
+					t.Fatal(err)
+				}
+				mr.Del("goods:1:removed_count")
+			},
+			adjust: [2]int{1, 0},
+		},
+		{
+			name: "expired counts",
+			setup: func(t *testing.T, repo *RedisRepository, mr *miniredis.Miniredis) {
+				if err := repo.SetCounts(context.Background(), 1, 10, 2, 0); err != nil {
+					t.Fatal(err)
+				}
+				mr.FastForward(countTTL + time.Second)
+			},
+			adjust: [2]int{1, 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mr := newTestRedisRepository(t)
+			ctx := context.Background()
+			tc.setup(t, repo, mr)
+
+			if err := repo.AdjustCounts(ctx, 1, tc.adjust[0], tc.adjust[1]); err != nil {
+				t.Fatalf("failed to adjust counts: %v", err)
+			}
+
+			total, removed, found, err := repo.GetCounts(ctx, 1)
+			if err != nil {
+				t.Fatalf("failed to get counts: %v", err)
+			}
+			if found != tc.wantFound || total != tc.wantTotal || removed != tc.wantRemoved {
+				t.Errorf("expected found=%t total=%d removed=%d, got found=%t total=%d removed=%d",
+					tc.wantFound, tc.wantTotal, tc.wantRemoved, found, total, removed)
+			}
+			if !tc.wantFound && (mr.Exists("goods:1:total_count") || mr.Exists("goods:1:removed_count")) {
+				t.Error("expected both counters to be absent")
+			}
+		})
+	}
+}
+
+func TestRedisRepository_CountsGeneration(t *testing.T) {
+	repo, _ := newTestRedisRepository(t)
+	ctx := context.Background()
+
+	generation, err := repo.CountsGeneration(ctx, 1)
+	if err != nil || generation != 0 {
+		t.Fatalf("expected generation 0, got %d, err=%v", generation, err)
+	}
+
+	// Счетчики, посчитанные до изменения записей, не сохраняются
+	if err := repo.AdjustCounts(ctx, 1, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetCounts(ctx, 1, 10, 2, generation); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, found, _ := repo.GetCounts(ctx, 1); found {
+		t.Fatal("expected stale counts to be skipped")
+	}
+
+	if generation, err = repo.CountsGeneration(ctx, 1); err != nil || generation != 1 {
+		t.Fatalf("expected generation 1, got %d, err=%v", generation, err)
+	}
+	if err := repo.SetCounts(ctx, 1, 11, 2, generation); err != nil {
+		t.Fatal(err)
+	}
+	if total, _, found, _ := repo.GetCounts(ctx, 1); !found || total != 11 {
+		t.Fatalf("expected counts to be saved, got found=%t total=%d", found, total)
+	}
+
+	if err := repo.InvalidateCounts(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetCounts(ctx, 1, 11, 2, generation); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, found, _ := repo.GetCounts(ctx, 1); found {
+		t.Fatal("expected counts read before invalidation to be skipped")
+	}
+}
+
+func TestRedisRepository_InvalidateCounts(t *testing.T) {
+	repo, _ := newTestRedisRepository(t)
+	ctx := context.Background()
+
+	if err := repo.SetCounts(ctx, 1, 3, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetCounts(ctx, 2, 5, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InvalidateCounts(ctx, 1); err != nil {
+		t.Fatalf("failed to invalidate counts: %v", err)
+	}
+
+	if _, _, found, _ := repo.GetCounts(ctx, 1); found {
+		t.Error("expected counts of project 1 to be invalidated")
+	}
+	if total, _, found, _ := repo.GetCounts(ctx, 2); !found || total != 5 {
+		t.Error("expected counts of project 2 to be kept")
+	}
+}
+
+func TestRedisRepository_IdempotencyKey(t *testing.T) {
+	repo, mr := newTestRedisRepository(t)
+	ctx := context.Background()
+
+	pending := &models.IdempotencyRecord{RequestHash: "hash", Owner: "owner"}
+	if existing, err := repo.ReserveIdempotencyKey(ctx, "key", pending, time.Minute); err != nil || existing != nil {
+		t.Fatalf("expected key to be reserved, got %+v, err=%v", existing, err)
+	}
+
+	existing, err := repo.ReserveIdempotencyKey(ctx, "key", pending, time.Minute)
+	if err != nil || existing == nil || existing.Completed || existing.RequestHash != "hash" {
+		t.Fatalf("expected pending record, got %+v, err=%v", existing, err)
+	}
+
+	// Резерв продлевается только запросом, который его занял
+	mr.FastForward(50 * time.Second)
+	if err := repo.ExtendIdempotencyKey(ctx, "key", "other", time.Minute); !errors.Is(err, models.ErrIdempotencyKeyNotOwned) {
+		t.Fatalf("expected ErrIdempotencyKeyNotOwned, got %v", err)
+	}
+	if err := repo.ExtendIdempotencyKey(ctx, "key", "owner", time.Minute); err != nil {
+		t.Fatalf("failed to extend key: %v", err)
+	}
+	if ttl := mr.TTL("idempotency:key"); ttl != time.Minute {
+		t.Errorf("expected extended ttl of 1m, got %s", ttl)
+	}
+
+	completed := &models.IdempotencyRecord{
+		RequestHash: "hash",
+		Owner:       "other",
+		Completed:   true,
+		StatusCode:  201,
+		Header:      map[string][]string{"Etag": {`"1"`}},
+		Body:        []byte(`{"id":1}`),
+	}
+	if err := repo.SaveIdempotencyKey(ctx, "key", completed, time.Hour); !errors.Is(err, models.ErrIdempotencyKeyNotOwned) {
+		t.Fatalf("expected ErrIdempotencyKeyNotOwned, got %v", err)
+	}
+	if err := repo.ReleaseIdempotencyKey(ctx, "key", "other"); !errors.Is(err, models.ErrIdempotencyKeyNotOwned) || !mr.Exists("idempotency:key") {
+		t.Fatalf("expected key of another owner to be kept, err=%v", err)
+	}
+
+	completed.Owner = "owner"
+	if err := repo.SaveIdempotencyKey(ctx, "key", completed, time.Hour); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+	existing, err = repo.ReserveIdempotencyKey(ctx, "key", pending, time.Minute)
+	if err != nil || existing == nil || !existing.Completed || existing.StatusCode != 201 || string(existing.Body) != `{"id":1}` {
+		t.Fatalf("expected completed record, got %+v, err=%v", existing, err)
+	}
+
+	// Сохранённый ответ не продлевается и не заменяется резервом
+	if err := repo.ExtendIdempotencyKey(ctx, "key", "owner", time.Minute); !errors.Is(err, models.ErrIdempotencyKeyNotOwned) {
+		t.Fatalf("expected completed key not to be extended, got %v", err)
+	}
+
+	// Сохранённый ответ живёт ttl
+	mr.FastForward(time.Hour + time.Second)
+	if existing, err := repo.ReserveIdempotencyKey(ctx, "key", pending, time.Minute); err != nil || existing != nil {
+		t.Fatalf("expected expired key to be reserved again, got %+v, err=%v", existing, err)
+	}
+
+	if err := repo.ReleaseIdempotencyKey(ctx, "key", "owner"); err != nil {
+		t.Fatalf("failed to release key: %v", err)
+	}
+	if mr.Exists("idempotency:key") {
+		t.Error("expected released key to be deleted")
+	}
+}
+
+func TestRedisRepository_TakeTokens(t *testing.T) {
+	repo, mr := newTestRedisRepository(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mr.SetTime(now)
+
+	buckets := []models.RateLimitBucket{
+		{Key: "client:a:write", Limit: models.RateLimit{Requests: 3, Period: 3 * time.Second}},
+		{Key: "project:1:write", Limit: models.RateLimit{Requests: 2, Period: 10 * time.Second}},
+	}
+
+	// Токены забираются из обоих бакетов, пока есть в каждом
+	for i := 0; i < 2; i++ {
+		allowed, states, err := repo.TakeTokens(ctx, buckets)
+		if err != nil || !allowed || len(states) != 2 || states[0].Remaining != 2-i || states[1].Remaining != 1-i {
+			t.Fatalf("request %d: unexpected result allowed=%v, states=%+v, err=%v", i, allowed, states, err)
+		}
+	}
+
+	// Бакет проекта пуст, токен клиента не списывается
+	allowed, states, err := repo.TakeTokens(ctx, buckets)
+	if err != nil || allowed {
+		t.Fatalf("expected request to be rejected, err=%v", err)
+	}
+	if states[0].Remaining != 1 || states[1].Remaining != 0 ||
+		states[1].RetryAfter != 5*time.Second || states[1].Reset != 10*time.Second {
+		t.Fatalf("unexpected states %+v", states)
+	}
+	if ttl := mr.TTL("ratelimit:project:1:write"); ttl <= 0 {
+		t.Errorf("expected bucket ttl, got %v", ttl)
+	}
+
+	// Через 5 секунд в бакете проекта появляется токен
+	mr.SetTime(now.Add(5 * time.Second))
+	allowed, states, err = repo.TakeTokens(ctx, buckets)
+	if err != nil || !allowed || states[0].Remaining != 2 || states[1].Remaining != 0 {
+		t.Fatalf("unexpected result allowed=%v, states=%+v, err=%v", allowed, states, err)
+	}
+}
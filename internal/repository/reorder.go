@@ -0,0 +1,132 @@
+package repository
+
+import "goods-service/internal/models"
+
+// reorderGoods Перемещает товар id на позицию newPriority в списке goods,
+// упорядоченном по приоритету, и нумерует список подряд начиная с 1.
+// Позиция за пределами списка означает конец списка.
+// Возвращает изменения только тех товаров, чей приоритет изменился
+func reorderGoods(goods []models.Good, id, newPriority int) ([]models.GoodChange, error) {
+	ordered, err := moveGood(goods, id, newPriority)
+	if err != nil {
+		return nil, err
+	}
+
+	return renumberGoods(ordered), nil
+}
+
+// reprioritizeGood Перемещает товар, как reorderGoods, если он хранится
+// в версии version. Версия 0 не проверяется
+func reprioritizeGood(goods []models.Good, id, newPriority, version int) ([]models.GoodChange, error) {
+	for i := range goods {
+		if goods[i].ID != id {
+			continue
+		}
+		if err := checkVersion(version, goods[i].Version); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	return reorderGoods(goods, id, newPriority)
+}
+
+// applyGoodsReorder Применяет к списку goods, упорядоченному по приоритету,
+// полный новый порядок или перемещения по очереди и нумерует список подряд.
+// Возвращает по одному изменению на каждый товар, чей приоритет изменился
+func applyGoodsReorder(goods []models.Good, reorder models.GoodsReorder) ([]models.GoodChange, error) {
+	if len(reorder.IDs) > 0 {
+		ordered, err := orderGoods(goods, reorder.IDs)
+		if err != nil {
+			return nil, err
+		}
+
+		return renumberGoods(ordered), nil
+	}
+
+	ordered := goods
+	for _, move := range reorder.Moves {
+		var err error
+		if ordered, err = moveGood(ordered, move.ID, move.NewPriority); err != nil {
+			return nil, err
+		}
+	}
+
+	return renumberGoods(ordered), nil
+}
+
+// moveGood Возвращает новый список, в котором товар id стоит на позиции newPriority
+func moveGood(goods []models.Good, id, newPriority int) ([]models.Good, error) {
+	index := -1
+	for i := range goods {
+		if goods[i].ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, models.ErrNotFound
+	}
+
+	if newPriority < 1 {
+		newPriority = 1
+	}
+	if newPriority > len(goods) {
+		newPriority = len(goods)
+	}
+
+	rest := make([]models.Good, 0, len(goods)-1)
+	rest = append(rest, goods[:index]...)
+	rest = append(rest, goods[index+1:]...)
+
+	ordered := make([]models.Good, 0, len(goods))
+	ordered = append(ordered, rest[:newPriority-1]...)
+	ordered = append(ordered, goods[index])
+	ordered = append(ordered, rest[newPriority-1:]...)
+
+	return ordered, nil
+}
+
+// orderGoods Упорядочивает товары по списку ids. Список должен содержать
+// каждый товар ровно один раз
+func orderGoods(goods []models.Good, ids []int) ([]models.Good, error) {
+	if len(ids) != len(goods) {
+		return nil, models.ErrInvalidReorder
+	}
+
+	byID := make(map[int]models.Good, len(goods))
+	for _, good := range goods {
+		byID[good.ID] = good
+	}
+
+	ordered := make([]models.Good, 0, len(ids))
+	for _, id := range ids {
+		good, ok := byID[id]
+		if !ok {
+			return nil, models.ErrInvalidReorder
+		}
+		delete(byID, id)
+		ordered = append(ordered, good)
+	}
+
+	return ordered, nil
+}
+
+// renumberGoods Присваивает товарам приоритеты 1..n в порядке списка.
+// Возвращает изменения только тех товаров, чей приоритет изменился,
+// версия изменившихся товаров увеличивается
+func renumberGoods(ordered []models.Good) []models.GoodChange {
+	changes := make([]models.GoodChange, 0)
+	for i, good := range ordered {
+		if good.Priority == i+1 {
+			continue
+		}
+
+		after := good
+		after.Priority = i + 1
+		after.Version++
+		changes = append(changes, models.GoodChange{Before: good, After: after})
+	}
+
+	return changes
+}
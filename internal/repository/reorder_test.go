@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"errors"
+	"goods-service/internal/models"
+	"testing"
+)
+
+func TestReorderGoods(t *testing.T) {
+	// Товары 1..4 с пропуском приоритета 3 после удаления
+	goods := []models.Good{
+		{ID: 1, Priority: 1},
+		{ID: 2, Priority: 2},
+		{ID: 3, Priority: 4},
+		{ID: 4, Priority: 5},
+	}
+
+	tests := []struct {
+		name        string
+		id          int
+		newPriority int
+		want        map[int]int // Ожидаемые изменения: id -> новый приоритет
+		wantErr     error
+	}{
+		{
+			name:        "move down closes gaps",
+			id:          1,
+			newPriority: 3,
+			want:        map[int]int{2: 1, 3: 2, 1: 3, 4: 4},
+		},
+		{
+			name:        "move up",
+			id:          4,
+			newPriority: 2,
+			want:        map[int]int{4: 2, 2: 3},
+		},
+		{
+			name:        "position beyond the end",
+			id:          2,
+			newPriority: 100,
+			want:        map[int]int{3: 2, 4: 3, 2: 4},
+		},
+		{
+			name:        "same position only closes gaps",
+			id:          1,
+			newPriority: 1,
+			want:        map[int]int{3: 3, 4: 4},
+		},
+		{
+			name:        "unknown good",
+			id:          5,
+			newPriority: 1,
+			wantErr:     models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := reorderGoods(goods, tc.id, tc.newPriority)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+
+			got := make(map[int]int, len(changes))
+			for _, change := range changes {
+				if change.Before.Priority == change.After.Priority {
+					t.Errorf("unchanged good %d returned", change.After.ID)
+				}
+				got[change.After.ID] = change.After.Priority
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected changes %v, got %v", tc.want, got)
+			}
+			for id, priority := range tc.want {
+				if got[id] != priority {
+					t.Errorf("expected changes %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestApplyGoodsReorder(t *testing.T) {
+	goods := []models.Good{
+		{ID: 1, Priority: 1},
+		{ID: 2, Priority: 2},
+		{ID: 3, Priority: 3},
+		{ID: 4, Priority: 4},
+	}
+
+	tests := []struct {
+		name    string
+		reorder models.GoodsReorder
+		want    map[int]int // Ожидаемые изменения: id -> новый приоритет
+		wantErr error
+	}{
+		{
+			name:    "full order",
+			reorder: models.GoodsReorder{IDs: []int{4, 3, 2, 1}},
+			want:    map[int]int{4: 1, 3: 2, 2: 3, 1: 4},
+		},
+		{
+			name:    "full order keeps unchanged goods",
+			reorder: models.GoodsReorder{IDs: []int{2, 1, 3, 4}},
+			want:    map[int]int{2: 1, 1: 2},
+		},
+		{
+			name:    "missing id",
+			reorder: models.GoodsReorder{IDs: []int{1, 2, 3}},
+			wantErr: models.ErrInvalidReorder,
+		},
+		{
+			name:    "duplicate id",
+			reorder: models.GoodsReorder{IDs: []int{1, 2, 3, 3}},
+			wantErr: models.ErrInvalidReorder,
+		},
+		{
+			name:    "unknown id",
+			reorder: models.GoodsReorder{IDs: []int{1, 2, 3, 5}},
+			wantErr: models.ErrInvalidReorder,
+		},
+		{
+			name: "moves are applied in order",
+			reorder: models.GoodsReorder{Moves: []models.GoodMove{
+				{ID: 4, NewPriority: 1},
+				{ID: 1, NewPriority: 4},
+			}},
+			want: map[int]int{4: 1, 1: 4},
+		},
+		{
+			name: "moves cancelling each other",
+			reorder: models.GoodsReorder{Moves: []models.GoodMove{
+				{ID: 1, NewPriority: 3},
+				{ID: 1, NewPriority: 1},
+			}},
+			want: map[int]int{},
+		},
+		{
+			name:    "move of unknown good",
+			reorder: models.GoodsReorder{Moves: []models.GoodMove{{ID: 5, NewPriority: 1}}},
+			wantErr: models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := applyGoodsReorder(goods, tc.reorder)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+
+			got := make(map[int]int, len(changes))
+			for _, change := range changes {
+				if _, ok := got[change.After.ID]; ok {
+					t.Errorf("good %d changed more than once", change.After.ID)
+				}
+				got[change.After.ID] = change.After.Priority
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected changes %v, got %v", tc.want, got)
+			}
+			for id, priority := range tc.want {
+				if got[id] != priority {
+					t.Errorf("expected changes %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"goods-service/internal/models"
+	"sync"
+	"time"
+)
+
+// jwtLeeway Допустимое расхождение часов при проверке exp и nbf токена
+const jwtLeeway = 30 * time.Second
+
+// JWTOptions Параметры проверки JWT. Без ключей токены не принимаются
+type JWTOptions struct {
+	Keys     *JWKS
+	Issuer   string // Ожидаемый iss, пусто - не проверяется
+	Audience string // Ожидаемый aud, пусто - не проверяется
+}
+
+// AuthService Определяет клиента по API ключу или JWT и проекты, к которым
+// у него есть доступ. Доступ к проектам хранится по субъекту клиента,
+// одинаково для API ключей и токенов
+type AuthService struct {
+	store  AuthStore
+	keys   *JWKS
+	parser *jwt.Parser
+
+	cacheTTL time.Duration
+	mu       sync.Mutex
+	cache    map[string]cachedIdentity
+}
+
+// cachedIdentity Клиент, найденный по API ключу или субъекту токена
+type cachedIdentity struct {
+	identity  *models.Identity
+	expiresAt time.Time
+}
+
+// NewAuthService Найденные клиенты хранятся в памяти cacheTTL, поэтому
+// отзыв ключа и изменение доступа к проектам вступают в силу не позже
+// чем через cacheTTL. 0 отключает кэш
+func NewAuthService(store AuthStore, opts JWTOptions, cacheTTL time.Duration) *AuthService {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+
+	return &AuthService{
+		store:    store,
+		keys:     opts.Keys,
+		parser:   jwt.NewParser(parserOpts...),
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]cachedIdentity),
+	}
+}
+
+// HashAPIKey Хэш API ключа, под которым ключ хранится
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuthenticateAPIKey Возвращает клиента по API ключу. Для неизвестного
+// или отозванного ключа возвращает models.ErrUnauthenticated
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (*models.Identity, error) {
+	if key == "" {
+		return nil, models.ErrUnauthenticated
+	}
+
+	hash := HashAPIKey(key)
+	return s.cached(models.AuthMethodAPIKey+":"+hash, func() (*models.Identity, error) {
+		apiKey, err := s.store.GetAPIKey(ctx, hash)
+		if err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, models.ErrUnauthenticated
+			}
+			return nil, err
+		}
+
+		return s.identity(ctx, apiKey.Subject, models.AuthMethodAPIKey)
+	})
+}
+
+// AuthenticateToken Возвращает клиента по JWT, подписанному HS256 или RS256
+// ключом из JWKS. Токен должен содержать sub и exp. Для непрошедшего
+// проверку токена возвращает ошибку, оборачивающую models.ErrUnauthenticated
+func (s *AuthService) AuthenticateToken(ctx context.Context, token string) (*models.Identity, error) {
+	if s.keys == nil {
+		return nil, fmt.Errorf("%w: tokens are not accepted", models.ErrUnauthenticated)
+	}
+
+	var claims jwt.RegisteredClaims
+	if _, err := s.parser.ParseWithClaims(token, &claims, s.keys.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrUnauthenticated, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", models.ErrUnauthenticated)
+	}
+
+	return s.cached(models.AuthMethodJWT+":"+claims.Subject, func() (*models.Identity, error) {
+		return s.identity(ctx, claims.Subject, models.AuthMethodJWT)
+	})
+}
+
+// cached Возвращает клиента из кэша по ключу key или загружает его через load.
+// Кэшируются только найденные клиенты, поэтому неизвестные ключи не занимают память
+func (s *AuthService) cached(key string, load func() (*models.Identity, error)) (*models.Identity, error) {
+	if s.cacheTTL <= 0 {
+		return load()
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	if ok && now.After(entry.expiresAt) {
+		delete(s.cache, key)
+		ok = false
+	}
+	s.mu.Unlock()
+	if ok {
+		return entry.identity, nil
+	}
+
+	identity, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedIdentity{identity: identity, expiresAt: now.Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return identity, nil
+}
+
+// identity Загружает доступ клиента subject к проектам
+func (s *AuthService) identity(ctx context.Context, subject, method string) (*models.Identity, error) {
+	grants, err := s.store.GetProjectGrants(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Identity{
+		Subject: subject,
+		Method:  method,
+		Grants:  grants,
+	}, nil
+}
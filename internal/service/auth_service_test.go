@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var testHMACSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestAuthService Создаёт сервис с ключом billing-key клиента billing
+// и набором из HMAC ключа hs и RSA ключа rs
+func newTestAuthService(t *testing.T) (*AuthService, *rsa.PrivateKey) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks, err := ParseJWKS([]byte(fmt.Sprintf(`{"keys":[
+		{"kty":"oct","kid":"hs","alg":"HS256","k":%q},
+		{"kty":"RSA","kid":"rs","alg":"RS256","n":%q,"e":%q}
+	]}`,
+		base64.RawURLEncoding.EncodeToString(testHMACSecret),
+		base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	)))
+	if err != nil {
+		t.Fatalf("failed to parse JWKS: %v", err)
+	}
+
+	store := repository.NewMemoryAuthStore()
+	store.AddAPIKey(&models.APIKey{Name: "billing", Subject: "billing"}, HashAPIKey("billing-key"))
+	store.GrantProject("billing", models.ProjectGrant{ProjectID: 1, Access: models.AccessWrite})
+	store.GrantProject("billing", models.ProjectGrant{ProjectID: 2, Access: models.AccessRead})
+	revokedAt := time.Now()
+	store.AddAPIKey(&models.APIKey{Name: "old", Subject: "billing", RevokedAt: &revokedAt}, HashAPIKey("revoked-key"))
+
+	return NewAuthService(store, JWTOptions{Keys: jwks, Issuer: "auth", Audience: "goods"}, 0), rsaKey
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "billing",
+		"iss": "auth",
+		"aud": "goods",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func withClaim(name string, value interface{}) jwt.MapClaims {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func TestAuthService_AuthenticateAPIKey(t *testing.T) {
+	s, _ := newTestAuthService(t)
+	ctx := context.Background()
+
+	identity, err := s.AuthenticateAPIKey(ctx, "billing-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Subject != "billing" || identity.Method != models.AuthMethodAPIKey {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if !identity.CanWrite(1) || !identity.CanRead(1) {
+		t.Error("expected write access to project 1")
+	}
+	if identity.CanWrite(2) || !identity.CanRead(2) {
+		t.Error("expected read only access to project 2")
+	}
+	if identity.CanRead(3) || identity.CanRead(models.AllProjects) {
+		t.Error("expected no access to other projects")
+	}
+	if identity.Actor() != "api_key:billing" {
+		t.Errorf("unexpected actor %s", identity.Actor())
+	}
+
+	for _, key := range []string{"", "unknown-key", "revoked-key"} {
+		if _, err := s.AuthenticateAPIKey(ctx, key); !errors.Is(err, models.ErrUnauthenticated) {
+			t.Errorf("key %q: expected ErrUnauthenticated, got %v", key, err)
+		}
+	}
+}
+
+func TestAuthService_AuthenticateToken(t *testing.T) {
+	s, rsaKey := newTestAuthService(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyBytes := rsaKey.N.Bytes()
+
+	tests := []struct {
+		name   string
+		token  string
+		wantOK bool
+	}{
+		{"HS256", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, validClaims()), true},
+		{"RS256", signToken(t, jwt.SigningMethodRS256, "rs", rsaKey, validClaims()), true},
+		{"key selected by algorithm without kid", signToken(t, jwt.SigningMethodRS256, "", rsaKey, validClaims()), true},
+		{"unknown kid", signToken(t, jwt.SigningMethodHS256, "other", testHMACSecret, validClaims()), false},
+		{"wrong secret", signToken(t, jwt.SigningMethodHS256, "hs", []byte("wrong secret wrong secret wrong!"), validClaims()), false},
+		{"wrong RSA key", signToken(t, jwt.SigningMethodRS256, "rs", otherKey, validClaims()), false},
+		{"RSA key used as HMAC secret", signToken(t, jwt.SigningMethodHS256, "rs", publicKeyBytes, validClaims()), false},
+		{"unsupported algorithm", signToken(t, jwt.SigningMethodHS512, "hs", testHMACSecret, validClaims()), false},
+		{"none algorithm", signToken(t, jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType, validClaims()), false},
+		{"expired", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, withClaim("exp", time.Now().Add(-time.Hour).Unix())), false},
+		{"no expiration", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, withClaim("exp", nil)), false},
+		{"not yet valid", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, withClaim("nbf", time.Now().Add(time.Hour).Unix())), false},
+		{"wrong issuer", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, withClaim("iss", "other")), false},
+		{"wrong audience", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, withClaim("aud", "other")), false},
+		{"no subject", signToken(t, jwt.SigningMethodHS256, "hs", testHMACSecret, withClaim("sub", nil)), false},
+		{"malformed", "not.a.token", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := s.AuthenticateToken(context.Background(), tt.token)
+			if !tt.wantOK {
+				if !errors.Is(err, models.ErrUnauthenticated) {
+					t.Fatalf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if identity.Subject != "billing" || identity.Method != models.AuthMethodJWT || !identity.CanWrite(1) {
+				t.Errorf("unexpected identity %+v", identity)
+			}
+		})
+	}
+}
+
+func TestAuthService_TokensWithoutKeys(t *testing.T) {
+	s := NewAuthService(repository.NewMemoryAuthStore(), JWTOptions{}, 0)
+
+	token := signToken(t, jwt.SigningMethodHS256, "", testHMACSecret, validClaims())
+	if _, err := s.AuthenticateToken(context.Background(), token); !errors.Is(err, models.ErrUnauthenticated) {
+		t.Fatalf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+// countingAuthStore Считает обращения к хранилищу клиентов
+type countingAuthStore struct {
+	*repository.MemoryAuthStore
+	calls int
+}
+
+func (s *countingAuthStore) GetAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	s.calls++
+	return s.MemoryAuthStore.GetAPIKey(ctx, keyHash)
+}
+
+func (s *countingAuthStore) GetProjectGrants(ctx context.Context, subject string) ([]models.ProjectGrant, error) {
+	s.calls++
+	return s.MemoryAuthStore.GetProjectGrants(ctx, subject)
+}
+
+func TestAuthService_Cache(t *testing.T) {
+	store := &countingAuthStore{MemoryAuthStore: repository.NewMemoryAuthStore()}
+	store.AddAPIKey(&models.APIKey{Name: "billing", Subject: "billing"}, HashAPIKey("billing-key"))
+	store.GrantProject("billing", models.ProjectGrant{ProjectID: 1, Access: models.AccessRead})
+	s := NewAuthService(store, JWTOptions{}, 50*time.Millisecond)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.AuthenticateAPIKey(ctx, "billing-key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.calls != 2 {
+		t.Fatalf("expected key and grants to be loaded once, got %d calls", store.calls)
+	}
+
+	// Неизвестные ключи не кэшируются
+	for i := 0; i < 2; i++ {
+		if _, err := s.AuthenticateAPIKey(ctx, "unknown-key"); !errors.Is(err, models.ErrUnauthenticated) {
+			t.Fatalf("expected ErrUnauthenticated, got %v", err)
+		}
+	}
+	if store.calls != 4 {
+		t.Fatalf("expected unknown key to be looked up each time, got %d calls", store.calls)
+	}
+
+	// Изменение доступа видно после истечения записи кэша
+	store.GrantProject("billing", models.ProjectGrant{ProjectID: 2, Access: models.AccessWrite})
+	if identity, err := s.AuthenticateAPIKey(ctx, "billing-key"); err != nil || identity.CanWrite(2) {
+		t.Fatalf("expected cached identity, got %+v, err=%v", identity, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if identity, err := s.AuthenticateAPIKey(ctx, "billing-key"); err != nil || !identity.CanWrite(2) {
+		t.Fatalf("expected reloaded identity, got %+v, err=%v", identity, err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"oct key", `{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`, false},
+		{"encryption keys are skipped", `{"keys":[{"kty":"oct","k":"c2VjcmV0"},{"kty":"oct","use":"enc","k":"c2VjcmV0"}]}`, false},
+		{"no keys", `{"keys":[]}`, true},
+		{"only encryption keys", `{"keys":[{"kty":"oct","use":"enc","k":"c2VjcmV0"}]}`, true},
+		{"algorithm does not match key type", `{"keys":[{"kty":"oct","alg":"RS256","k":"c2VjcmV0"}]}`, true},
+		{"unsupported keys are skipped", `{"keys":[{"kty":"oct","k":"c2VjcmV0"},{"kty":"EC","crv":"P-256"},{"kty":"oct","alg":"HS512","k":"c2VjcmV0"}]}`, false},
+		{"only unsupported keys", `{"keys":[{"kty":"EC","crv":"P-256"}]}`, true},
+		{"empty secret", `{"keys":[{"kty":"oct"}]}`, true},
+		{"invalid RSA modulus", `{"keys":[{"kty":"RSA","n":"!","e":"AQAB"}]}`, true},
+		{"invalid JSON", `{"keys":`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWKS([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestIdentity_AllProjects(t *testing.T) {
+	identity := &models.Identity{Grants: []models.ProjectGrant{{ProjectID: models.AllProjects, Access: models.AccessRead}}}
+
+	if !identity.CanRead(5) || !identity.CanRead(models.AllProjects) {
+		t.Error("expected read access to all projects")
+	}
+	if identity.CanWrite(5) {
+		t.Error("expected no write access")
+	}
+}
@@ -2,62 +2,44 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"github.com/nats-io/nats.go"
 	"goods-service/internal/models"
-	"goods-service/internal/repository"
 	"log"
 )
 
 type GoodService struct {
-	postgresRepo   *repository.PostgresRepository
-	redisRepo      *repository.RedisRepository
-	clickhouseRepo *repository.ClickhouseRepository
-	natsConn       *nats.Conn
-}
-
-func NewGoodService(
-	postgresRepo *repository.PostgresRepository,
-	redisRepo *repository.RedisRepository,
-	clickhouseRepo *repository.ClickhouseRepository,
-	natsConn *nats.Conn,
-) *GoodService {
+	goodStore GoodStore
+	cache     GoodCache
+}
+
+func NewGoodService(goodStore GoodStore, cache GoodCache) *GoodService {
 	return &GoodService{
-		postgresRepo:   postgresRepo,
-		redisRepo:      redisRepo,
-		clickhouseRepo: clickhouseRepo,
-		natsConn:       natsConn,
+		goodStore: goodStore,
+		cache:     cache,
 	}
 }
 
 func (s *GoodService) CreateGood(ctx context.Context, good *models.Good) error {
-	if err := s.postgresRepo.CreateGood(ctx, good); err != nil {
+	if err := s.goodStore.CreateGood(ctx, good); err != nil {
 		return err
 	}
 
-	// Инвалидируем кэш счетчиков
-	if err := s.redisRepo.InvalidateCounts(ctx); err != nil {
-		log.Printf("Failed to invalidate counts cache: %v", err)
-	}
+	// Обновляем счетчики
+	s.adjustCounts(ctx, good.ProjectID, 1, 0)
 
 	// Кэшируем новую запись
-	if err := s.redisRepo.SetGood(ctx, good); err != nil {
-		return err
-	}
-
-	// Отправляем событие в NATS для логирования в ClickHouse
-	if err := s.publishEvent("good.created", good); err != nil {
-		return err
+	if err := s.cache.SetGood(ctx, good); err != nil {
+		log.Printf("Failed to cache created good: %v", err)
 	}
 
 	return nil
 }
 
-func (s *GoodService) DeleteGood(ctx context.Context, id, projectID int) error {
+// DeleteGood Отмечает товар удалённым. Если version не 0, товар удаляется,
+// только если хранится в этой версии, иначе возвращается models.ErrVersionMismatch
+func (s *GoodService) DeleteGood(ctx context.Context, id, projectID, version int) error {
 	// Проверяем существование записи
-	exists, err := s.postgresRepo.CheckGoodExists(ctx, id, projectID)
+	exists, err := s.goodStore.CheckGoodExists(ctx, id, projectID)
 	if err != nil {
 		return err
 	}
@@ -65,34 +47,51 @@ func (s *GoodService) DeleteGood(ctx context.Context, id, projectID int) error {
 		return models.ErrNotFound
 	}
 
-	good := models.Good{ID: id, ProjectID: projectID}
+	good := models.Good{ID: id, ProjectID: projectID, Version: version}
 
 	// Отмечаем удалённым
-	if err := s.postgresRepo.MarkAsRemoved(ctx, &good); err != nil {
+	if err := s.goodStore.MarkAsRemoved(ctx, &good); err != nil {
 		return err
 	}
 
-	// Инвалидируем кэш счетчиков
-	if err := s.redisRepo.InvalidateCounts(ctx); err != nil {
-		log.Printf("Failed to invalidate counts cache: %v", err)
-	}
+	// Обновляем счетчики
+	s.adjustCounts(ctx, projectID, -1, 1)
 
 	// Инвалидируем кэш записи
-	if err := s.redisRepo.InvalidateGood(ctx, id, projectID); err != nil {
+	if err := s.cache.InvalidateGood(ctx, id, projectID); err != nil {
 		return err
 	}
 
-	// Отправляем событие в NATS
-	if err := s.publishEvent("good.deleted", &good); err != nil {
-		return err
+	return nil
+}
+
+// RestoreGood восстанавливает удаленную запись. Запись получает
+// наименьший приоритет (максимальное значение) среди неудаленных записей проекта
+func (s *GoodService) RestoreGood(ctx context.Context, id, projectID int) (*models.Good, error) {
+	good := models.Good{ID: id, ProjectID: projectID}
+
+	// Снимаем отметку об удалении
+	if _, err := s.goodStore.RestoreGood(ctx, &good); err != nil {
+		return nil, err
 	}
 
-	return nil
+	// Обновляем счетчики
+	s.adjustCounts(ctx, projectID, 1, -1)
+
+	// Кэшируем восстановленную запись
+	if err := s.cache.SetGood(ctx, &good); err != nil {
+		log.Printf("Failed to cache restored good: %v", err)
+	}
+
+	return &good, nil
 }
 
-func (s *GoodService) UpdateGood(ctx context.Context, good *models.Good) error {
+// UpdateGood Применяет к товару good.ID изменение patch. Поля, не указанные
+// в patch, не меняются. Если good.Version не 0, товар обновляется, только
+// если хранится в этой версии. good заполняется новым состоянием
+func (s *GoodService) UpdateGood(ctx context.Context, good *models.Good, patch models.GoodPatch) error {
 	// Проверяем существование записи
-	exists, err := s.postgresRepo.CheckGoodExists(ctx, good.ID, good.ProjectID)
+	exists, err := s.goodStore.CheckGoodExists(ctx, good.ID, good.ProjectID)
 	if err != nil {
 		return err
 	}
@@ -101,26 +100,135 @@ func (s *GoodService) UpdateGood(ctx context.Context, good *models.Good) error {
 	}
 
 	// Обновляем в PostgreSQL
-	if err := s.postgresRepo.UpdateGood(ctx, good); err != nil {
+	if _, err := s.goodStore.UpdateGood(ctx, good, patch); err != nil {
 		return err
 	}
 
 	// Инвалидируем кэш
-	if err := s.redisRepo.InvalidateGood(ctx, good.ID, good.ProjectID); err != nil {
+	if err := s.cache.InvalidateGood(ctx, good.ID, good.ProjectID); err != nil {
 		return err
 	}
 
-	// Отправляем событие в NATS
-	if err := s.publishEvent("good.updated", good); err != nil {
-		return err
+	return nil
+}
+
+// CreateGoods Создаёт пачку товаров проекта. Приоритеты назначаются в порядке goods.
+// Возвращает ошибку по каждому товару в порядке goods: товары с внешним ключом,
+// который уже есть в проекте, не создаются. Если atomic, пачка применяется только целиком
+func (s *GoodService) CreateGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error) {
+	if len(goods) == 0 {
+		return nil, nil
 	}
 
-	return nil
+	errs, err := s.goodStore.CreateGoods(ctx, projectID, goods, atomic)
+	if err != nil {
+		return errs, err
+	}
+
+	created := succeededGoods(goods, errs)
+	if len(created) == 0 {
+		return errs, nil
+	}
+
+	s.adjustCounts(ctx, projectID, len(created), 0)
+
+	if err := s.cache.SetGoods(ctx, created); err != nil {
+		log.Printf("Failed to cache created goods: %v", err)
+	}
+
+	return errs, nil
+}
+
+// UpdateGoods Применяет к товарам проекта изменения patches[i], как UpdateGood.
+// Возвращает ошибку по каждому товару в порядке goods. Если atomic, пачка
+// применяется только целиком
+func (s *GoodService) UpdateGoods(ctx context.Context, projectID int, goods []models.Good, patches []models.GoodPatch, atomic bool) ([]error, error) {
+	errs, err := s.goodStore.UpdateGoods(ctx, projectID, goods, patches, atomic)
+	if err != nil {
+		return errs, err
+	}
+
+	if err := s.cache.InvalidateGoods(ctx, succeededGoods(goods, errs)); err != nil {
+		log.Printf("Failed to invalidate cache for updated goods: %v", err)
+	}
+
+	return errs, nil
+}
+
+// DeleteGoods Отмечает удалёнными пачку товаров проекта. Ошибки по товарам
+// и признак atomic обрабатываются так же, как в UpdateGoods
+func (s *GoodService) DeleteGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error) {
+	errs, err := s.goodStore.RemoveGoods(ctx, projectID, goods, atomic)
+	if err != nil {
+		return errs, err
+	}
+
+	removed := succeededGoods(goods, errs)
+	if len(removed) > 0 {
+		s.adjustCounts(ctx, projectID, -len(removed), len(removed))
+	}
+
+	if err := s.cache.InvalidateGoods(ctx, removed); err != nil {
+		log.Printf("Failed to invalidate cache for removed goods: %v", err)
+	}
+
+	return errs, nil
+}
+
+// exportPageSize Количество товаров, читаемых из хранилища за один запрос при выгрузке
+const exportPageSize = 500
+
+// ExportGoods Передаёт в fn все неудалённые товары проекта в порядке приоритета.
+// Товары читаются страницами по ключу, поэтому выгрузка не держит их в памяти
+// целиком. Товары, изменённые во время выгрузки, попадают в неё в любом из состояний
+func (s *GoodService) ExportGoods(ctx context.Context, projectID int, fn func(good *models.Good) error) error {
+	filter := models.GoodsFilter{ProjectID: projectID, Keyset: true, Limit: exportPageSize}
+	for {
+		goods, err := s.goodStore.ListGoods(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range goods {
+			if err := fn(&goods[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(goods) < exportPageSize {
+			return nil
+		}
+		filter.After = models.NewGoodsCursor(&goods[len(goods)-1], false)
+	}
+}
+
+// ImportGoods Создаёт или обновляет товары проекта по внешнему ключу.
+// Строки читаются функцией next до io.EOF. Изменения сохраняются, только если
+// ни в одной строке нет ошибок
+func (s *GoodService) ImportGoods(ctx context.Context, projectID int, next func() (*models.ImportRow, error)) (*models.ImportResult, error) {
+	result, err := s.goodStore.ImportGoods(ctx, projectID, next)
+	if err != nil || !result.Applied {
+		return result, err
+	}
+
+	if result.Created > 0 {
+		s.adjustCounts(ctx, projectID, result.Created, 0)
+	}
+
+	updated := make([]models.Good, 0, len(result.UpdatedIDs))
+	for _, id := range result.UpdatedIDs {
+		updated = append(updated, models.Good{ID: id, ProjectID: projectID})
+	}
+	if err := s.cache.InvalidateGoods(ctx, updated); err != nil {
+		log.Printf("Failed to invalidate cache for imported goods: %v", err)
+	}
+
+	return result, nil
 }
 
 func (s *GoodService) GetGood(ctx context.Context, id, projectID int) (*models.Good, error) {
 	// Проверяем существование записи
-	exists, err := s.postgresRepo.CheckGoodExists(ctx, id, projectID)
+	exists, err := s.goodStore.CheckGoodExists(ctx, id, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -128,8 +236,8 @@ func (s *GoodService) GetGood(ctx context.Context, id, projectID int) (*models.G
 		return nil, models.ErrNotFound
 	}
 
-	// Пробуем получить из Redis
-	good, err := s.redisRepo.GetGood(ctx, id, projectID)
+	// Пробуем получить из кэша
+	good, err := s.cache.GetGood(ctx, id, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -137,8 +245,8 @@ func (s *GoodService) GetGood(ctx context.Context, id, projectID int) (*models.G
 		return good, nil
 	}
 
-	// Если нет в Redis, получаем из PostgreSQL
-	good, err = s.postgresRepo.GetGood(ctx, id, projectID)
+	// Если нет в кэше, получаем из хранилища
+	good, err = s.goodStore.GetGood(ctx, id, projectID)
 	if err != nil {
 		return nil, err
 	}
@@ -147,41 +255,76 @@ func (s *GoodService) GetGood(ctx context.Context, id, projectID int) (*models.G
 	}
 
 	// Кэшируем результат
-	if err := s.redisRepo.SetGood(ctx, good); err != nil {
+	if err := s.cache.SetGood(ctx, good); err != nil {
 		return nil, err
 	}
 
 	return good, nil
 }
 
-func (s *GoodService) ListGoods(ctx context.Context, limit, offset int) ([]models.Good, error) {
-	return s.postgresRepo.ListGoods(ctx, limit, offset)
+// ListGoods возвращает страницу товаров. При обходе по курсору дополнительно
+// возвращает курсор следующей страницы, либо nil, если страница последняя
+func (s *GoodService) ListGoods(ctx context.Context, filter models.GoodsFilter) ([]models.Good, *models.GoodsCursor, error) {
+	if !filter.Keyset {
+		goods, err := s.goodStore.ListGoods(ctx, filter)
+		return goods, nil, err
+	}
+
+	if filter.After != nil && (filter.After.ProjectID != filter.ProjectID || filter.After.Desc != filter.SortDesc) {
+		return nil, nil, models.ErrInvalidCursor
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	goods, err := s.goodStore.ListGoods(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(goods) <= limit {
+		return goods, nil, nil
+	}
+
+	goods = goods[:limit]
+	return goods, models.NewGoodsCursor(&goods[limit-1], filter.SortDesc), nil
+}
+
+// CountGoods возвращает количество неудаленных и удаленных записей, попадающих под фильтр.
+// Для фильтра только по проекту используются кэшированные счетчики проекта
+func (s *GoodService) CountGoods(ctx context.Context, filter models.GoodsFilter) (total int, removed int, err error) {
+	if !filter.HasConditions() {
+		return s.GetProjectCounts(ctx, filter.ProjectID)
+	}
+
+	return s.goodStore.CountGoods(ctx, filter)
 }
 
-func (s *GoodService) ReprioritizeGood(ctx context.Context, id, projectID, newPriority int) (*models.PriorityResponse, error) {
+// ReprioritizeGood Перемещает товар на позицию newPriority. Если version не 0,
+// товар перемещается, только если хранится в этой версии
+func (s *GoodService) ReprioritizeGood(ctx context.Context, id, projectID, newPriority, version int) (*models.PriorityResponse, error) {
 	// Обновляем приоритеты
-	updatedPriorities, err := s.postgresRepo.ReprioritizeGoods(ctx, id, projectID, newPriority)
+	updatedPriorities, err := s.goodStore.ReprioritizeGoods(ctx, id, projectID, newPriority, version)
 	if err != nil {
 		return nil, err
 	}
 
 	var priorityItems []models.PriorityItem
 
-	for _, item := range updatedPriorities {
+	for _, change := range updatedPriorities {
+		item := change.After
+
 		// Добавляем в ответ
 		priorityItems = append(priorityItems, models.PriorityItem{
 			ID:       item.ID,
 			Priority: item.Priority,
+			Version:  item.Version,
 		})
 
 		// Инвалидируем кэш для всех затронутых записей
-		if err := s.redisRepo.InvalidateGood(ctx, item.ID, projectID); err != nil {
-			log.Printf("error invalidating Redis cache for good %d: %v", item.ID, err)
-		}
-
-		// Отправляем события в NATS
-		if err := s.publishEvent("good.reprioritized", &item); err != nil {
-			log.Printf("error publishing reprioritize event: %v", err)
+		if err := s.cache.InvalidateGood(ctx, item.ID, projectID); err != nil {
+			log.Printf("error invalidating cache for good %d: %v", item.ID, err)
 		}
 	}
 
@@ -190,64 +333,85 @@ func (s *GoodService) ReprioritizeGood(ctx context.Context, id, projectID, newPr
 	}, nil
 }
 
-// GetTotalCount возвращает общее количество неудаленных записей
-func (s *GoodService) GetTotalCount(ctx context.Context) (int, error) {
-	// Пробуем получить из Redis
-	count, err := s.redisRepo.GetTotalCount(ctx)
-	if err == nil {
-		return count, nil
-	}
-
-	// Если нет в Redis, получаем из PostgreSQL
-	count, err = s.postgresRepo.GetTotalCount(ctx)
+// ReorderGoods Меняет порядок товаров проекта одной операцией и возвращает
+// новые приоритеты изменившихся товаров
+func (s *GoodService) ReorderGoods(ctx context.Context, projectID int, reorder models.GoodsReorder) (*models.PriorityResponse, error) {
+	changes, err := s.goodStore.ReorderGoods(ctx, projectID, reorder)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// Кэшируем результат
-	if err := s.redisRepo.SetTotalCount(ctx, count); err != nil {
-		// Логируем ошибку, но не прерываем выполнение
-		log.Printf("Failed to cache total count: %v", err)
+	priorityItems := make([]models.PriorityItem, 0, len(changes))
+	for _, change := range changes {
+		item := change.After
+
+		priorityItems = append(priorityItems, models.PriorityItem{
+			ID:       item.ID,
+			Priority: item.Priority,
+			Version:  item.Version,
+		})
+
+		if err := s.cache.InvalidateGood(ctx, item.ID, projectID); err != nil {
+			log.Printf("error invalidating cache for good %d: %v", item.ID, err)
+		}
 	}
 
-	return count, nil
+	return &models.PriorityResponse{
+		Priorities: priorityItems,
+	}, nil
 }
 
-// GetRemovedCount возвращает количество удаленных записей
-func (s *GoodService) GetRemovedCount(ctx context.Context) (int, error) {
-	// Пробуем получить из Redis
-	count, err := s.redisRepo.GetRemovedCount(ctx)
-	if err == nil {
-		return count, nil
+// GetProjectCounts возвращает количество неудаленных и удаленных записей проекта
+func (s *GoodService) GetProjectCounts(ctx context.Context, projectID int) (total int, removed int, err error) {
+	// Пробуем получить из кэша
+	total, removed, found, err := s.cache.GetCounts(ctx, projectID)
+	if err != nil {
+		log.Printf("Failed to get counts from cache: %v", err)
+	}
+	if err == nil && found {
+		return total, removed, nil
+	}
+
+	// Поколение читается до подсчета: если записи изменятся во время подсчета,
+	// поколение тоже изменится и устаревшие счетчики не попадут в кэш
+	generation, genErr := s.cache.CountsGeneration(ctx, projectID)
+	if genErr != nil {
+		log.Printf("Failed to get counts generation from cache: %v", genErr)
 	}
 
-	// Если нет в Redis, получаем из PostgreSQL
-	count, err = s.postgresRepo.GetRemovedCount(ctx)
+	// Если нет в кэше, получаем из хранилища
+	total, removed, err = s.goodStore.CountGoods(ctx, models.GoodsFilter{ProjectID: projectID})
 	if err != nil {
-		return 0, err
+		return 0, 0, err
+	}
+	if genErr != nil {
+		return total, removed, nil
 	}
 
 	// Кэшируем результат
-	if err := s.redisRepo.SetRemovedCount(ctx, count); err != nil {
+	if err := s.cache.SetCounts(ctx, projectID, total, removed, generation); err != nil {
 		// Логируем ошибку, но не прерываем выполнение
-		log.Printf("Failed to cache removed count: %v", err)
+		log.Printf("Failed to cache counts: %v", err)
 	}
 
-	return count, nil
+	return total, removed, nil
 }
 
-// publishEvent Публикация события в NATS
-func (s *GoodService) publishEvent(subject string, good *models.Good) error {
-	event := models.NewClickhouseEvent(good)
-
-	bytes, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("error marshaling event: %v", err)
+// adjustCounts Изменяет кэшированные счетчики проекта
+func (s *GoodService) adjustCounts(ctx context.Context, projectID, totalDelta, removedDelta int) {
+	if err := s.cache.AdjustCounts(ctx, projectID, totalDelta, removedDelta); err != nil {
+		log.Printf("Failed to adjust counts cache: %v", err)
 	}
+}
 
-	if err := s.natsConn.Publish(subject, bytes); err != nil {
-		return fmt.Errorf("error publishing to NATS: %v", err)
+// succeededGoods Возвращает товары пачки, операция над которыми выполнена без ошибки
+func succeededGoods(goods []models.Good, errs []error) []models.Good {
+	succeeded := make([]models.Good, 0, len(goods))
+	for i := range goods {
+		if errs[i] == nil {
+			succeeded = append(succeeded, goods[i])
+		}
 	}
 
-	return nil
+	return succeeded
 }
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"sync"
+	"testing"
+)
+
+func newTestGoodService(t *testing.T, names ...string) (*GoodService, *repository.MemoryRepository, *repository.MemoryCache) {
+	t.Helper()
+
+	repo := repository.NewMemoryRepository()
+	cache := repository.NewMemoryCache()
+	s := NewGoodService(repo, cache)
+
+	for _, name := range names {
+		if err := s.CreateGood(context.Background(), &models.Good{ProjectID: 1, Name: name}); err != nil {
+			t.Fatalf("failed to create good: %v", err)
+		}
+	}
+
+	return s, repo, cache
+}
+
+// priorities Возвращает приоритеты неудалённых товаров проекта по id
+func priorities(t *testing.T, s *GoodService, projectID int) map[int]int {
+	t.Helper()
+
+	goods, _, err := s.ListGoods(context.Background(), models.GoodsFilter{ProjectID: projectID, Limit: 1000})
+	if err != nil {
+		t.Fatalf("failed to list goods: %v", err)
+	}
+
+	result := make(map[int]int, len(goods))
+	for _, good := range goods {
+		result[good.ID] = good.Priority
+	}
+	return result
+}
+
+func TestGoodService_CreateGood(t *testing.T) {
+	s, _, cache := newTestGoodService(t, "first", "second")
+	ctx := context.Background()
+
+	if got := priorities(t, s, 1); got[1] != 1 || got[2] != 2 {
+		t.Errorf("expected priorities 1 and 2, got %v", got)
+	}
+
+	cached, err := cache.GetGood(ctx, 2, 1)
+	if err != nil || cached == nil || cached.Name != "second" {
+		t.Errorf("expected created good in cache, got %+v, err=%v", cached, err)
+	}
+
+	err = s.CreateGood(ctx, &models.Good{ProjectID: 100, Name: "orphan"})
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown project, got %v", err)
+	}
+}
+
+func TestGoodService_GetGood(t *testing.T) {
+	s, repo, cache := newTestGoodService(t, "first")
+	ctx := context.Background()
+
+	if err := cache.InvalidateGood(ctx, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	good, err := s.GetGood(ctx, 1, 1)
+	if err != nil || good.Name != "first" {
+		t.Fatalf("unexpected result: %+v, err=%v", good, err)
+	}
+	if cached, _ := cache.GetGood(ctx, 1, 1); cached == nil {
+		t.Error("expected good to be cached after read")
+	}
+
+	if _, err := s.GetGood(ctx, 1, 2); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another project, got %v", err)
+	}
+
+	// Удалённый товар не отдаётся даже из кэша
+	if err := repo.MarkAsRemoved(ctx, &models.Good{ID: 1, ProjectID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetGood(ctx, 1, 1); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for removed good, got %v", err)
+	}
+}
+
+func TestGoodService_DeleteAndRestore(t *testing.T) {
+	s, _, cache := newTestGoodService(t, "first", "second", "third")
+	ctx := context.Background()
+
+	// Загружаем счетчики в кэш, чтобы проверить их изменение
+	if total, removed, err := s.GetProjectCounts(ctx, 1); err != nil || total != 3 || removed != 0 {
+		t.Fatalf("unexpected counts: total=%d, removed=%d, err=%v", total, removed, err)
+	}
+
+	if err := s.DeleteGood(ctx, 1, 1, 0); err != nil {
+		t.Fatalf("failed to delete good: %v", err)
+	}
+	if err := s.DeleteGood(ctx, 1, 1, 0); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on repeated delete, got %v", err)
+	}
+	if cached, _ := cache.GetGood(ctx, 1, 1); cached != nil {
+		t.Error("expected removed good to be evicted from cache")
+	}
+
+	total, removed, found, _ := cache.GetCounts(ctx, 1)
+	if !found || total != 2 || removed != 1 {
+		t.Errorf("unexpected cached counts after delete: total=%d, removed=%d, found=%t", total, removed, found)
+	}
+
+	good, err := s.RestoreGood(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("failed to restore good: %v", err)
+	}
+	if good.Removed || good.Priority != 4 {
+		t.Errorf("expected restored good at the end of the list, got %+v", good)
+	}
+
+	total, removed, found, _ = cache.GetCounts(ctx, 1)
+	if !found || total != 3 || removed != 0 {
+		t.Errorf("unexpected cached counts after restore: total=%d, removed=%d, found=%t", total, removed, found)
+	}
+
+	if _, err := s.RestoreGood(ctx, 2, 1); !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected ErrNotFound when restoring active good, got %v", err)
+	}
+}
+
+func TestGoodService_UpdateGood(t *testing.T) {
+	s, _, cache := newTestGoodService(t, "first")
+	ctx := context.Background()
+
+	description := "desc"
+	good := models.Good{ID: 1, ProjectID: 1}
+	if err := s.UpdateGood(ctx, &good, models.GoodPatch{Description: &description}); err != nil {
+		t.Fatalf("failed to update good: %v", err)
+	}
+	if good.Priority != 1 || good.Name != "first" || good.Description != "desc" {
+		t.Errorf("expected only description to change, got %+v", good)
+	}
+	if cached, _ := cache.GetGood(ctx, 1, 1); cached != nil {
+		t.Error("expected updated good to be evicted from cache")
+	}
+
+	err := s.UpdateGood(ctx, &models.Good{ID: 2, ProjectID: 1}, models.GoodPatch{Description: &description})
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGoodService_ReprioritizeGood(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          int
+		newPriority int
+		want        map[int]int
+		wantErr     error
+	}{
+		{
+			name:        "move last to top",
+			id:          3,
+			newPriority: 1,
+			want:        map[int]int{3: 1, 1: 2, 2: 3},
+		},
+		{
+			name:        "move into the middle",
+			id:          3,
+			newPriority: 2,
+			want:        map[int]int{1: 1, 3: 2, 2: 3},
+		},
+		{
+			name:        "move first to last",
+			id:          1,
+			newPriority: 3,
+			want:        map[int]int{2: 1, 3: 2, 1: 3},
+		},
+		{
+			name:        "unknown good",
+			id:          100,
+			newPriority: 1,
+			wantErr:     models.ErrNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _, cache := newTestGoodService(t, "first", "second", "third")
+			ctx := context.Background()
+
+			resp, err := s.ReprioritizeGood(ctx, tc.id, 1, tc.newPriority, 0)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to reprioritize: %v", err)
+			}
+
+			got := priorities(t, s, 1)
+			for id, priority := range tc.want {
+				if got[id] != priority {
+					t.Errorf("expected good %d to have priority %d, got %v", id, priority, got)
+				}
+			}
+
+			for _, item := range resp.Priorities {
+				if got[item.ID] != item.Priority {
+					t.Errorf("response priority %d of good %d differs from stored %d", item.Priority, item.ID, got[item.ID])
+				}
+				if cached, _ := cache.GetGood(ctx, item.ID, 1); cached != nil {
+					t.Errorf("expected good %d to be evicted from cache", item.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGoodService_Batches(t *testing.T) {
+	s, _, cache := newTestGoodService(t, "existing")
+	ctx := context.Background()
+
+	// Загружаем счетчики в кэш, чтобы проверить их изменение
+	if _, _, err := s.GetProjectCounts(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	goods := []models.Good{{Name: "first"}, {Name: "second"}}
+	if _, err := s.CreateGoods(ctx, 1, goods, true); err != nil {
+		t.Fatalf("failed to create goods: %v", err)
+	}
+	for i, good := range goods {
+		if good.Priority != i+2 {
+			t.Errorf("expected good %d to have priority %d, got %d", good.ID, i+2, good.Priority)
+		}
+		if cached, _ := cache.GetGood(ctx, good.ID, 1); cached == nil {
+			t.Errorf("expected created good %d in cache", good.ID)
+		}
+	}
+
+	updates := []models.Good{{ID: goods[0].ID, ProjectID: 1}, {ID: 100, ProjectID: 1}}
+	renamed := "renamed"
+	patches := []models.GoodPatch{{Name: &renamed}, {Name: &renamed}}
+	errs, err := s.UpdateGoods(ctx, 1, updates, patches, false)
+	if err != nil || errs[0] != nil || !errors.Is(errs[1], models.ErrNotFound) {
+		t.Fatalf("unexpected update results: %v, err=%v", errs, err)
+	}
+	if cached, _ := cache.GetGood(ctx, goods[0].ID, 1); cached != nil {
+		t.Error("expected updated good to be evicted from cache")
+	}
+
+	removals := []models.Good{{ID: goods[1].ID, ProjectID: 1}, {ID: 100, ProjectID: 1}}
+	if _, err := s.DeleteGoods(ctx, 1, removals, true); !errors.Is(err, models.ErrBatchAborted) {
+		t.Fatalf("expected ErrBatchAborted, got %v", err)
+	}
+	if _, err := s.DeleteGoods(ctx, 1, removals[:1], true); err != nil {
+		t.Fatalf("failed to delete goods: %v", err)
+	}
+
+	total, removed, found, _ := cache.GetCounts(ctx, 1)
+	if !found || total != 2 || removed != 1 {
+		t.Errorf("unexpected cached counts: total=%d, removed=%d, found=%t", total, removed, found)
+	}
+}
+
+func TestGoodService_ExportGoods(t *testing.T) {
+	s, repo, _ := newTestGoodService(t)
+	ctx := context.Background()
+
+	// Больше двух страниц выгрузки, последняя неполная
+	goods := make([]models.Good, 2*exportPageSize+1)
+	for i := range goods {
+		goods[i].Name = "good"
+	}
+	if _, err := repo.CreateGoods(ctx, 1, goods, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.MarkAsRemoved(ctx, &models.Good{ID: goods[0].ID, ProjectID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var exported []int
+	err := s.ExportGoods(ctx, 1, func(good *models.Good) error {
+		exported = append(exported, good.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to export goods: %v", err)
+	}
+
+	if len(exported) != len(goods)-1 || exported[0] != goods[1].ID || exported[len(exported)-1] != goods[len(goods)-1].ID {
+		t.Errorf("expected all active goods in priority order, got %d goods", len(exported))
+	}
+	for i := 1; i < len(exported); i++ {
+		if exported[i] <= exported[i-1] {
+			t.Fatalf("goods exported out of order at %d: %d after %d", i, exported[i], exported[i-1])
+		}
+	}
+}
+
+func TestGoodService_ListGoodsKeyset(t *testing.T) {
+	s, _, _ := newTestGoodService(t, "a", "b", "c", "d", "e")
+	ctx := context.Background()
+
+	var ids []int
+	filter := models.GoodsFilter{ProjectID: 1, Keyset: true, Limit: 2}
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("too many pages")
+		}
+
+		goods, next, err := s.ListGoods(ctx, filter)
+		if err != nil {
+			t.Fatalf("failed to list goods: %v", err)
+		}
+		for _, good := range goods {
+			ids = append(ids, good.ID)
+		}
+		if next == nil {
+			break
+		}
+		filter.After = next
+	}
+
+	if len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Errorf("expected all goods in priority order, got %v", ids)
+	}
+
+	filter = models.GoodsFilter{ProjectID: 1, Keyset: true, Limit: 2, After: &models.GoodsCursor{ProjectID: 1, ID: 1, Priority: 1, Desc: true}}
+	if _, _, err := s.ListGoods(ctx, filter); !errors.Is(err, models.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for cursor with another direction, got %v", err)
+	}
+}
+
+func TestGoodService_CountGoods(t *testing.T) {
+	s, repo, cache := newTestGoodService(t, "apple", "banana", "apricot")
+	ctx := context.Background()
+
+	// Без условий используются кэшированные счетчики проекта
+	generation, err := cache.CountsGeneration(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.SetCounts(ctx, 1, 42, 7, generation); err != nil {
+		t.Fatal(err)
+	}
+	if total, removed, err := s.CountGoods(ctx, models.GoodsFilter{ProjectID: 1}); err != nil || total != 42 || removed != 7 {
+		t.Errorf("expected cached counts, got total=%d, removed=%d, err=%v", total, removed, err)
+	}
+
+	if err := repo.MarkAsRemoved(ctx, &models.Good{ID: 3, ProjectID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	total, removed, err := s.CountGoods(ctx, models.GoodsFilter{ProjectID: 1, Name: "ap"})
+	if err != nil || total != 1 || removed != 1 {
+		t.Errorf("expected filtered counts 1/1, got total=%d, removed=%d, err=%v", total, removed, err)
+	}
+}
+
+func TestGoodService_ReprioritizeGoodConcurrently(t *testing.T) {
+	const goodsCount, moves = 10, 100
+
+	names := make([]string, goodsCount)
+	for i := range names {
+		names[i] = "good"
+	}
+	s, _, _ := newTestGoodService(t, names...)
+
+	var wg sync.WaitGroup
+	for i := 0; i < moves; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			id := (i*7)%goodsCount + 1
+			newPriority := (i*3)%goodsCount + 1
+			if _, err := s.ReprioritizeGood(context.Background(), id, 1, newPriority, 0); err != nil {
+				t.Errorf("failed to reprioritize: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Приоритеты должны остаться уникальными и идти подряд
+	seen := make(map[int]bool)
+	for id, priority := range priorities(t, s, 1) {
+		if seen[priority] || priority < 1 || priority > goodsCount {
+			t.Errorf("good %d has duplicate or out of range priority %d", id, priority)
+		}
+		seen[priority] = true
+	}
+}
+
+// blockingCountStore Останавливает подсчет записей, пока не закрыт канал release
+type blockingCountStore struct {
+	*repository.MemoryRepository
+	counted chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingCountStore) CountGoods(ctx context.Context, filter models.GoodsFilter) (int, int, error) {
+	total, removed, err := s.MemoryRepository.CountGoods(ctx, filter)
+	if s.counted != nil {
+		close(s.counted)
+		s.counted = nil
+		<-s.release
+	}
+	return total, removed, err
+}
+
+func TestGoodService_ProjectCountsConcurrentAdjust(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	store := &blockingCountStore{
+		MemoryRepository: repository.NewMemoryRepository(),
+		counted:          make(chan struct{}),
+		release:          make(chan struct{}),
+	}
+	counted := store.counted
+	s := NewGoodService(store, repository.NewRedisRepository(client))
+	ctx := context.Background()
+
+	// Чтение промахивается мимо кэша и считает записи до создания товара
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, _, err := s.GetProjectCounts(ctx, 1); err != nil {
+			t.Error(err)
+		}
+	}()
+	<-counted
+
+	if err := s.CreateGood(ctx, &models.Good{ProjectID: 1, Name: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	close(store.release)
+	<-done
+
+	// Посчитанные до создания счетчики не попадают в кэш
+	if total, _, err := s.GetProjectCounts(ctx, 1); err != nil || total != 1 {
+		t.Fatalf("expected total 1, got %d, err=%v", total, err)
+	}
+	if err := s.CreateGood(ctx, &models.Good{ProjectID: 1, Name: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if total, _, err := s.GetProjectCounts(ctx, 1); err != nil || total != 2 {
+		t.Fatalf("expected total 2, got %d, err=%v", total, err)
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+)
+
+// HistoryService Чтение истории изменений товаров из лога событий в ClickHouse
+type HistoryService struct {
+	eventLog EventLog
+}
+
+func NewHistoryService(eventLog EventLog) *HistoryService {
+	return &HistoryService{eventLog: eventLog}
+}
+
+// GetHistory возвращает страницу событий и общее количество событий, попадающих под фильтр
+func (s *HistoryService) GetHistory(ctx context.Context, filter models.HistoryFilter) ([]models.ClickhouseEvent, int, error) {
+	events, err := s.eventLog.GetHistory(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.eventLog.CountHistory(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
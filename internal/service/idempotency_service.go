@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"goods-service/internal/models"
+	"log"
+	"sync"
+	"time"
+)
+
+// IdempotencyService Защищает изменяющие запросы от повторного выполнения.
+// Запрос с ключом идемпотентности выполняется один раз, повтор получает
+// сохранённый ответ
+type IdempotencyService struct {
+	store   IdempotencyStore
+	ttl     time.Duration
+	lockTTL time.Duration
+}
+
+// NewIdempotencyService Ответы хранятся ttl, ключ выполняющегося запроса
+// блокируется на lockTTL и продлевается через KeepAlive, поэтому после падения
+// экземпляра сервиса ключ освобождается не позже чем через lockTTL
+func NewIdempotencyService(store IdempotencyStore, ttl, lockTTL time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		store:   store,
+		ttl:     ttl,
+		lockTTL: lockTTL,
+	}
+}
+
+// Begin Резервирует ключ для запроса с хэшем requestHash. Если запрос нужно
+// выполнить, возвращает токен резерва owner, который передаётся в Complete,
+// KeepAlive и Release. Если такой запрос уже выполнен, возвращает сохранённый ответ.
+// Для другого запроса с тем же ключом возвращает models.ErrIdempotencyKeyReused,
+// для ещё не завершённого - models.ErrIdempotencyKeyInProgress
+func (s *IdempotencyService) Begin(ctx context.Context, key, requestHash string) (owner string, existing *models.IdempotencyRecord, err error) {
+	record := &models.IdempotencyRecord{RequestHash: requestHash, Owner: uuid.NewString()}
+	existing, err = s.store.ReserveIdempotencyKey(ctx, key, record, s.lockTTL)
+	if err != nil {
+		return "", nil, err
+	}
+	if existing == nil {
+		return record.Owner, nil, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return "", nil, models.ErrIdempotencyKeyReused
+	}
+	if !existing.Completed {
+		return "", nil, models.ErrIdempotencyKeyInProgress
+	}
+
+	return "", existing, nil
+}
+
+// KeepAlive Продлевает резерв ключа, пока выполняется запрос owner, чтобы резерв
+// не истёк и ключ не занял повтор. Продление прекращается вызовом stop
+func (s *IdempotencyService) KeepAlive(key, owner string) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(s.lockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := s.store.ExtendIdempotencyKey(ctx, key, owner, s.lockTTL)
+				if errors.Is(err, models.ErrIdempotencyKeyNotOwned) {
+					return
+				}
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Failed to extend idempotency key: %v", err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+}
+
+// Complete Сохраняет ответ на запрос owner. Если резерв ключа истёк и ключ
+// занят другим запросом, возвращает models.ErrIdempotencyKeyNotOwned
+func (s *IdempotencyService) Complete(ctx context.Context, key, owner string, record *models.IdempotencyRecord) error {
+	record.Owner = owner
+	record.Completed = true
+	return s.store.SaveIdempotencyKey(ctx, key, record, s.ttl)
+}
+
+// Release Снимает резерв ключа запросом owner, запрос с этим ключом можно выполнить заново
+func (s *IdempotencyService) Release(ctx context.Context, key, owner string) error {
+	return s.store.ReleaseIdempotencyKey(ctx, key, owner)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyService_Owner(t *testing.T) {
+	s := NewIdempotencyService(repository.NewMemoryCache(), time.Hour, 20*time.Millisecond)
+	ctx := context.Background()
+
+	first, _, err := s.Begin(ctx, "key", "hash")
+	if err != nil || first == "" {
+		t.Fatalf("expected key to be reserved, got owner %q, err=%v", first, err)
+	}
+
+	// Резерв первого запроса истёк, ключ занимает повтор
+	time.Sleep(30 * time.Millisecond)
+	second, _, err := s.Begin(ctx, "key", "hash")
+	if err != nil || second == "" || second == first {
+		t.Fatalf("expected key to be reserved again, got owner %q, err=%v", second, err)
+	}
+
+	// Завершившийся первый запрос не заменяет и не снимает чужой резерв
+	if err := s.Complete(ctx, "key", first, &models.IdempotencyRecord{RequestHash: "hash", StatusCode: 201}); !errors.Is(err, models.ErrIdempotencyKeyNotOwned) {
+		t.Fatalf("expected ErrIdempotencyKeyNotOwned, got %v", err)
+	}
+	if err := s.Release(ctx, "key", first); !errors.Is(err, models.ErrIdempotencyKeyNotOwned) {
+		t.Fatalf("expected ErrIdempotencyKeyNotOwned, got %v", err)
+	}
+	if _, _, err := s.Begin(ctx, "key", "hash"); !errors.Is(err, models.ErrIdempotencyKeyInProgress) {
+		t.Fatalf("expected second request to keep the key, got %v", err)
+	}
+
+	if err := s.Complete(ctx, "key", second, &models.IdempotencyRecord{RequestHash: "hash", StatusCode: 201}); err != nil {
+		t.Fatalf("failed to complete: %v", err)
+	}
+	if _, record, err := s.Begin(ctx, "key", "hash"); err != nil || record == nil || record.StatusCode != 201 {
+		t.Fatalf("expected saved response, got %+v, err=%v", record, err)
+	}
+}
+
+func TestIdempotencyService_KeepAlive(t *testing.T) {
+	s := NewIdempotencyService(repository.NewMemoryCache(), time.Hour, 30*time.Millisecond)
+	ctx := context.Background()
+
+	owner, _, err := s.Begin(ctx, "key", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Пока запрос выполняется дольше lockTTL, повтор ждёт его завершения
+	stop := s.KeepAlive("key", owner)
+	time.Sleep(100 * time.Millisecond)
+	if _, _, err := s.Begin(ctx, "key", "hash"); !errors.Is(err, models.ErrIdempotencyKeyInProgress) {
+		t.Fatalf("expected key to stay reserved, got %v", err)
+	}
+	stop()
+	stop()
+
+	time.Sleep(50 * time.Millisecond)
+	if next, _, err := s.Begin(ctx, "key", "hash"); err != nil || next == "" {
+		t.Fatalf("expected key to expire after stop, got owner %q, err=%v", next, err)
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+)
+
+// Алгоритмы подписи JWT, которые принимает сервис, и типы ключей JWKS для них
+var jwtKeyTypes = map[string]string{
+	jwt.SigningMethodHS256.Alg(): "oct",
+	jwt.SigningMethodRS256.Alg(): "RSA",
+}
+
+// JWKS Ключи проверки подписи JWT из локально заданного набора JSON Web Key Set.
+// Поддерживаются симметричные ключи (kty oct) для HS256 и открытые ключи RSA для RS256,
+// ключи других типов и алгоритмов пропускаются
+type JWKS struct {
+	keys []jwk
+}
+
+// jwk Ключ набора с разобранным материалом: []byte для oct, *rsa.PublicKey для RSA
+type jwk struct {
+	kid string
+	kty string
+	alg string
+	key interface{}
+}
+
+// LoadJWKS Читает набор ключей из файла path
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
+
+// ParseJWKS Разбирает набор ключей в формате RFC 7517
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			K   string `json:"k"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	jwks := &JWKS{}
+	for i, raw := range set.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+		if raw.Alg != "" {
+			kty, ok := jwtKeyTypes[raw.Alg]
+			if !ok {
+				continue
+			}
+			if kty != raw.Kty {
+				return nil, fmt.Errorf("invalid JWKS key %d: algorithm %q does not match key type %q", i, raw.Alg, raw.Kty)
+			}
+		}
+
+		key := jwk{kid: raw.Kid, kty: raw.Kty, alg: raw.Alg}
+		switch raw.Kty {
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(raw.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("invalid JWKS key %d: invalid k", i)
+			}
+			key.key = secret
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(raw.N)
+			e, errE := base64.RawURLEncoding.DecodeString(raw.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("invalid JWKS key %d: invalid n or e", i)
+			}
+			key.key = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		default:
+			continue
+		}
+
+		jwks.keys = append(jwks.keys, key)
+	}
+
+	if len(jwks.keys) == 0 {
+		return nil, errors.New("invalid JWKS: no signing keys")
+	}
+
+	return jwks, nil
+}
+
+// keyFunc Выбирает ключ проверки подписи токена по kid и алгоритму. Тип ключа
+// должен соответствовать алгоритму, поэтому открытый ключ RSA нельзя
+// использовать как секрет HS256. Без kid ключ подходящего типа должен быть единственным
+func (k *JWKS) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	kty, ok := jwtKeyTypes[alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	var found []jwk
+	for _, key := range k.keys {
+		if key.kty != kty || (key.alg != "" && key.alg != alg) {
+			continue
+		}
+		if kid != "" && key.kid != kid {
+			continue
+		}
+		found = append(found, key)
+	}
+
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("no key for kid %q and algorithm %q", kid, alg)
+	case len(found) > 1:
+		return nil, errors.New("kid is required to select a key")
+	}
+
+	return found[0].key, nil
+}
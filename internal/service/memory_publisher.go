@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+)
+
+// MemoryPublisher Доставляет события напрямую в лог событий без NATS.
+// Используется при запуске с хранилищем в памяти
+type MemoryPublisher struct {
+	eventLogger EventLogger
+}
+
+func NewMemoryPublisher(eventLogger EventLogger) *MemoryPublisher {
+	return &MemoryPublisher{eventLogger: eventLogger}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, messages []models.OutboxMessage) []error {
+	results := make([]error, len(messages))
+	for i, message := range messages {
+		event, err := decodeEvent(message.Subject, message.Payload)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+
+		results[i] = p.eventLogger.LogGoodEvent(ctx, event)
+	}
+
+	return results
+}
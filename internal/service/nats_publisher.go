@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"goods-service/internal/models"
+	"time"
+)
+
+// publishTimeout Время ожидания подтверждения публикации пачки сообщений
+const publishTimeout = 5 * time.Second
+
+// EventPublisher Публикует пачку событий и возвращает результат по каждому
+// сообщению в том же порядке. nil означает, что доставка подтверждена
+type EventPublisher interface {
+	Publish(ctx context.Context, messages []models.OutboxMessage) []error
+}
+
+// CorePublisher Публикация в core NATS. Доставка подтверждается через Flush
+type CorePublisher struct {
+	natsConn *nats.Conn
+}
+
+func NewCorePublisher(natsConn *nats.Conn) *CorePublisher {
+	return &CorePublisher{natsConn: natsConn}
+}
+
+func (p *CorePublisher) Publish(ctx context.Context, messages []models.OutboxMessage) []error {
+	results := make([]error, len(messages))
+	for i, message := range messages {
+		results[i] = p.natsConn.Publish(message.Subject, message.Payload)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	// Flush гарантирует, что сервер получил все опубликованные сообщения
+	if err := p.natsConn.FlushWithContext(ctx); err != nil {
+		for i := range results {
+			if results[i] == nil {
+				results[i] = err
+			}
+		}
+	}
+
+	return results
+}
+
+// JetStreamPublisher Публикация в JetStream. Доставка подтверждается
+// PubAck от сервера, id сообщения outbox используется для дедупликации
+type JetStreamPublisher struct {
+	js jetstream.JetStream
+}
+
+func NewJetStreamPublisher(js jetstream.JetStream) *JetStreamPublisher {
+	return &JetStreamPublisher{js: js}
+}
+
+func (p *JetStreamPublisher) Publish(ctx context.Context, messages []models.OutboxMessage) []error {
+	results := make([]error, len(messages))
+	futures := make([]jetstream.PubAckFuture, len(messages))
+	for i, message := range messages {
+		futures[i], results[i] = p.js.PublishAsync(message.Subject, message.Payload,
+			jetstream.WithMsgID(fmt.Sprintf("outbox-%d", message.ID)))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	for i, future := range futures {
+		if future == nil {
+			continue
+		}
+
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			results[i] = err
+		case <-ctx.Done():
+			results[i] = ctx.Err()
+		}
+	}
+
+	return results
+}
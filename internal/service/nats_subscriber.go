@@ -3,53 +3,402 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"goods-service/internal/models"
-	"goods-service/internal/repository"
 	"log"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// Заголовки сообщений, отправляемых в dead-letter
+const (
+	deadLetterSubjectHeader    = "Goods-Original-Subject"
+	deadLetterErrorHeader      = "Goods-Error"
+	deadLetterDeliveriesHeader = "Goods-Deliveries"
+)
+
+// maxDeliveriesAdvisoryFormat Тема уведомления JetStream о сообщении, доставленном
+// MaxDeliver раз без подтверждения. Параметры - поток и consumer
+const maxDeliveriesAdvisoryFormat = "$JS.EVENT.ADVISORY.CONSUMER.MAX_DELIVERIES.%s.%s"
+
+// errAckWaitExpired Причина переноса в dead-letter сообщения, последняя доставка
+// которого не подтверждена за AckWait
+var errAckWaitExpired = errors.New("ack wait expired on the last delivery")
+
+// maxDeliveriesAdvisory Уведомление JetStream о сообщении, исчерпавшем доставки
+type maxDeliveriesAdvisory struct {
+	Stream     string `json:"stream"`
+	Consumer   string `json:"consumer"`
+	StreamSeq  uint64 `json:"stream_seq"`
+	Deliveries uint64 `json:"deliveries"`
+}
+
+// logEventMargin Запас ожидания записи события сверх времени, за которое хранилище
+// гарантированно возвращает результат
+const logEventMargin = 5 * time.Second
+
+// EventLogger Хранилище, в которое записываются события об изменении товаров
+type EventLogger interface {
+	LogGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error
+}
+
+// EventQueue Хранилище, принимающее события в ограниченную очередь без ожидания записи
+type EventQueue interface {
+	EnqueueGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error
+}
+
+// JetStreamOptions Параметры потребления событий через JetStream
+type JetStreamOptions struct {
+	Stream            string        // Поток для тем good.>
+	Consumer          string        // Имя durable pull consumer
+	MaxDeliver        int           // Максимальное количество доставок сообщения
+	AckWait           time.Duration // Время ожидания подтверждения обработки
+	RetryDelay        time.Duration // Базовая задержка перед повторной доставкой
+	FetchBatch        int           // Количество сообщений, запрашиваемых за раз
+	DeadLetterStream  string        // Поток для сообщений, которые не удалось обработать
+	DeadLetterSubject string        // Тема для сообщений, которые не удалось обработать
+}
+
+// EnsureJetStream Создаёт или обновляет потоки событий и dead-letter
+func EnsureJetStream(ctx context.Context, js jetstream.JetStream, opts JetStreamOptions) error {
+	_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       opts.Stream,
+		Subjects:   []string{"good.>"},
+		Storage:    jetstream.FileStorage,
+		Duplicates: 2 * time.Minute,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating stream %s: %v", opts.Stream, err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     opts.DeadLetterStream,
+		Subjects: []string{opts.DeadLetterSubject},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating stream %s: %v", opts.DeadLetterStream, err)
+	}
+
+	return nil
+}
+
 type NATSSubscriber struct {
-	natsConn       *nats.Conn
-	clickhouseRepo *repository.ClickhouseRepository
+	natsConn    *nats.Conn
+	eventLogger EventLogger
+	logTimeout  time.Duration
+	jetStream   *JetStreamOptions
 }
 
-func NewNATSSubscriber(natsConn *nats.Conn, clickhouseRepo *repository.ClickhouseRepository) *NATSSubscriber {
+// NewNATSSubscriber Создаёт подписчика на события товаров. maxWait - время, за которое
+// eventLogger гарантированно возвращает результат записи, включая все повторные попытки.
+// Если jetStream не задан, используется подписка core NATS без гарантий доставки
+func NewNATSSubscriber(natsConn *nats.Conn, eventLogger EventLogger, maxWait time.Duration, jetStream *JetStreamOptions) *NATSSubscriber {
 	return &NATSSubscriber{
-		natsConn:       natsConn,
-		clickhouseRepo: clickhouseRepo,
+		natsConn:    natsConn,
+		eventLogger: eventLogger,
+		logTimeout:  maxWait + logEventMargin,
+		jetStream:   jetStream,
 	}
 }
 
-func (s *NATSSubscriber) Subscribe() error {
+// Subscribe Запускает обработку событий. В режиме JetStream обработка
+// продолжается до отмены контекста
+func (s *NATSSubscriber) Subscribe(ctx context.Context) error {
+	if s.jetStream != nil {
+		return s.subscribeJetStream(ctx)
+	}
+
 	_, err := s.natsConn.Subscribe("good.*", func(msg *nats.Msg) {
 		log.Printf("recieved NATS message: subject=%s", msg.Subject)
 
-		var good models.Good
-		if err := json.Unmarshal(msg.Data, &good); err != nil {
+		event, err := decodeEvent(msg.Subject, msg.Data)
+		if err != nil {
 			log.Printf("error unmarshalling NATS message: %v", err)
 			return
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		// Обработчик ждёт только места в очереди записи, поэтому при отставании
+		// ClickHouse сообщения копятся в буфере подписки NATS, а не в горутинах
+		if queue, ok := s.eventLogger.(EventQueue); ok {
+			if err := queue.EnqueueGoodEvent(ctx, event); err != nil {
+				log.Printf("error queueing NATS message: %v", err)
+			}
+			return
+		}
 
-		if err := s.clickhouseRepo.LogGoodEvent(ctx, &good); err != nil {
+		if err := s.logEvent(event); err != nil {
 			log.Printf("error logging NATS message: %v", err)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	log.Println("successfully subscribed to NATS topics: good.*")
+
+	return nil
+}
+
+func (s *NATSSubscriber) subscribeJetStream(ctx context.Context) error {
+	// Иначе сообщение будет доставлено повторно, пока его запись ещё не завершена
+	if s.jetStream.AckWait <= s.logTimeout {
+		return fmt.Errorf("ack wait %s must exceed event log timeout %s", s.jetStream.AckWait, s.logTimeout)
+	}
+
+	js, err := jetstream.New(s.natsConn)
+	if err != nil {
+		return err
+	}
+
+	if err := EnsureJetStream(ctx, js, *s.jetStream); err != nil {
+		return err
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, s.jetStream.Stream, jetstream.ConsumerConfig{
+		Durable:       s.jetStream.Consumer,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       s.jetStream.AckWait,
+		MaxDeliver:    s.jetStream.MaxDeliver,
+		FilterSubject: "good.>",
+	})
+	if err != nil {
+		return fmt.Errorf("error creating consumer %s: %v", s.jetStream.Consumer, err)
+	}
+
+	if err := s.subscribeMaxDeliveries(ctx, js); err != nil {
+		return err
+	}
+
+	go s.consume(ctx, js, consumer)
+
+	log.Printf("successfully subscribed to JetStream: stream=%s, consumer=%s",
+		s.jetStream.Stream,
+		s.jetStream.Consumer)
+
+	return nil
+}
+
+// consume Забирает сообщения пачками, пока не отменён контекст
+func (s *NATSSubscriber) consume(ctx context.Context, js jetstream.JetStream, consumer jetstream.Consumer) {
+	for ctx.Err() == nil {
+		batch, err := consumer.Fetch(s.jetStream.FetchBatch, jetstream.FetchMaxWait(time.Second))
+		if err != nil {
+			log.Printf("error fetching JetStream messages: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// Сообщения пачки обрабатываются параллельно, чтобы попасть в одну пачку записи ClickHouse
+		var wg sync.WaitGroup
+		for msg := range batch.Messages() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleJetStreamMessage(ctx, js, msg)
+			}()
+		}
+		wg.Wait()
+
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+			log.Printf("error fetching JetStream messages: %v", err)
+		}
+	}
+}
+
+// handleJetStreamMessage Подтверждает сообщение только после успешной записи
+// в ClickHouse. После исчерпания попыток сообщение уходит в dead-letter
+func (s *NATSSubscriber) handleJetStreamMessage(ctx context.Context, js jetstream.JetStream, msg jetstream.Msg) {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		log.Printf("error reading JetStream message metadata: %v", err)
+		return
+	}
+
+	event, err := decodeEvent(msg.Subject(), msg.Data())
+	if err != nil {
+		// Повторная доставка не поможет, сразу отправляем в dead-letter
+		s.deadLetter(ctx, js, msg, metadata, err)
+		return
+	}
+
+	if err := s.logEvent(event); err != nil {
+		if metadata.NumDelivered >= uint64(s.jetStream.MaxDeliver) {
+			s.deadLetter(ctx, js, msg, metadata, err)
+			return
+		}
+
+		log.Printf("error logging JetStream message, will retry: subject=%s, deliveries=%d, err=%v",
+			msg.Subject(),
+			metadata.NumDelivered,
+			err)
+
+		delay := s.jetStream.RetryDelay * time.Duration(metadata.NumDelivered)
+		if err := msg.NakWithDelay(delay); err != nil {
+			log.Printf("error sending nak: %v", err)
+		}
+		return
+	}
+
+	if err := msg.Ack(); err != nil {
+		log.Printf("error acking JetStream message: %v", err)
+	}
+}
+
+// deadLetter Переносит сообщение в dead-letter и прекращает его доставку.
+// Если dead-letter недоступен, сообщение будет доставлено повторно
+func (s *NATSSubscriber) deadLetter(
+	ctx context.Context,
+	js jetstream.JetStream,
+	msg jetstream.Msg,
+	metadata *jetstream.MsgMetadata,
+	cause error,
+) {
+	err := s.publishDeadLetter(ctx, js, msg.Subject(), msg.Data(), metadata.Sequence.Stream, metadata.NumDelivered, cause)
+	if err != nil {
+		log.Printf("error publishing to dead-letter: %v", err)
+		if err := msg.NakWithDelay(s.jetStream.RetryDelay); err != nil {
+			log.Printf("error sending nak: %v", err)
+		}
+		return
+	}
+
+	if err := msg.Term(); err != nil {
+		log.Printf("error terminating JetStream message: %v", err)
+	}
+
+	log.Printf("moved message to dead-letter: subject=%s, deliveries=%d, err=%v",
+		msg.Subject(),
+		metadata.NumDelivered,
+		cause)
+}
+
+// subscribeMaxDeliveries Переносит в dead-letter сообщения, последняя доставка
+// которых не подтверждена за AckWait, например при остановке сервиса во время
+// обработки. JetStream больше не доставляет такие сообщения, а только отправляет
+// уведомление. Уведомления не сохраняются: отправленные, пока ни один экземпляр
+// сервиса не подписан, теряются вместе с сообщением
+func (s *NATSSubscriber) subscribeMaxDeliveries(ctx context.Context, js jetstream.JetStream) error {
+	subject := fmt.Sprintf(maxDeliveriesAdvisoryFormat, s.jetStream.Stream, s.jetStream.Consumer)
+
+	// Уведомление обрабатывает один из экземпляров сервиса
+	sub, err := s.natsConn.QueueSubscribe(subject, s.jetStream.Consumer, func(msg *nats.Msg) {
+		var advisory maxDeliveriesAdvisory
+		if err := json.Unmarshal(msg.Data, &advisory); err != nil {
+			log.Printf("error unmarshalling JetStream advisory: %v", err)
 			return
 		}
 
-		log.Printf("successfully logged event to ClickHouse: good_id=%d, project_id=%d",
-			good.ID,
-			good.ProjectID)
+		s.deadLetterSequence(ctx, js, advisory)
 	})
+	if err != nil {
+		return fmt.Errorf("error subscribing to %s: %v", subject, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+
+	return nil
+}
+
+// deadLetterSequence Копирует в dead-letter сообщение потока, указанное в уведомлении
+func (s *NATSSubscriber) deadLetterSequence(ctx context.Context, js jetstream.JetStream, advisory maxDeliveriesAdvisory) {
+	getCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
 
+	stream, err := js.Stream(getCtx, s.jetStream.Stream)
 	if err != nil {
+		log.Printf("error reading stream %s: %v", s.jetStream.Stream, err)
+		return
+	}
+	msg, err := stream.GetMsg(getCtx, advisory.StreamSeq)
+	if err != nil {
+		log.Printf("error reading message %d of stream %s: %v", advisory.StreamSeq, s.jetStream.Stream, err)
+		return
+	}
+
+	err = s.publishDeadLetter(ctx, js, msg.Subject, msg.Data, msg.Sequence, advisory.Deliveries, errAckWaitExpired)
+	if err != nil {
+		log.Printf("error publishing to dead-letter: %v", err)
+		return
+	}
+
+	log.Printf("moved message to dead-letter: subject=%s, deliveries=%d, err=%v",
+		msg.Subject,
+		advisory.Deliveries,
+		errAckWaitExpired)
+}
+
+// publishDeadLetter Публикует копию сообщения потока в dead-letter. Идентификатор
+// публикации - номер сообщения в потоке, поэтому повторная копия того же сообщения
+// в пределах окна дубликатов отбрасывается
+func (s *NATSSubscriber) publishDeadLetter(
+	ctx context.Context,
+	js jetstream.JetStream,
+	subject string,
+	data []byte,
+	sequence uint64,
+	deliveries uint64,
+	cause error,
+) error {
+	deadMsg := nats.NewMsg(s.jetStream.DeadLetterSubject)
+	deadMsg.Data = data
+	deadMsg.Header.Set(nats.MsgIdHdr, s.jetStream.Stream+"-"+strconv.FormatUint(sequence, 10))
+	deadMsg.Header.Set(deadLetterSubjectHeader, subject)
+	deadMsg.Header.Set(deadLetterErrorHeader, cause.Error())
+	deadMsg.Header.Set(deadLetterDeliveriesHeader, strconv.FormatUint(deliveries, 10))
+
+	pubCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	_, err := js.PublishMsg(pubCtx, deadMsg)
+	return err
+}
+
+// logEvent Записывает событие в ClickHouse
+func (s *NATSSubscriber) logEvent(event *models.ClickhouseEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.logTimeout)
+	defer cancel()
+
+	if err := s.eventLogger.LogGoodEvent(ctx, event); err != nil {
 		return err
 	}
 
-	log.Println("successfully subscribed to NATS topics: good.*")
+	log.Printf("successfully logged event to ClickHouse: type=%s, good_id=%d, project_id=%d",
+		event.EventType,
+		event.ID,
+		event.ProjectID)
 
 	return nil
 }
+
+// decodeEvent Разбирает событие из сообщения NATS
+func decodeEvent(subject string, data []byte) (*models.ClickhouseEvent, error) {
+	var event models.ClickhouseEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+
+	// Тип события определяется темой сообщения, если не указан в самом событии
+	if event.EventType == "" {
+		event.EventType = subject
+	}
+	if event.EventTime.IsZero() {
+		event.EventTime = time.Now()
+	}
+	// Идентификатор события без него выводится из содержимого сообщения,
+	// чтобы повторные доставки не записывались в лог дважды
+	if event.EventID == "" {
+		event.EventID = uuid.NewSHA1(uuid.NameSpaceOID, data).String()
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"goods-service/internal/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeEventLogger struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	logged   []models.ClickhouseEvent
+}
+
+func (l *fakeEventLogger) LogGoodEvent(_ context.Context, event *models.ClickhouseEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.failures != 0 {
+		if l.failures > 0 {
+			l.failures--
+		}
+		return errors.New("clickhouse unavailable")
+	}
+
+	l.logged = append(l.logged, *event)
+	return nil
+}
+
+// fakeEventQueue Принимает события в очередь, как ClickhouseBatchWriter
+type fakeEventQueue struct {
+	fakeEventLogger
+}
+
+func (q *fakeEventQueue) EnqueueGoodEvent(ctx context.Context, event *models.ClickhouseEvent) error {
+	return q.LogGoodEvent(ctx, event)
+}
+
+func (l *fakeEventLogger) snapshot() (calls int, logged []models.ClickhouseEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.calls, append([]models.ClickhouseEvent(nil), l.logged...)
+}
+
+func runJetStreamServer(t *testing.T) *nats.Conn {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NATS server: %v", err)
+	}
+
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server is not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatalf("failed to connect to NATS: %v", err)
+	}
+	t.Cleanup(nc.Close)
+
+	return nc
+}
+
+func testJetStreamOptions() *JetStreamOptions {
+	return &JetStreamOptions{
+		Stream:            "GOODS",
+		Consumer:          "clickhouse-logger",
+		MaxDeliver:        3,
+		AckWait:           10 * time.Second,
+		RetryDelay:        10 * time.Millisecond,
+		FetchBatch:        10,
+		DeadLetterStream:  "GOODS_DLQ",
+		DeadLetterSubject: "dlq.good",
+	}
+}
+
+func publishTestEvent(t *testing.T, nc *nats.Conn) *models.ClickhouseEvent {
+	t.Helper()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatalf("failed to create JetStream context: %v", err)
+	}
+
+	good := models.Good{ID: 7, ProjectID: 1, Name: "good", Priority: 1}
+	event := models.NewClickhouseEvent(models.EventGoodCreated, &good, nil)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	message := models.OutboxMessage{ID: 1, Subject: models.EventGoodCreated, Payload: payload}
+	results := NewJetStreamPublisher(js).Publish(context.Background(), []models.OutboxMessage{message})
+	if results[0] != nil {
+		t.Fatalf("failed to publish event: %v", results[0])
+	}
+
+	return event
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatal("condition was not met in time")
+}
+
+func TestNATSSubscriber_JetStreamRetriesUntilLogged(t *testing.T) {
+	nc := runJetStreamServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := &fakeEventLogger{failures: 1}
+	opts := testJetStreamOptions()
+	if err := NewNATSSubscriber(nc, logger, 0, opts).Subscribe(ctx); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	event := publishTestEvent(t, nc)
+
+	waitFor(t, func() bool {
+		_, logged := logger.snapshot()
+		return len(logged) == 1
+	})
+
+	calls, logged := logger.snapshot()
+	if calls != 2 {
+		t.Errorf("expected 2 logging attempts, got %d", calls)
+	}
+	if logged[0].EventType != models.EventGoodCreated || logged[0].ID != 7 {
+		t.Errorf("unexpected logged event: %+v", logged[0])
+	}
+	// Повторная доставка сохраняет идентификатор, по которому goods_log отбрасывает дубликаты
+	if logged[0].EventID == "" || logged[0].EventID != event.EventID {
+		t.Errorf("expected event id %q, got %q", event.EventID, logged[0].EventID)
+	}
+
+	// Сообщение подтверждено и больше не ожидает обработки
+	js, _ := jetstream.New(nc)
+	waitFor(t, func() bool {
+		consumer, err := js.Consumer(ctx, opts.Stream, opts.Consumer)
+		if err != nil {
+			return false
+		}
+		info, err := consumer.Info(ctx)
+		return err == nil && info.NumAckPending == 0 && info.NumPending == 0
+	})
+}
+
+func TestNATSSubscriber_JetStreamDeadLettersAfterMaxDeliver(t *testing.T) {
+	nc := runJetStreamServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := &fakeEventLogger{failures: -1}
+	opts := testJetStreamOptions()
+	if err := NewNATSSubscriber(nc, logger, 0, opts).Subscribe(ctx); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	publishTestEvent(t, nc)
+
+	js, _ := jetstream.New(nc)
+	var deadLetter jetstream.RawStreamMsg
+	waitFor(t, func() bool {
+		stream, err := js.Stream(ctx, opts.DeadLetterStream)
+		if err != nil {
+			return false
+		}
+		msg, err := stream.GetLastMsgForSubject(ctx, opts.DeadLetterSubject)
+		if err != nil {
+			return false
+		}
+		deadLetter = *msg
+		return true
+	})
+
+	if calls, _ := logger.snapshot(); calls != opts.MaxDeliver {
+		t.Errorf("expected %d logging attempts, got %d", opts.MaxDeliver, calls)
+	}
+	if got := deadLetter.Header.Get(deadLetterSubjectHeader); got != models.EventGoodCreated {
+		t.Errorf("expected original subject %s, got %s", models.EventGoodCreated, got)
+	}
+	if got := deadLetter.Header.Get(deadLetterDeliveriesHeader); got != "3" {
+		t.Errorf("expected 3 deliveries, got %s", got)
+	}
+}
+
+func TestNATSSubscriber_JetStreamDeadLettersExpiredAckWait(t *testing.T) {
+	nc := runJetStreamServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := testJetStreamOptions()
+	opts.MaxDeliver = 2
+	js, _ := jetstream.New(nc)
+	if err := EnsureJetStream(ctx, js, *opts); err != nil {
+		t.Fatal(err)
+	}
+
+	// Consumer с коротким AckWait вместо подписчика, который не успевает подтвердить сообщение
+	consumer, err := js.CreateOrUpdateConsumer(ctx, opts.Stream, jetstream.ConsumerConfig{
+		Durable:    opts.Consumer,
+		AckPolicy:  jetstream.AckExplicitPolicy,
+		AckWait:    100 * time.Millisecond,
+		MaxDeliver: opts.MaxDeliver,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscriber := NewNATSSubscriber(nc, &fakeEventLogger{}, 0, opts)
+	if err := subscriber.subscribeMaxDeliveries(ctx, js); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	publishTestEvent(t, nc)
+
+	// Последний запрос не получает сообщений: на нём JetStream обнаруживает
+	// исчерпанные доставки и отправляет уведомление
+	for i := 0; i <= opts.MaxDeliver; i++ {
+		batch, err := consumer.Fetch(1, jetstream.FetchMaxWait(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range batch.Messages() {
+		}
+	}
+
+	var deadLetter jetstream.RawStreamMsg
+	waitFor(t, func() bool {
+		stream, err := js.Stream(ctx, opts.DeadLetterStream)
+		if err != nil {
+			return false
+		}
+		msg, err := stream.GetLastMsgForSubject(ctx, opts.DeadLetterSubject)
+		if err != nil {
+			return false
+		}
+		deadLetter = *msg
+		return true
+	})
+
+	if got := deadLetter.Header.Get(deadLetterSubjectHeader); got != models.EventGoodCreated {
+		t.Errorf("expected original subject %s, got %s", models.EventGoodCreated, got)
+	}
+	if got := deadLetter.Header.Get(deadLetterDeliveriesHeader); got != "2" {
+		t.Errorf("expected 2 deliveries, got %s", got)
+	}
+	if got := deadLetter.Header.Get(deadLetterErrorHeader); got != errAckWaitExpired.Error() {
+		t.Errorf("expected error %q, got %q", errAckWaitExpired, got)
+	}
+}
+
+func TestNATSSubscriber_JetStreamRejectsShortAckWait(t *testing.T) {
+	nc := runJetStreamServer(t)
+
+	opts := testJetStreamOptions()
+	opts.AckWait = 30 * time.Second
+	if err := NewNATSSubscriber(nc, &fakeEventLogger{}, 40*time.Second, opts).Subscribe(context.Background()); err == nil {
+		t.Fatal("expected error for ack wait shorter than event log timeout")
+	}
+}
+
+func TestNATSSubscriber_CoreQueuesEvents(t *testing.T) {
+	nc := runJetStreamServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := &fakeEventQueue{}
+	if err := NewNATSSubscriber(nc, queue, 0, nil).Subscribe(ctx); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+
+	payload := []byte(`{"Id":7,"ProjectId":1,"Name":"good"}`)
+	for i := 0; i < 2; i++ {
+		if err := nc.Publish(models.EventGoodCreated, payload); err != nil {
+			t.Fatalf("failed to publish event: %v", err)
+		}
+	}
+
+	waitFor(t, func() bool {
+		_, logged := queue.snapshot()
+		return len(logged) == 2
+	})
+
+	// Событие без идентификатора получает его из содержимого сообщения
+	_, logged := queue.snapshot()
+	if logged[0].EventType != models.EventGoodCreated || logged[0].EventID == "" || logged[0].EventID != logged[1].EventID {
+		t.Errorf("unexpected queued events: %+v", logged)
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"log"
+	"time"
+)
+
+// OutboxRelay Отправляет события из outbox в NATS. Событие помечается
+// отправленным только после подтверждения доставки публикатором,
+// недоставленные события отправляются повторно
+type OutboxRelay struct {
+	outboxStore OutboxStore
+	publisher   EventPublisher
+	batchSize   int
+	retention   time.Duration
+}
+
+func NewOutboxRelay(
+	outboxStore OutboxStore,
+	publisher EventPublisher,
+	batchSize int,
+	retention time.Duration,
+) *OutboxRelay {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	return &OutboxRelay{
+		outboxStore: outboxStore,
+		publisher:   publisher,
+		batchSize:   batchSize,
+		retention:   retention,
+	}
+}
+
+// Run Отправляет события с заданным интервалом опроса до отмены контекста
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer cleanupTicker.Stop()
+
+	for {
+		// Пока пачки заполнены целиком, продолжаем без ожидания
+		for {
+			processed, err := r.outboxStore.ProcessOutbox(ctx, r.batchSize, func(messages []models.OutboxMessage) []error {
+				return r.publisher.Publish(ctx, messages)
+			})
+			if err != nil {
+				log.Printf("error processing outbox: %v", err)
+				break
+			}
+			if processed < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanupTicker.C:
+			r.cleanup(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanup Удаляет отправленные события старше срока хранения
+func (r *OutboxRelay) cleanup(ctx context.Context) {
+	deleted, err := r.outboxStore.DeleteSentOutbox(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		log.Printf("error cleaning up outbox: %v", err)
+		return
+	}
+
+	if deleted > 0 {
+		log.Printf("outbox cleanup: deleted %d sent events", deleted)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"log"
+)
+
+type ProjectService struct {
+	projectStore ProjectStore
+	cache        GoodCache
+}
+
+func NewProjectService(projectStore ProjectStore, cache GoodCache) *ProjectService {
+	return &ProjectService{
+		projectStore: projectStore,
+		cache:        cache,
+	}
+}
+
+func (s *ProjectService) CreateProject(ctx context.Context, project *models.Project) error {
+	return s.projectStore.CreateProject(ctx, project)
+}
+
+func (s *ProjectService) GetProject(ctx context.Context, id int) (*models.Project, error) {
+	return s.projectStore.GetProject(ctx, id)
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context, limit, offset int) ([]models.Project, error) {
+	return s.projectStore.ListProjects(ctx, limit, offset)
+}
+
+// GetProjectsCount возвращает количество неудалённых проектов
+func (s *ProjectService) GetProjectsCount(ctx context.Context) (int, error) {
+	return s.projectStore.GetProjectsCount(ctx)
+}
+
+func (s *ProjectService) RenameProject(ctx context.Context, project *models.Project) error {
+	return s.projectStore.RenameProject(ctx, project)
+}
+
+// DeleteProject Помечает проект удалённым. Все неудалённые товары проекта
+// также помечаются удалёнными, по каждому из них в outbox записывается событие good.deleted.
+// Возвращает количество удалённых вместе с проектом товаров
+func (s *ProjectService) DeleteProject(ctx context.Context, id int) (int, error) {
+	removedGoods, err := s.projectStore.RemoveProject(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(removedGoods) == 0 {
+		return 0, nil
+	}
+
+	// Переносим товары проекта в счетчик удаленных
+	if err := s.cache.AdjustCounts(ctx, id, -len(removedGoods), len(removedGoods)); err != nil {
+		log.Printf("Failed to adjust counts cache: %v", err)
+	}
+
+	for _, good := range removedGoods {
+		// Инвалидируем кэш записи
+		if err := s.cache.InvalidateGood(ctx, good.ID, good.ProjectID); err != nil {
+			log.Printf("error invalidating cache for good %d: %v", good.ID, err)
+		}
+	}
+
+	return len(removedGoods), nil
+}
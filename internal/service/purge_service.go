@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"log"
+	"time"
+)
+
+// PurgeService Физически удаляет товары, помеченные удалёнными дольше срока хранения
+type PurgeService struct {
+	goodStore GoodStore
+	cache     GoodCache
+	retention time.Duration
+	batchSize int
+	dryRun    bool
+}
+
+func NewPurgeService(
+	goodStore GoodStore,
+	cache GoodCache,
+	retention time.Duration,
+	batchSize int,
+	dryRun bool,
+) *PurgeService {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	return &PurgeService{
+		goodStore: goodStore,
+		cache:     cache,
+		retention: retention,
+		batchSize: batchSize,
+		dryRun:    dryRun,
+	}
+}
+
+// Run Запускает очистку с заданным интервалом до отмены контекста
+func (s *PurgeService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := s.Purge(ctx)
+		if err != nil {
+			log.Printf("error purging removed goods: %v", err)
+		} else if report.Purged > 0 {
+			log.Printf("purge finished: dry_run=%t, removed_before=%s, purged=%d, by_project=%v",
+				report.DryRun,
+				report.RemovedBefore.Format(time.RFC3339),
+				report.Purged,
+				report.ByProject)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge Выполняет один проход очистки пачками. В режиме dry-run ничего
+// не удаляет и только сообщает, какие товары были бы удалены
+func (s *PurgeService) Purge(ctx context.Context) (*models.PurgeReport, error) {
+	report := &models.PurgeReport{
+		DryRun:        s.dryRun,
+		RemovedBefore: time.Now().Add(-s.retention),
+		ByProject:     make(map[int]int),
+	}
+
+	if s.dryRun {
+		return report, s.collectPurgeable(ctx, report)
+	}
+
+	for {
+		goods, err := s.goodStore.PurgeRemovedGoods(ctx, report.RemovedBefore, s.batchSize)
+		if err != nil {
+			return report, err
+		}
+
+		purgedByProject := make(map[int]int)
+		for _, good := range goods {
+			purgedByProject[good.ProjectID]++
+		}
+
+		// Уменьшаем счетчики удаленных записей
+		for projectID, count := range purgedByProject {
+			if err := s.cache.AdjustCounts(ctx, projectID, 0, -count); err != nil {
+				log.Printf("Failed to adjust counts cache: %v", err)
+			}
+			report.ByProject[projectID] += count
+		}
+		report.Purged += len(goods)
+
+		if len(goods) < s.batchSize {
+			return report, nil
+		}
+	}
+}
+
+// collectPurgeable Собирает отчёт о товарах, подлежащих удалению
+func (s *PurgeService) collectPurgeable(ctx context.Context, report *models.PurgeReport) error {
+	afterID := 0
+	for {
+		goods, err := s.goodStore.ListPurgeableGoods(ctx, report.RemovedBefore, afterID, s.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, good := range goods {
+			log.Printf("purge dry run: would delete good_id=%d, project_id=%d, removed_at=%s",
+				good.ID,
+				good.ProjectID,
+				good.RemovedAt.Format(time.RFC3339))
+			report.ByProject[good.ProjectID]++
+		}
+		report.Purged += len(goods)
+
+		if len(goods) < s.batchSize {
+			return nil
+		}
+		afterID = goods[len(goods)-1].ID
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"goods-service/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimits Лимиты запросов клиента и проекта, отдельные для чтения и изменения.
+// Маршрут из Routes расходует собственные бакеты вместо общих. Address ограничивает
+// все запросы с одного адреса до аутентификации клиента
+type RateLimits struct {
+	Address      models.RateLimit
+	ClientRead   models.RateLimit
+	ClientWrite  models.RateLimit
+	ProjectRead  models.RateLimit
+	ProjectWrite models.RateLimit
+	Routes       map[string]RouteRateLimit // Ключ - метод и шаблон пути, например "POST /api/v1/good/create"
+}
+
+// RouteRateLimit Лимиты запросов к маршруту одного клиента и одного проекта
+type RouteRateLimit struct {
+	Client  models.RateLimit
+	Project models.RateLimit
+}
+
+// RateLimitService Ограничивает частоту запросов клиентов к проектам. Бакеты
+// хранятся в RateLimitStore, поэтому лимиты общие для всех экземпляров сервиса
+type RateLimitService struct {
+	store  RateLimitStore
+	limits RateLimits
+}
+
+func NewRateLimitService(store RateLimitStore, limits RateLimits) *RateLimitService {
+	return &RateLimitService{
+		store:  store,
+		limits: limits,
+	}
+}
+
+// AllowAddress Забирает токен запроса с адреса address. Возвращает nil,
+// если лимит адреса не задан
+func (s *RateLimitService) AllowAddress(ctx context.Context, address string) (*models.RateLimitResult, error) {
+	if s.limits.Address.Unlimited() {
+		return nil, nil
+	}
+
+	buckets := []models.RateLimitBucket{{Key: "address:" + address, Limit: s.limits.Address}}
+	allowed, states, err := s.store.TakeTokens(ctx, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	return rateLimitResult(allowed, buckets, states), nil
+}
+
+// Allow Забирает токены запроса клиента client к маршруту route проекта projectId.
+// Запросы без проекта (models.AllProjects) ограничиваются только по клиенту.
+// Возвращает nil, если для запроса лимиты не заданы
+func (s *RateLimitService) Allow(ctx context.Context, client, route string, projectId int, write bool) (*models.RateLimitResult, error) {
+	var buckets []models.RateLimitBucket
+	addBucket := func(key string, limit models.RateLimit) {
+		if !limit.Unlimited() {
+			buckets = append(buckets, models.RateLimitBucket{Key: key, Limit: limit})
+		}
+	}
+
+	clientKey := "client:" + client
+	projectKey := "project:" + strconv.Itoa(projectId)
+	hasProject := projectId != models.AllProjects
+
+	if routeLimit, ok := s.limits.Routes[route]; ok {
+		addBucket(clientKey+":route:"+route, routeLimit.Client)
+		if hasProject {
+			addBucket(projectKey+":route:"+route, routeLimit.Project)
+		}
+	} else if write {
+		addBucket(clientKey+":write", s.limits.ClientWrite)
+		if hasProject {
+			addBucket(projectKey+":write", s.limits.ProjectWrite)
+		}
+	} else {
+		addBucket(clientKey+":read", s.limits.ClientRead)
+		if hasProject {
+			addBucket(projectKey+":read", s.limits.ProjectRead)
+		}
+	}
+
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+
+	allowed, states, err := s.store.TakeTokens(ctx, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	return rateLimitResult(allowed, buckets, states), nil
+}
+
+// rateLimitResult Сводит состояние бакетов к результату по самому строгому из них:
+// при отказе - по бакету, токен в котором появится позже всех,
+// иначе - по бакету с наименьшим остатком
+func rateLimitResult(allowed bool, buckets []models.RateLimitBucket, states []models.RateLimitState) *models.RateLimitResult {
+	strictest := 0
+	for i := 1; i < len(states); i++ {
+		if allowed && states[i].Remaining < states[strictest].Remaining ||
+			!allowed && states[i].RetryAfter > states[strictest].RetryAfter {
+			strictest = i
+		}
+	}
+
+	return &models.RateLimitResult{
+		Allowed:    allowed,
+		Limit:      buckets[strictest].Limit.Requests,
+		Remaining:  states[strictest].Remaining,
+		RetryAfter: states[strictest].RetryAfter,
+		Reset:      states[strictest].Reset,
+	}
+}
+
+// ParseRateLimit Разбирает лимит вида <запросов>/<период>, например 100/1s или 600/1m.
+// Пустая строка и 0 - без ограничения
+func ParseRateLimit(value string) (models.RateLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return models.RateLimit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return models.RateLimit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<period>", value)
+	}
+
+	var limit models.RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || limit.Requests <= 0 {
+		return models.RateLimit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", value)
+	}
+	if limit.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || limit.Period < time.Millisecond {
+		return models.RateLimit{}, fmt.Errorf("invalid rate limit %q: invalid period", value)
+	}
+
+	return limit, nil
+}
+
+// ParseRouteRateLimits Разбирает лимиты маршрутов вида
+// <метод> <путь>=<лимит клиента>[,<лимит проекта>], например
+// "POST /api/v1/goods/import=1/1m,5/1m". Без лимита проекта запросы
+// к маршруту ограничиваются только по клиенту
+func ParseRouteRateLimits(values []string) (map[string]RouteRateLimit, error) {
+	routes := make(map[string]RouteRateLimit, len(values))
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		route, limits, ok := strings.Cut(value, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !hasPath || method == "" || !strings.HasPrefix(strings.TrimSpace(path), "/") {
+			return nil, fmt.Errorf("invalid route rate limit %q: expected <method> <path>=<client limit>[,<project limit>]", value)
+		}
+
+		clientLimit, projectLimit, _ := strings.Cut(limits, ",")
+		var routeLimit RouteRateLimit
+		var err error
+		if routeLimit.Client, err = ParseRateLimit(clientLimit); err != nil {
+			return nil, err
+		}
+		if routeLimit.Project, err = ParseRateLimit(projectLimit); err != nil {
+			return nil, err
+		}
+
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = routeLimit
+	}
+
+	return routes, nil
+}
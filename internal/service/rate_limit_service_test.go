@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestRateLimitService_Allow(t *testing.T) {
+	s := NewRateLimitService(repository.NewMemoryCache(), RateLimits{
+		ClientRead:   models.RateLimit{Requests: 5, Period: time.Minute},
+		ClientWrite:  models.RateLimit{Requests: 3, Period: time.Minute},
+		ProjectWrite: models.RateLimit{Requests: 2, Period: time.Minute},
+		Routes: map[string]RouteRateLimit{
+			"POST /import": {Client: models.RateLimit{Requests: 1, Period: time.Minute}},
+		},
+	})
+	ctx := context.Background()
+
+	allow := func(client, route string, projectId int, write bool) *models.RateLimitResult {
+		t.Helper()
+
+		result, err := s.Allow(ctx, client, route, projectId, write)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// Результат по самому строгому бакету - бакету проекта
+	if result := allow("a", "POST /create", 1, true); !result.Allowed || result.Limit != 2 || result.Remaining != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	allow("b", "POST /create", 1, true)
+
+	// Проект исчерпал лимит изменений для всех клиентов
+	result := allow("a", "POST /create", 1, true)
+	if result.Allowed || result.Limit != 2 || result.Remaining != 0 ||
+		result.RetryAfter <= 29*time.Second || result.RetryAfter > 30*time.Second {
+		t.Fatalf("expected project limit, got %+v", result)
+	}
+
+	// Другой проект и чтение расходуют свои бакеты
+	if result := allow("a", "POST /create", 2, true); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("expected other project to be allowed, got %+v", result)
+	}
+	if result := allow("a", "GET /list", 1, false); !result.Allowed || result.Limit != 5 || result.Remaining != 4 {
+		t.Fatalf("expected read to be allowed, got %+v", result)
+	}
+
+	// Маршрут с собственным лимитом не ограничен по проекту
+	if result := allow("a", "POST /import", 1, true); !result.Allowed || result.Limit != 1 || result.Remaining != 0 {
+		t.Fatalf("unexpected route result %+v", result)
+	}
+	if result := allow("a", "POST /import", 1, true); result.Allowed {
+		t.Fatalf("expected route limit, got %+v", result)
+	}
+
+	// Без лимита чтения проекта список проектов ограничен только по клиенту
+	if result := allow("c", "GET /projects", models.AllProjects, false); !result.Allowed || result.Limit != 5 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	unlimited := NewRateLimitService(repository.NewMemoryCache(), RateLimits{})
+	if result, err := unlimited.Allow(ctx, "a", "GET /list", 1, false); err != nil || result != nil {
+		t.Fatalf("expected no limits, got %+v, err=%v", result, err)
+	}
+}
+
+func TestRateLimitService_AllowAddress(t *testing.T) {
+	s := NewRateLimitService(repository.NewMemoryCache(), RateLimits{
+		Address:    models.RateLimit{Requests: 1, Period: time.Minute},
+		ClientRead: models.RateLimit{Requests: 5, Period: time.Minute},
+	})
+	ctx := context.Background()
+
+	if result, err := s.AllowAddress(ctx, "10.0.0.1"); err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("unexpected result %+v, err=%v", result, err)
+	}
+	if result, err := s.AllowAddress(ctx, "10.0.0.1"); err != nil || result.Allowed {
+		t.Fatalf("expected address limit, got %+v, err=%v", result, err)
+	}
+	if result, err := s.AllowAddress(ctx, "10.0.0.2"); err != nil || !result.Allowed {
+		t.Fatalf("expected other address to be allowed, got %+v, err=%v", result, err)
+	}
+
+	// Лимит адреса не расходует бакеты клиента
+	if result, err := s.Allow(ctx, "ip:10.0.0.1", "GET /list", 1, false); err != nil || result.Remaining != 4 {
+		t.Fatalf("unexpected client result %+v, err=%v", result, err)
+	}
+
+	unlimited := NewRateLimitService(repository.NewMemoryCache(), RateLimits{ClientRead: models.RateLimit{Requests: 5, Period: time.Minute}})
+	if result, err := unlimited.AllowAddress(ctx, "10.0.0.1"); err != nil || result != nil {
+		t.Fatalf("expected no address limit, got %+v, err=%v", result, err)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    models.RateLimit
+		wantErr bool
+	}{
+		{"100/1s", models.RateLimit{Requests: 100, Period: time.Second}, false},
+		{" 600 / 1m ", models.RateLimit{Requests: 600, Period: time.Minute}, false},
+		{"", models.RateLimit{}, false},
+		{"0", models.RateLimit{}, false},
+		{"100", models.RateLimit{}, true},
+		{"-1/1s", models.RateLimit{}, true},
+		{"100/1", models.RateLimit{}, true},
+		{"100/0s", models.RateLimit{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRateLimit(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%q: expected %+v (error %v), got %+v, %v", tt.value, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestParseRouteRateLimits(t *testing.T) {
+	routes, err := ParseRouteRateLimits([]string{
+		"POST /api/v1/goods/import=1/1m,5/1m",
+		"get /api/v2/projects=10/1s",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]RouteRateLimit{
+		"POST /api/v1/goods/import": {
+			Client:  models.RateLimit{Requests: 1, Period: time.Minute},
+			Project: models.RateLimit{Requests: 5, Period: time.Minute},
+		},
+		"GET /api/v2/projects": {Client: models.RateLimit{Requests: 10, Period: time.Second}},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, routes)
+	}
+	for route, limit := range want {
+		if routes[route] != limit {
+			t.Errorf("%s: expected %+v, got %+v", route, limit, routes[route])
+		}
+	}
+
+	for _, value := range []string{"/api/v1/goods/import=1/1m", "POST api=1/1m", "POST /api=1m", "POST /api"} {
+		if _, err := ParseRouteRateLimits([]string{value}); err == nil {
+			t.Errorf("%q: expected error", value)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"time"
+)
+
+// GoodStore Основное хранилище товаров. Изменения товаров записывают
+// события в outbox в той же транзакции
+type GoodStore interface {
+	CreateGood(ctx context.Context, good *models.Good) error
+	UpdateGood(ctx context.Context, good *models.Good, patch models.GoodPatch) (*models.Good, error)
+	CreateGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error)
+	UpdateGoods(ctx context.Context, projectID int, goods []models.Good, patches []models.GoodPatch, atomic bool) ([]error, error)
+	RemoveGoods(ctx context.Context, projectID int, goods []models.Good, atomic bool) ([]error, error)
+	ImportGoods(ctx context.Context, projectID int, next func() (*models.ImportRow, error)) (*models.ImportResult, error)
+	ReprioritizeGoods(ctx context.Context, id, projectID, newPriority, version int) ([]models.GoodChange, error)
+	ReorderGoods(ctx context.Context, projectID int, reorder models.GoodsReorder) ([]models.GoodChange, error)
+	ListGoods(ctx context.Context, filter models.GoodsFilter) ([]models.Good, error)
+	CountGoods(ctx context.Context, filter models.GoodsFilter) (total int, removed int, err error)
+	GetGood(ctx context.Context, id, projectID int) (*models.Good, error)
+	MarkAsRemoved(ctx context.Context, good *models.Good) error
+	RestoreGood(ctx context.Context, good *models.Good) (*models.Good, error)
+	ListPurgeableGoods(ctx context.Context, removedBefore time.Time, afterID, limit int) ([]models.Good, error)
+	PurgeRemovedGoods(ctx context.Context, removedBefore time.Time, limit int) ([]models.Good, error)
+	CheckGoodExists(ctx context.Context, id, projectID int) (bool, error)
+}
+
+// ProjectStore Хранилище проектов
+type ProjectStore interface {
+	CreateProject(ctx context.Context, project *models.Project) error
+	GetProject(ctx context.Context, id int) (*models.Project, error)
+	ListProjects(ctx context.Context, limit, offset int) ([]models.Project, error)
+	GetProjectsCount(ctx context.Context) (int, error)
+	RenameProject(ctx context.Context, project *models.Project) error
+	RemoveProject(ctx context.Context, id int) ([]models.Good, error)
+	CheckProjectExists(ctx context.Context, id int) (bool, error)
+}
+
+// OutboxStore Очередь событий, ожидающих отправки
+type OutboxStore interface {
+	ProcessOutbox(ctx context.Context, limit int, publish func(messages []models.OutboxMessage) []error) (int, error)
+	DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// GoodCache Кэш товаров и счетчиков проектов. GetGood возвращает nil,
+// если товара нет в кэше, GetCounts - found = false, если нет счетчиков.
+// Поколение счетчиков меняется при каждом AdjustCounts и InvalidateCounts:
+// SetCounts сохраняет счетчики, только если поколение не изменилось с момента,
+// когда его прочитал CountsGeneration
+type GoodCache interface {
+	SetGood(ctx context.Context, good *models.Good) error
+	GetGood(ctx context.Context, id, projectID int) (*models.Good, error)
+	InvalidateGood(ctx context.Context, id, projectID int) error
+	SetGoods(ctx context.Context, goods []models.Good) error
+	InvalidateGoods(ctx context.Context, goods []models.Good) error
+	GetCounts(ctx context.Context, projectID int) (total int, removed int, found bool, err error)
+	CountsGeneration(ctx context.Context, projectID int) (int64, error)
+	SetCounts(ctx context.Context, projectID, total, removed int, generation int64) error
+	AdjustCounts(ctx context.Context, projectID, totalDelta, removedDelta int) error
+	InvalidateCounts(ctx context.Context, projectID int) error
+}
+
+// IdempotencyStore Хранилище ключей идемпотентности с ограниченным временем жизни
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey Атомарно сохраняет record под ключом, если ключа ещё нет,
+	// и возвращает nil. Если ключ уже есть, возвращает сохранённую под ним запись
+	ReserveIdempotencyKey(ctx context.Context, key string, record *models.IdempotencyRecord, ttl time.Duration) (*models.IdempotencyRecord, error)
+	// SaveIdempotencyKey Заменяет запись под ключом на record, только если ключ
+	// зарезервирован record.Owner, иначе возвращает models.ErrIdempotencyKeyNotOwned
+	SaveIdempotencyKey(ctx context.Context, key string, record *models.IdempotencyRecord, ttl time.Duration) error
+	// ExtendIdempotencyKey Продлевает резерв незавершённого запроса owner на ttl
+	ExtendIdempotencyKey(ctx context.Context, key, owner string, ttl time.Duration) error
+	// ReleaseIdempotencyKey Удаляет ключ, только если он зарезервирован owner
+	ReleaseIdempotencyKey(ctx context.Context, key, owner string) error
+}
+
+// EventLog Лог событий об изменении товаров, из которого читается история
+type EventLog interface {
+	GetHistory(ctx context.Context, filter models.HistoryFilter) ([]models.ClickhouseEvent, error)
+	CountHistory(ctx context.Context, filter models.HistoryFilter) (int, error)
+}
+
+// AuthStore Хранилище API ключей и доступа клиентов к проектам
+type AuthStore interface {
+	// GetAPIKey Возвращает действующий ключ по SHA-256 хэшу или models.ErrNotFound
+	GetAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	GetProjectGrants(ctx context.Context, subject string) ([]models.ProjectGrant, error)
+}
+
+// RateLimitStore Хранилище бакетов ограничения частоты запросов, общее для всех экземпляров сервиса
+type RateLimitStore interface {
+	// TakeTokens Атомарно забирает по токену из каждого бакета, только если токен
+	// есть во всех. Возвращает состояние бакетов в порядке buckets
+	TakeTokens(ctx context.Context, buckets []models.RateLimitBucket) (allowed bool, states []models.RateLimitState, err error)
+}
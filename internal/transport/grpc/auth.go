@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"goods-service/internal/models"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log"
+	"strings"
+)
+
+// readMethods Методы, для которых достаточно доступа к проекту на чтение
+var readMethods = map[string]bool{
+	goodspb.GoodsService_GetGood_FullMethodName:         true,
+	goodspb.GoodsService_ListGoods_FullMethodName:       true,
+	goodspb.ProjectsService_GetProject_FullMethodName:   true,
+	goodspb.ProjectsService_ListProjects_FullMethodName: true,
+}
+
+// projectRequest Запрос к товарам проекта
+type projectRequest interface {
+	GetProjectId() int64
+}
+
+// projectIdRequest Запрос к проекту по его идентификатору
+type projectIdRequest interface {
+	GetId() int64
+}
+
+// authInterceptor Проверяет клиента по метаданным x-api-key или authorization: Bearer
+// так же, как HTTP API, и его доступ к проекту запроса
+type authInterceptor struct {
+	authService *service.AuthService
+}
+
+// unary Проверяет доступ перед вызовом обработчика
+func (a *authInterceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	identity, err := a.identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(identity, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+
+	return handler(models.WithIdentity(ctx, identity), req)
+}
+
+// stream Проверяет клиента при открытии потока, а доступ к проекту -
+// при получении запроса, в котором передан проект
+func (a *authInterceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	identity, err := a.identify(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{
+		ServerStream: ss,
+		ctx:          models.WithIdentity(ss.Context(), identity),
+		identity:     identity,
+		method:       info.FullMethod,
+	})
+}
+
+// identify Определяет клиента по метаданным вызова. API ключ имеет приоритет над токеном
+func (a *authInterceptor) identify(ctx context.Context) (*models.Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var (
+		identity *models.Identity
+		err      = models.ErrUnauthenticated
+	)
+	if keys := md.Get("x-api-key"); len(keys) > 0 && keys[0] != "" {
+		identity, err = a.authService.AuthenticateAPIKey(ctx, keys[0])
+	} else if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") && token != "" {
+			identity, err = a.authService.AuthenticateToken(ctx, strings.TrimSpace(token))
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, models.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, "Authentication required")
+		}
+		log.Printf("grpc: failed to authenticate request: %v", err)
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+
+	return identity, nil
+}
+
+// authorize Проверяет доступ клиента к проекту запроса. Запросы без проекта,
+// например список и создание проектов, требуют доступа ко всем проектам
+func authorize(identity *models.Identity, method string, req interface{}) error {
+	projectId := requestProjectId(req)
+	allowed := identity.CanWrite(projectId)
+	if readMethods[method] {
+		allowed = identity.CanRead(projectId)
+	}
+	if !allowed {
+		return status.Error(codes.PermissionDenied, "Access to the project is denied")
+	}
+
+	return nil
+}
+
+// requestProjectId Проект запроса, models.AllProjects для запросов без проекта
+func requestProjectId(req interface{}) int {
+	switch req := req.(type) {
+	case projectRequest:
+		return int(req.GetProjectId())
+	case projectIdRequest:
+		return int(req.GetId())
+	}
+
+	return models.AllProjects
+}
+
+// authorizedStream Поток с клиентом в контексте, проверяющий доступ
+// к проекту каждого полученного запроса
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	identity *models.Identity
+	method   string
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return authorize(s.identity, s.method, m)
+}
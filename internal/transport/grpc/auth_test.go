@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	store := repository.NewMemoryAuthStore()
+	store.AddAPIKey(&models.APIKey{Name: "admin", Subject: "admin"}, service.HashAPIKey("admin-key"))
+	store.GrantProject("admin", models.ProjectGrant{ProjectID: models.AllProjects, Access: models.AccessWrite})
+	store.AddAPIKey(&models.APIKey{Name: "reader", Subject: "reader"}, service.HashAPIKey("reader-key"))
+	store.GrantProject("reader", models.ProjectGrant{ProjectID: 1, Access: models.AccessRead})
+
+	env := newTestEnvWithAuth(t, service.NewAuthService(store, service.JWTOptions{}, 0))
+	admin := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "admin-key")
+	reader := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "reader-key")
+
+	_, err := env.goods.GetGood(context.Background(), &goodspb.GetGoodRequest{Id: 1, ProjectId: 1})
+	wantCode(t, err, codes.Unauthenticated)
+
+	unknown := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+	_, err = env.goods.GetGood(unknown, &goodspb.GetGoodRequest{Id: 1, ProjectId: 1})
+	wantCode(t, err, codes.Unauthenticated)
+
+	good, err := env.goods.CreateGood(admin, &goodspb.CreateGoodRequest{ProjectId: 1, Name: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.goods.GetGood(reader, &goodspb.GetGoodRequest{Id: good.GetId(), ProjectId: 1}); err != nil {
+		t.Fatalf("expected read access: %v", err)
+	}
+
+	_, err = env.goods.CreateGood(reader, &goodspb.CreateGoodRequest{ProjectId: 1, Name: "second"})
+	wantCode(t, err, codes.PermissionDenied)
+
+	_, err = env.projects.GetProject(reader, &goodspb.GetProjectRequest{Id: 2})
+	wantCode(t, err, codes.PermissionDenied)
+
+	_, err = env.projects.CreateProject(reader, &goodspb.CreateProjectRequest{Name: "other"})
+	wantCode(t, err, codes.PermissionDenied)
+
+	// Проект потока проверяется при получении запроса
+	stream, err := env.goods.ListGoods(reader, &goodspb.ListGoodsRequest{ProjectId: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	wantCode(t, err, codes.PermissionDenied)
+
+	stream, err = env.goods.ListGoods(reader, &goodspb.ListGoodsRequest{ProjectId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed, err := stream.Recv(); err != nil || listed.GetId() != good.GetId() {
+		t.Fatalf("expected listed good, got %v, %v", listed, err)
+	}
+}
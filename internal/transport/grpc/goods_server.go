@@ -0,0 +1,288 @@
+package grpc
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+)
+
+// maxGoodNameLength Максимальная длина названия товара
+const maxGoodNameLength = 255
+
+// maxExternalKeyLength Максимальная длина внешнего ключа товара
+const maxExternalKeyLength = 255
+
+// GoodsServer Реализация goodspb.GoodsServiceServer
+type GoodsServer struct {
+	goodspb.UnimplementedGoodsServiceServer
+
+	goodService    *service.GoodService
+	projectService *service.ProjectService
+}
+
+func NewGoodsServer(goodService *service.GoodService, projectService *service.ProjectService) *GoodsServer {
+	return &GoodsServer{
+		goodService:    goodService,
+		projectService: projectService,
+	}
+}
+
+func (s *GoodsServer) GetGood(ctx context.Context, req *goodspb.GetGoodRequest) (*goodspb.Good, error) {
+	if err := validateGoodRef(req.GetProjectId(), req.GetId()); err != nil {
+		return nil, err
+	}
+
+	good, err := s.goodService.GetGood(ctx, int(req.GetId()), int(req.GetProjectId()))
+	if err != nil {
+		return nil, statusError(err, "Good not found")
+	}
+
+	return goodMessage(good), nil
+}
+
+// ListGoods Передаёт товары в поток страницами по ключу (приоритет, id),
+// поэтому список не держится в памяти целиком
+func (s *GoodsServer) ListGoods(req *goodspb.ListGoodsRequest, stream grpc.ServerStreamingServer[goodspb.Good]) error {
+	ctx := stream.Context()
+
+	if err := validateId("projectId", req.GetProjectId()); err != nil {
+		return err
+	}
+	if req.GetLimit() < 0 {
+		return invalidArgument("limit must not be negative")
+	}
+
+	// Пустой список не отличить от отсутствующего проекта, поэтому проверяем проект
+	if _, err := s.projectService.GetProject(ctx, int(req.GetProjectId())); err != nil {
+		return statusError(err, "Project not found")
+	}
+
+	filter := models.GoodsFilter{
+		ProjectID:      int(req.GetProjectId()),
+		Name:           req.GetName(),
+		IncludeRemoved: req.GetIncludeRemoved(),
+		SortDesc:       req.GetDesc(),
+		Keyset:         true,
+	}
+	if req.PriorityFrom != nil {
+		priorityFrom := int(req.GetPriorityFrom())
+		filter.PriorityFrom = &priorityFrom
+	}
+	if req.PriorityTo != nil {
+		priorityTo := int(req.GetPriorityTo())
+		filter.PriorityTo = &priorityTo
+	}
+
+	remaining := int(req.GetLimit())
+	for {
+		filter.Limit = listPageSize
+		if remaining > 0 && remaining < listPageSize {
+			filter.Limit = remaining
+		}
+
+		goods, next, err := s.goodService.ListGoods(ctx, filter)
+		if err != nil {
+			return statusError(err, "Project not found")
+		}
+
+		for i := range goods {
+			if err := stream.Send(goodMessage(&goods[i])); err != nil {
+				return err
+			}
+		}
+
+		if req.GetLimit() > 0 {
+			remaining -= len(goods)
+			if remaining <= 0 {
+				return nil
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		filter.After = next
+	}
+}
+
+func (s *GoodsServer) CreateGood(ctx context.Context, req *goodspb.CreateGoodRequest) (*goodspb.Good, error) {
+	if err := validateId("projectId", req.GetProjectId()); err != nil {
+		return nil, err
+	}
+	if err := validateGoodName(req.GetName()); err != nil {
+		return nil, err
+	}
+	if len([]rune(req.GetExternalKey())) > maxExternalKeyLength {
+		return nil, invalidArgument("External key is too long")
+	}
+
+	good := models.Good{
+		ProjectID:   int(req.GetProjectId()),
+		ExternalKey: req.GetExternalKey(),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+	}
+	if err := s.goodService.CreateGood(ctx, &good); err != nil {
+		return nil, statusError(err, "Project not found")
+	}
+
+	return goodMessage(&good), nil
+}
+
+// UpdateGood Меняет переданные в запросе поля товара, только если он хранится
+// в версии version. Без проверки версии товар меняется с any_version
+func (s *GoodsServer) UpdateGood(ctx context.Context, req *goodspb.UpdateGoodRequest) (*goodspb.Good, error) {
+	if err := validateGoodRef(req.GetProjectId(), req.GetId()); err != nil {
+		return nil, err
+	}
+	if req.Name == nil && req.Description == nil {
+		return nil, invalidArgument("Name or description is required")
+	}
+	if req.Name != nil {
+		if err := validateGoodName(req.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	version, err := expectedVersion(req.GetVersion(), req.GetAnyVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	good := models.Good{
+		ID:        int(req.GetId()),
+		ProjectID: int(req.GetProjectId()),
+		Version:   version,
+	}
+	patch := models.GoodPatch{Name: req.Name, Description: req.Description}
+	if err := s.goodService.UpdateGood(ctx, &good, patch); err != nil {
+		return nil, statusError(err, "Good not found")
+	}
+
+	return goodMessage(&good), nil
+}
+
+func (s *GoodsServer) RemoveGood(ctx context.Context, req *goodspb.RemoveGoodRequest) (*goodspb.RemoveGoodResponse, error) {
+	if err := validateGoodRef(req.GetProjectId(), req.GetId()); err != nil {
+		return nil, err
+	}
+	version, err := expectedVersion(req.GetVersion(), req.GetAnyVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.goodService.DeleteGood(ctx, int(req.GetId()), int(req.GetProjectId()), version); err != nil {
+		return nil, statusError(err, "Good not found")
+	}
+
+	return &goodspb.RemoveGoodResponse{
+		Id:        req.GetId(),
+		ProjectId: req.GetProjectId(),
+		Removed:   true,
+	}, nil
+}
+
+func (s *GoodsServer) RestoreGood(ctx context.Context, req *goodspb.RestoreGoodRequest) (*goodspb.Good, error) {
+	if err := validateGoodRef(req.GetProjectId(), req.GetId()); err != nil {
+		return nil, err
+	}
+
+	good, err := s.goodService.RestoreGood(ctx, int(req.GetId()), int(req.GetProjectId()))
+	if err != nil {
+		return nil, statusError(err, "Good not found")
+	}
+
+	return goodMessage(good), nil
+}
+
+func (s *GoodsServer) ReprioritizeGood(ctx context.Context, req *goodspb.ReprioritizeGoodRequest) (*goodspb.ReprioritizeGoodResponse, error) {
+	if err := validateGoodRef(req.GetProjectId(), req.GetId()); err != nil {
+		return nil, err
+	}
+	if req.GetPriority() < 1 {
+		return nil, invalidArgument("Priority must be greater than 0")
+	}
+	version, err := expectedVersion(req.GetVersion(), req.GetAnyVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.goodService.ReprioritizeGood(ctx, int(req.GetId()), int(req.GetProjectId()),
+		int(req.GetPriority()), version)
+	if err != nil {
+		return nil, statusError(err, "Good not found")
+	}
+
+	priorities := make([]*goodspb.GoodPriority, 0, len(response.Priorities))
+	for _, item := range response.Priorities {
+		priorities = append(priorities, &goodspb.GoodPriority{
+			Id:       int64(item.ID),
+			Priority: int64(item.Priority),
+			Version:  int64(item.Version),
+		})
+	}
+
+	return &goodspb.ReprioritizeGoodResponse{Priorities: priorities}, nil
+}
+
+// validateGoodRef Проверяет идентификаторы проекта и товара из запроса
+func validateGoodRef(projectId, id int64) error {
+	if err := validateId("projectId", projectId); err != nil {
+		return err
+	}
+
+	return validateId("id", id)
+}
+
+// expectedVersion Возвращает версию, в которой должен храниться изменяемый товар,
+// 0 - любая. Как и If-Match в HTTP API, версия обязательна: без неё вызов
+// отклоняется с FAILED_PRECONDITION, а любую версию нужно запросить явно через anyVersion
+func expectedVersion(version int64, anyVersion bool) (int, error) {
+	switch {
+	case anyVersion && version != 0:
+		return 0, invalidArgument("Version must not be set with anyVersion")
+	case anyVersion:
+		return 0, nil
+	case version == 0:
+		return 0, status.Error(codes.FailedPrecondition, "Good version is required")
+	case version < 0:
+		return 0, invalidArgument("Invalid good version")
+	}
+
+	return int(version), nil
+}
+
+func validateGoodName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return invalidArgument("Good name is required")
+	}
+	if len([]rune(name)) > maxGoodNameLength {
+		return invalidArgument("Good name is too long")
+	}
+
+	return nil
+}
+
+// goodMessage Преобразует товар в сообщение gRPC
+func goodMessage(good *models.Good) *goodspb.Good {
+	msg := &goodspb.Good{
+		Id:          int64(good.ID),
+		ProjectId:   int64(good.ProjectID),
+		ExternalKey: good.ExternalKey,
+		Name:        good.Name,
+		Description: good.Description,
+		Priority:    int64(good.Priority),
+		Removed:     good.Removed,
+		CreatedAt:   timestamppb.New(good.CreatedAt),
+		Version:     int64(good.Version),
+	}
+	if good.RemovedAt != nil {
+		msg.RemovedAt = timestamppb.New(*good.RemovedAt)
+	}
+
+	return msg
+}
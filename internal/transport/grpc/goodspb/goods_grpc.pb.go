@@ -0,0 +1,647 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: goods/v1/goods.proto
+
+package goodspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GoodsService_GetGood_FullMethodName          = "/goods.v1.GoodsService/GetGood"
+	GoodsService_ListGoods_FullMethodName        = "/goods.v1.GoodsService/ListGoods"
+	GoodsService_CreateGood_FullMethodName       = "/goods.v1.GoodsService/CreateGood"
+	GoodsService_UpdateGood_FullMethodName       = "/goods.v1.GoodsService/UpdateGood"
+	GoodsService_RemoveGood_FullMethodName       = "/goods.v1.GoodsService/RemoveGood"
+	GoodsService_RestoreGood_FullMethodName      = "/goods.v1.GoodsService/RestoreGood"
+	GoodsService_ReprioritizeGood_FullMethodName = "/goods.v1.GoodsService/ReprioritizeGood"
+)
+
+// GoodsServiceClient is the client API for GoodsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GoodsService Товары проекта. Ошибки возвращаются статусами gRPC:
+// NOT_FOUND - проект или товар не найден, INVALID_ARGUMENT - некорректный запрос,
+// ALREADY_EXISTS - внешний ключ занят, ABORTED - версия товара устарела
+type GoodsServiceClient interface {
+	// GetGood Возвращает товар проекта
+	GetGood(ctx context.Context, in *GetGoodRequest, opts ...grpc.CallOption) (*Good, error)
+	// ListGoods Передаёт товары проекта в порядке приоритета
+	ListGoods(ctx context.Context, in *ListGoodsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Good], error)
+	// CreateGood Создаёт товар с наименьшим приоритетом в проекте
+	CreateGood(ctx context.Context, in *CreateGoodRequest, opts ...grpc.CallOption) (*Good, error)
+	// UpdateGood Меняет переданные поля товара
+	UpdateGood(ctx context.Context, in *UpdateGoodRequest, opts ...grpc.CallOption) (*Good, error)
+	// RemoveGood Отмечает товар удалённым
+	RemoveGood(ctx context.Context, in *RemoveGoodRequest, opts ...grpc.CallOption) (*RemoveGoodResponse, error)
+	// RestoreGood Восстанавливает удалённый товар с наименьшим приоритетом
+	RestoreGood(ctx context.Context, in *RestoreGoodRequest, opts ...grpc.CallOption) (*Good, error)
+	// ReprioritizeGood Перемещает товар на новую позицию
+	ReprioritizeGood(ctx context.Context, in *ReprioritizeGoodRequest, opts ...grpc.CallOption) (*ReprioritizeGoodResponse, error)
+}
+
+type goodsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoodsServiceClient(cc grpc.ClientConnInterface) GoodsServiceClient {
+	return &goodsServiceClient{cc}
+}
+
+func (c *goodsServiceClient) GetGood(ctx context.Context, in *GetGoodRequest, opts ...grpc.CallOption) (*Good, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Good)
+	err := c.cc.Invoke(ctx, GoodsService_GetGood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goodsServiceClient) ListGoods(ctx context.Context, in *ListGoodsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Good], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GoodsService_ServiceDesc.Streams[0], GoodsService_ListGoods_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListGoodsRequest, Good]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoodsService_ListGoodsClient = grpc.ServerStreamingClient[Good]
+
+func (c *goodsServiceClient) CreateGood(ctx context.Context, in *CreateGoodRequest, opts ...grpc.CallOption) (*Good, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Good)
+	err := c.cc.Invoke(ctx, GoodsService_CreateGood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goodsServiceClient) UpdateGood(ctx context.Context, in *UpdateGoodRequest, opts ...grpc.CallOption) (*Good, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Good)
+	err := c.cc.Invoke(ctx, GoodsService_UpdateGood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goodsServiceClient) RemoveGood(ctx context.Context, in *RemoveGoodRequest, opts ...grpc.CallOption) (*RemoveGoodResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveGoodResponse)
+	err := c.cc.Invoke(ctx, GoodsService_RemoveGood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goodsServiceClient) RestoreGood(ctx context.Context, in *RestoreGoodRequest, opts ...grpc.CallOption) (*Good, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Good)
+	err := c.cc.Invoke(ctx, GoodsService_RestoreGood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goodsServiceClient) ReprioritizeGood(ctx context.Context, in *ReprioritizeGoodRequest, opts ...grpc.CallOption) (*ReprioritizeGoodResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReprioritizeGoodResponse)
+	err := c.cc.Invoke(ctx, GoodsService_ReprioritizeGood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GoodsServiceServer is the server API for GoodsService service.
+// All implementations must embed UnimplementedGoodsServiceServer
+// for forward compatibility.
+//
+// GoodsService Товары проекта. Ошибки возвращаются статусами gRPC:
+// NOT_FOUND - проект или товар не найден, INVALID_ARGUMENT - некорректный запрос,
+// ALREADY_EXISTS - внешний ключ занят, ABORTED - версия товара устарела
+type GoodsServiceServer interface {
+	// GetGood Возвращает товар проекта
+	GetGood(context.Context, *GetGoodRequest) (*Good, error)
+	// ListGoods Передаёт товары проекта в порядке приоритета
+	ListGoods(*ListGoodsRequest, grpc.ServerStreamingServer[Good]) error
+	// CreateGood Создаёт товар с наименьшим приоритетом в проекте
+	CreateGood(context.Context, *CreateGoodRequest) (*Good, error)
+	// UpdateGood Меняет переданные поля товара
+	UpdateGood(context.Context, *UpdateGoodRequest) (*Good, error)
+	// RemoveGood Отмечает товар удалённым
+	RemoveGood(context.Context, *RemoveGoodRequest) (*RemoveGoodResponse, error)
+	// RestoreGood Восстанавливает удалённый товар с наименьшим приоритетом
+	RestoreGood(context.Context, *RestoreGoodRequest) (*Good, error)
+	// ReprioritizeGood Перемещает товар на новую позицию
+	ReprioritizeGood(context.Context, *ReprioritizeGoodRequest) (*ReprioritizeGoodResponse, error)
+	mustEmbedUnimplementedGoodsServiceServer()
+}
+
+// UnimplementedGoodsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGoodsServiceServer struct{}
+
+func (UnimplementedGoodsServiceServer) GetGood(context.Context, *GetGoodRequest) (*Good, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGood not implemented")
+}
+func (UnimplementedGoodsServiceServer) ListGoods(*ListGoodsRequest, grpc.ServerStreamingServer[Good]) error {
+	return status.Errorf(codes.Unimplemented, "method ListGoods not implemented")
+}
+func (UnimplementedGoodsServiceServer) CreateGood(context.Context, *CreateGoodRequest) (*Good, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGood not implemented")
+}
+func (UnimplementedGoodsServiceServer) UpdateGood(context.Context, *UpdateGoodRequest) (*Good, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGood not implemented")
+}
+func (UnimplementedGoodsServiceServer) RemoveGood(context.Context, *RemoveGoodRequest) (*RemoveGoodResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveGood not implemented")
+}
+func (UnimplementedGoodsServiceServer) RestoreGood(context.Context, *RestoreGoodRequest) (*Good, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreGood not implemented")
+}
+func (UnimplementedGoodsServiceServer) ReprioritizeGood(context.Context, *ReprioritizeGoodRequest) (*ReprioritizeGoodResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReprioritizeGood not implemented")
+}
+func (UnimplementedGoodsServiceServer) mustEmbedUnimplementedGoodsServiceServer() {}
+func (UnimplementedGoodsServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeGoodsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoodsServiceServer will
+// result in compilation errors.
+type UnsafeGoodsServiceServer interface {
+	mustEmbedUnimplementedGoodsServiceServer()
+}
+
+func RegisterGoodsServiceServer(s grpc.ServiceRegistrar, srv GoodsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGoodsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GoodsService_ServiceDesc, srv)
+}
+
+func _GoodsService_GetGood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoodsServiceServer).GetGood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoodsService_GetGood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoodsServiceServer).GetGood(ctx, req.(*GetGoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoodsService_ListGoods_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListGoodsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoodsServiceServer).ListGoods(m, &grpc.GenericServerStream[ListGoodsRequest, Good]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoodsService_ListGoodsServer = grpc.ServerStreamingServer[Good]
+
+func _GoodsService_CreateGood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoodsServiceServer).CreateGood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoodsService_CreateGood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoodsServiceServer).CreateGood(ctx, req.(*CreateGoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoodsService_UpdateGood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoodsServiceServer).UpdateGood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoodsService_UpdateGood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoodsServiceServer).UpdateGood(ctx, req.(*UpdateGoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoodsService_RemoveGood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveGoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoodsServiceServer).RemoveGood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoodsService_RemoveGood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoodsServiceServer).RemoveGood(ctx, req.(*RemoveGoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoodsService_RestoreGood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreGoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoodsServiceServer).RestoreGood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoodsService_RestoreGood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoodsServiceServer).RestoreGood(ctx, req.(*RestoreGoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoodsService_ReprioritizeGood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReprioritizeGoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoodsServiceServer).ReprioritizeGood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoodsService_ReprioritizeGood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoodsServiceServer).ReprioritizeGood(ctx, req.(*ReprioritizeGoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GoodsService_ServiceDesc is the grpc.ServiceDesc for GoodsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoodsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goods.v1.GoodsService",
+	HandlerType: (*GoodsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGood",
+			Handler:    _GoodsService_GetGood_Handler,
+		},
+		{
+			MethodName: "CreateGood",
+			Handler:    _GoodsService_CreateGood_Handler,
+		},
+		{
+			MethodName: "UpdateGood",
+			Handler:    _GoodsService_UpdateGood_Handler,
+		},
+		{
+			MethodName: "RemoveGood",
+			Handler:    _GoodsService_RemoveGood_Handler,
+		},
+		{
+			MethodName: "RestoreGood",
+			Handler:    _GoodsService_RestoreGood_Handler,
+		},
+		{
+			MethodName: "ReprioritizeGood",
+			Handler:    _GoodsService_ReprioritizeGood_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListGoods",
+			Handler:       _GoodsService_ListGoods_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "goods/v1/goods.proto",
+}
+
+const (
+	ProjectsService_GetProject_FullMethodName    = "/goods.v1.ProjectsService/GetProject"
+	ProjectsService_ListProjects_FullMethodName  = "/goods.v1.ProjectsService/ListProjects"
+	ProjectsService_CreateProject_FullMethodName = "/goods.v1.ProjectsService/CreateProject"
+	ProjectsService_UpdateProject_FullMethodName = "/goods.v1.ProjectsService/UpdateProject"
+	ProjectsService_RemoveProject_FullMethodName = "/goods.v1.ProjectsService/RemoveProject"
+)
+
+// ProjectsServiceClient is the client API for ProjectsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ProjectsService Проекты, к которым относятся товары
+type ProjectsServiceClient interface {
+	// GetProject Возвращает проект
+	GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*Project, error)
+	// ListProjects Передаёт все проекты
+	ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Project], error)
+	// CreateProject Создаёт проект
+	CreateProject(ctx context.Context, in *CreateProjectRequest, opts ...grpc.CallOption) (*Project, error)
+	// UpdateProject Переименовывает проект
+	UpdateProject(ctx context.Context, in *UpdateProjectRequest, opts ...grpc.CallOption) (*Project, error)
+	// RemoveProject Удаляет проект вместе с его товарами
+	RemoveProject(ctx context.Context, in *RemoveProjectRequest, opts ...grpc.CallOption) (*RemoveProjectResponse, error)
+}
+
+type projectsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProjectsServiceClient(cc grpc.ClientConnInterface) ProjectsServiceClient {
+	return &projectsServiceClient{cc}
+}
+
+func (c *projectsServiceClient) GetProject(ctx context.Context, in *GetProjectRequest, opts ...grpc.CallOption) (*Project, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Project)
+	err := c.cc.Invoke(ctx, ProjectsService_GetProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *projectsServiceClient) ListProjects(ctx context.Context, in *ListProjectsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Project], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ProjectsService_ServiceDesc.Streams[0], ProjectsService_ListProjects_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListProjectsRequest, Project]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProjectsService_ListProjectsClient = grpc.ServerStreamingClient[Project]
+
+func (c *projectsServiceClient) CreateProject(ctx context.Context, in *CreateProjectRequest, opts ...grpc.CallOption) (*Project, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Project)
+	err := c.cc.Invoke(ctx, ProjectsService_CreateProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *projectsServiceClient) UpdateProject(ctx context.Context, in *UpdateProjectRequest, opts ...grpc.CallOption) (*Project, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Project)
+	err := c.cc.Invoke(ctx, ProjectsService_UpdateProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *projectsServiceClient) RemoveProject(ctx context.Context, in *RemoveProjectRequest, opts ...grpc.CallOption) (*RemoveProjectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveProjectResponse)
+	err := c.cc.Invoke(ctx, ProjectsService_RemoveProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProjectsServiceServer is the server API for ProjectsService service.
+// All implementations must embed UnimplementedProjectsServiceServer
+// for forward compatibility.
+//
+// ProjectsService Проекты, к которым относятся товары
+type ProjectsServiceServer interface {
+	// GetProject Возвращает проект
+	GetProject(context.Context, *GetProjectRequest) (*Project, error)
+	// ListProjects Передаёт все проекты
+	ListProjects(*ListProjectsRequest, grpc.ServerStreamingServer[Project]) error
+	// CreateProject Создаёт проект
+	CreateProject(context.Context, *CreateProjectRequest) (*Project, error)
+	// UpdateProject Переименовывает проект
+	UpdateProject(context.Context, *UpdateProjectRequest) (*Project, error)
+	// RemoveProject Удаляет проект вместе с его товарами
+	RemoveProject(context.Context, *RemoveProjectRequest) (*RemoveProjectResponse, error)
+	mustEmbedUnimplementedProjectsServiceServer()
+}
+
+// UnimplementedProjectsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProjectsServiceServer struct{}
+
+func (UnimplementedProjectsServiceServer) GetProject(context.Context, *GetProjectRequest) (*Project, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProject not implemented")
+}
+func (UnimplementedProjectsServiceServer) ListProjects(*ListProjectsRequest, grpc.ServerStreamingServer[Project]) error {
+	return status.Errorf(codes.Unimplemented, "method ListProjects not implemented")
+}
+func (UnimplementedProjectsServiceServer) CreateProject(context.Context, *CreateProjectRequest) (*Project, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProject not implemented")
+}
+func (UnimplementedProjectsServiceServer) UpdateProject(context.Context, *UpdateProjectRequest) (*Project, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateProject not implemented")
+}
+func (UnimplementedProjectsServiceServer) RemoveProject(context.Context, *RemoveProjectRequest) (*RemoveProjectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveProject not implemented")
+}
+func (UnimplementedProjectsServiceServer) mustEmbedUnimplementedProjectsServiceServer() {}
+func (UnimplementedProjectsServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeProjectsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProjectsServiceServer will
+// result in compilation errors.
+type UnsafeProjectsServiceServer interface {
+	mustEmbedUnimplementedProjectsServiceServer()
+}
+
+func RegisterProjectsServiceServer(s grpc.ServiceRegistrar, srv ProjectsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedProjectsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProjectsService_ServiceDesc, srv)
+}
+
+func _ProjectsService_GetProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectsServiceServer).GetProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProjectsService_GetProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectsServiceServer).GetProject(ctx, req.(*GetProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProjectsService_ListProjects_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListProjectsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProjectsServiceServer).ListProjects(m, &grpc.GenericServerStream[ListProjectsRequest, Project]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProjectsService_ListProjectsServer = grpc.ServerStreamingServer[Project]
+
+func _ProjectsService_CreateProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectsServiceServer).CreateProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProjectsService_CreateProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectsServiceServer).CreateProject(ctx, req.(*CreateProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProjectsService_UpdateProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectsServiceServer).UpdateProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProjectsService_UpdateProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectsServiceServer).UpdateProject(ctx, req.(*UpdateProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProjectsService_RemoveProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProjectsServiceServer).RemoveProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProjectsService_RemoveProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProjectsServiceServer).RemoveProject(ctx, req.(*RemoveProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProjectsService_ServiceDesc is the grpc.ServiceDesc for ProjectsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProjectsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goods.v1.ProjectsService",
+	HandlerType: (*ProjectsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetProject",
+			Handler:    _ProjectsService_GetProject_Handler,
+		},
+		{
+			MethodName: "CreateProject",
+			Handler:    _ProjectsService_CreateProject_Handler,
+		},
+		{
+			MethodName: "UpdateProject",
+			Handler:    _ProjectsService_UpdateProject_Handler,
+		},
+		{
+			MethodName: "RemoveProject",
+			Handler:    _ProjectsService_RemoveProject_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListProjects",
+			Handler:       _ProjectsService_ListProjects_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "goods/v1/goods.proto",
+}
@@ -0,0 +1,133 @@
+package grpc
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
+)
+
+// maxProjectNameLength Максимальная длина названия проекта
+const maxProjectNameLength = 255
+
+// ProjectsServer Реализация goodspb.ProjectsServiceServer
+type ProjectsServer struct {
+	goodspb.UnimplementedProjectsServiceServer
+
+	projectService *service.ProjectService
+}
+
+func NewProjectsServer(projectService *service.ProjectService) *ProjectsServer {
+	return &ProjectsServer{projectService: projectService}
+}
+
+func (s *ProjectsServer) GetProject(ctx context.Context, req *goodspb.GetProjectRequest) (*goodspb.Project, error) {
+	if err := validateId("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectService.GetProject(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, statusError(err, "Project not found")
+	}
+
+	return projectMessage(project), nil
+}
+
+// ListProjects Передаёт в поток все проекты, читая их страницами
+func (s *ProjectsServer) ListProjects(req *goodspb.ListProjectsRequest, stream grpc.ServerStreamingServer[goodspb.Project]) error {
+	ctx := stream.Context()
+
+	for offset := 0; ; offset += listPageSize {
+		projects, err := s.projectService.ListProjects(ctx, listPageSize, offset)
+		if err != nil {
+			return statusError(err, "Project not found")
+		}
+
+		for i := range projects {
+			if err := stream.Send(projectMessage(&projects[i])); err != nil {
+				return err
+			}
+		}
+
+		if len(projects) < listPageSize {
+			return nil
+		}
+	}
+}
+
+func (s *ProjectsServer) CreateProject(ctx context.Context, req *goodspb.CreateProjectRequest) (*goodspb.Project, error) {
+	name, err := validateProjectName(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	project := models.Project{Name: name}
+	if err := s.projectService.CreateProject(ctx, &project); err != nil {
+		return nil, statusError(err, "Project not found")
+	}
+
+	return projectMessage(&project), nil
+}
+
+// UpdateProject Переименовывает проект
+func (s *ProjectsServer) UpdateProject(ctx context.Context, req *goodspb.UpdateProjectRequest) (*goodspb.Project, error) {
+	if err := validateId("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	name, err := validateProjectName(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	project := models.Project{ID: int(req.GetId()), Name: name}
+	if err := s.projectService.RenameProject(ctx, &project); err != nil {
+		return nil, statusError(err, "Project not found")
+	}
+
+	return projectMessage(&project), nil
+}
+
+// RemoveProject Удаляет проект вместе с его товарами
+func (s *ProjectsServer) RemoveProject(ctx context.Context, req *goodspb.RemoveProjectRequest) (*goodspb.RemoveProjectResponse, error) {
+	if err := validateId("id", req.GetId()); err != nil {
+		return nil, err
+	}
+
+	removedGoods, err := s.projectService.DeleteProject(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, statusError(err, "Project not found")
+	}
+
+	return &goodspb.RemoveProjectResponse{
+		Id:           req.GetId(),
+		Removed:      true,
+		RemovedGoods: int64(removedGoods),
+	}, nil
+}
+
+// validateProjectName Проверяет название проекта и возвращает его без крайних пробелов
+func validateProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", invalidArgument("Project name is required")
+	}
+	if len([]rune(name)) > maxProjectNameLength {
+		return "", invalidArgument("Project name is too long")
+	}
+
+	return name, nil
+}
+
+// projectMessage Преобразует проект в сообщение gRPC
+func projectMessage(project *models.Project) *goodspb.Project {
+	return &goodspb.Project{
+		Id:        int64(project.ID),
+		Name:      project.Name,
+		CreatedAt: timestamppb.New(project.CreatedAt),
+	}
+}
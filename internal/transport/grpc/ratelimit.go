@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"time"
+)
+
+// rateLimitInterceptor Ограничивает частоту вызовов по тем же бакетам, что и HTTP API.
+// Маршрут вызова - "GRPC <полное имя метода>", например
+// "GRPC /goods.v1.GoodsService/CreateGood", по нему задаются лимиты отдельных методов
+type rateLimitInterceptor struct {
+	rateLimitService *service.RateLimitService
+}
+
+// unaryAddress Забирает токен адреса клиента до аутентификации
+func (l *rateLimitInterceptor) unaryAddress(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := l.allowAddress(ctx, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// streamAddress Забирает токен адреса клиента при открытии потока
+func (l *rateLimitInterceptor) streamAddress(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.allowAddress(ss.Context(), ss.SetHeader); err != nil {
+		return err
+	}
+
+	return handler(srv, ss)
+}
+
+// unary Забирает токены перед вызовом обработчика
+func (l *rateLimitInterceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := l.allow(ctx, info.FullMethod, req, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// stream Забирает токены при получении каждого запроса потока, когда известен его проект
+func (l *rateLimitInterceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &rateLimitedStream{ServerStream: ss, limiter: l, method: info.FullMethod})
+}
+
+// allow Проверяет лимиты вызова method и передаёт их состояние в setHeader.
+// Если хранилище лимитов недоступно, вызов пропускается без ограничения
+func (l *rateLimitInterceptor) allow(ctx context.Context, method string, req interface{}, setHeader func(metadata.MD) error) error {
+	result, err := l.rateLimitService.Allow(ctx, rateLimitClient(ctx), "GRPC "+method, requestProjectId(req), !readMethods[method])
+	if err != nil {
+		log.Printf("grpc: failed to check rate limit: %v", err)
+		return nil
+	}
+
+	return rateLimitError(result, setHeader)
+}
+
+// allowAddress Проверяет лимит адреса клиента. Метаданные передаются только при отказе
+func (l *rateLimitInterceptor) allowAddress(ctx context.Context, setHeader func(metadata.MD) error) error {
+	result, err := l.rateLimitService.AllowAddress(ctx, peerHost(ctx))
+	if err != nil {
+		log.Printf("grpc: failed to check rate limit: %v", err)
+		return nil
+	}
+	if result != nil && result.Allowed {
+		return nil
+	}
+
+	return rateLimitError(result, setHeader)
+}
+
+// rateLimitError Передаёт состояние бакета в setHeader и возвращает
+// ошибку RESOURCE_EXHAUSTED, если лимит исчерпан. nil result - без ограничения
+func rateLimitError(result *models.RateLimitResult, setHeader func(metadata.MD) error) error {
+	if result == nil {
+		return nil
+	}
+
+	md := metadata.Pairs(
+		"x-ratelimit-limit", strconv.Itoa(result.Limit),
+		"x-ratelimit-remaining", strconv.Itoa(result.Remaining),
+		"x-ratelimit-reset", strconv.Itoa(ceilSeconds(result.Reset)),
+	)
+	if !result.Allowed {
+		md.Set("retry-after", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+	}
+	if err := setHeader(md); err != nil {
+		log.Printf("grpc: failed to set rate limit headers: %v", err)
+	}
+
+	if !result.Allowed {
+		return status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+	}
+
+	return nil
+}
+
+// rateLimitedStream Поток, ограничивающий частоту полученных запросов
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limiter *rateLimitInterceptor
+	method  string
+}
+
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.limiter.allow(s.Context(), s.method, m, s.SetHeader)
+}
+
+// rateLimitClient Клиент, по которому считаются лимиты: субъект аутентификации,
+// без неё - адрес клиента
+func rateLimitClient(ctx context.Context) string {
+	if identity := models.IdentityFromContext(ctx); identity != nil {
+		return identity.Actor()
+	}
+
+	return "ip:" + peerHost(ctx)
+}
+
+// peerHost Адрес клиента без порта
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// ceilSeconds Длительность в целых секундах с округлением вверх
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"testing"
+	"time"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	rateLimits := service.NewRateLimitService(repository.NewMemoryCache(), service.RateLimits{
+		ClientRead:   models.RateLimit{Requests: 2, Period: time.Minute},
+		ProjectWrite: models.RateLimit{Requests: 1, Period: time.Minute},
+		Routes: map[string]service.RouteRateLimit{
+			"GRPC " + goodspb.ProjectsService_CreateProject_FullMethodName: {Client: models.RateLimit{Requests: 5, Period: time.Minute}},
+		},
+	})
+	env := newTestEnvWithServices(t, nil, rateLimits)
+	ctx := context.Background()
+
+	var header metadata.MD
+	good, err := env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: 1, Name: "first"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-ratelimit-remaining"); len(got) != 1 || got[0] != "0" {
+		t.Errorf("expected remaining 0, got %v", got)
+	}
+
+	// Метод с собственным лимитом
+	project, err := env.projects.CreateProject(ctx, &goodspb.CreateProjectRequest{Name: "second"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "5" {
+		t.Errorf("expected route limit 5, got %v", got)
+	}
+
+	// Изменения проекта исчерпали лимит, другой проект расходует свой бакет
+	_, err = env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: 1, Name: "second"})
+	wantCode(t, err, codes.ResourceExhausted)
+	if _, err := env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: project.GetId(), Name: "second"}); err != nil {
+		t.Fatalf("expected other project to be allowed: %v", err)
+	}
+
+	// Запрос потока расходует токены чтения так же, как унарный вызов
+	if _, err := env.goods.GetGood(ctx, &goodspb.GetGoodRequest{Id: good.GetId(), ProjectId: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if listed, err := env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1}); err != nil || len(listed) != 1 {
+		t.Fatalf("expected listed good, got %v, %v", listed, err)
+	}
+	_, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1})
+	wantCode(t, err, codes.ResourceExhausted)
+}
+
+func TestRateLimitInterceptor_Address(t *testing.T) {
+	store := repository.NewMemoryAuthStore()
+	store.AddAPIKey(&models.APIKey{Name: "admin", Subject: "admin"}, service.HashAPIKey("admin-key"))
+	store.GrantProject("admin", models.ProjectGrant{ProjectID: models.AllProjects, Access: models.AccessWrite})
+	rateLimits := service.NewRateLimitService(repository.NewMemoryCache(), service.RateLimits{
+		Address: models.RateLimit{Requests: 2, Period: time.Minute},
+	})
+	env := newTestEnvWithServices(t, service.NewAuthService(store, service.JWTOptions{}, 0), rateLimits)
+	unknown := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "unknown")
+	admin := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "admin-key")
+
+	// Лимит адреса проверяется до аутентификации
+	_, err := env.projects.GetProject(unknown, &goodspb.GetProjectRequest{Id: 1})
+	wantCode(t, err, codes.Unauthenticated)
+	if _, err := env.projects.CreateProject(admin, &goodspb.CreateProjectRequest{Name: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = env.projects.GetProject(unknown, &goodspb.GetProjectRequest{Id: 1})
+	wantCode(t, err, codes.ResourceExhausted)
+
+	_, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1})
+	wantCode(t, err, codes.ResourceExhausted)
+}
@@ -0,0 +1,78 @@
+package grpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+	"goods-service/internal/models"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log"
+)
+
+// listPageSize Количество записей, читаемых за раз при передаче списка в поток
+const listPageSize = 100
+
+// NewServer Создаёт сервер gRPC с сервисами товаров и проектов. Сервисы
+// работают с теми же GoodService и ProjectService, что и HTTP API.
+// authService nil отключает проверку клиента, rateLimitService nil - ограничение
+// частоты вызовов. Лимит адреса проверяется до аутентификации, лимиты клиента
+// и проекта - после проверки клиента и его доступа к проекту
+func NewServer(goodService *service.GoodService, projectService *service.ProjectService, authService *service.AuthService, rateLimitService *service.RateLimitService, opts ...grpc.ServerOption) *grpc.Server {
+	var limiter *rateLimitInterceptor
+	if rateLimitService != nil {
+		limiter = &rateLimitInterceptor{rateLimitService: rateLimitService}
+		opts = append(opts, grpc.ChainUnaryInterceptor(limiter.unaryAddress), grpc.ChainStreamInterceptor(limiter.streamAddress))
+	}
+	if authService != nil {
+		auth := &authInterceptor{authService: authService}
+		opts = append(opts, grpc.ChainUnaryInterceptor(auth.unary), grpc.ChainStreamInterceptor(auth.stream))
+	}
+	if limiter != nil {
+		opts = append(opts, grpc.ChainUnaryInterceptor(limiter.unary), grpc.ChainStreamInterceptor(limiter.stream))
+	}
+
+	srv := grpc.NewServer(opts...)
+	goodspb.RegisterGoodsServiceServer(srv, NewGoodsServer(goodService, projectService))
+	goodspb.RegisterProjectsServiceServer(srv, NewProjectsServer(projectService))
+
+	return srv
+}
+
+// statusError Преобразует ошибку сервиса в статус gRPC.
+// notFound - сообщение для models.ErrNotFound
+func statusError(err error, notFound string) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return status.Error(codes.NotFound, notFound)
+	case errors.Is(err, models.ErrDuplicateExternalKey):
+		return status.Error(codes.AlreadyExists, "External key already exists")
+	case errors.Is(err, models.ErrVersionMismatch):
+		return status.Error(codes.Aborted, "Good version mismatch")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	log.Printf("grpc: %v", err)
+	return status.Error(codes.Internal, "Internal server error")
+}
+
+// invalidArgument Ошибка некорректного запроса
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
+
+// validateId Проверяет идентификатор из запроса
+func validateId(name string, id int64) error {
+	if id <= 0 {
+		return invalidArgument(name + " must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,442 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"goods-service/internal/transport/grpc/goodspb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"testing"
+)
+
+// testEnv Сервер gRPC на хранилище в памяти и клиенты к нему
+type testEnv struct {
+	goods    goodspb.GoodsServiceClient
+	projects goodspb.ProjectsServiceClient
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	return newTestEnvWithAuth(t, nil)
+}
+
+func newTestEnvWithAuth(t *testing.T, authService *service.AuthService) *testEnv {
+	t.Helper()
+
+	return newTestEnvWithServices(t, authService, nil)
+}
+
+func newTestEnvWithServices(t *testing.T, authService *service.AuthService, rateLimitService *service.RateLimitService) *testEnv {
+	t.Helper()
+
+	repo := repository.NewMemoryRepository()
+	cache := repository.NewMemoryCache()
+	srv := NewServer(service.NewGoodService(repo, cache), service.NewProjectService(repo, cache), authService, rateLimitService)
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testEnv{
+		goods:    goodspb.NewGoodsServiceClient(conn),
+		projects: goodspb.NewProjectsServiceClient(conn),
+	}
+}
+
+// createGoods Создаёт товары в проекте 1 с приоритетами 1..n
+func (e *testEnv) createGoods(t *testing.T, names ...string) []*goodspb.Good {
+	t.Helper()
+
+	goods := make([]*goodspb.Good, 0, len(names))
+	for _, name := range names {
+		good, err := e.goods.CreateGood(context.Background(), &goodspb.CreateGoodRequest{ProjectId: 1, Name: name})
+		if err != nil {
+			t.Fatalf("failed to create good: %v", err)
+		}
+		goods = append(goods, good)
+	}
+
+	return goods
+}
+
+// listGoods Читает поток ListGoods целиком
+func (e *testEnv) listGoods(t *testing.T, req *goodspb.ListGoodsRequest) ([]*goodspb.Good, error) {
+	t.Helper()
+
+	stream, err := e.goods.ListGoods(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var goods []*goodspb.Good
+	for {
+		good, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return goods, nil
+		}
+		if err != nil {
+			return goods, err
+		}
+		goods = append(goods, good)
+	}
+}
+
+func wantCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+
+	if status.Code(err) != code {
+		t.Fatalf("expected code %s, got %v", code, err)
+	}
+}
+
+func goodNames(goods []*goodspb.Good) []string {
+	names := make([]string, 0, len(goods))
+	for _, good := range goods {
+		names = append(names, good.GetName())
+	}
+
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestGoodsServer_CreateAndGet(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	created, err := env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{
+		ProjectId:   1,
+		Name:        "first",
+		Description: "desc",
+		ExternalKey: "sku-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetId() == 0 || created.GetPriority() != 1 || created.GetVersion() != 1 || created.GetCreatedAt() == nil {
+		t.Errorf("unexpected created good %v", created)
+	}
+
+	good, err := env.goods.GetGood(ctx, &goodspb.GetGoodRequest{ProjectId: 1, Id: created.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if good.GetName() != "first" || good.GetDescription() != "desc" || good.GetExternalKey() != "sku-1" {
+		t.Errorf("unexpected good %v", good)
+	}
+
+	_, err = env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: 1, Name: "copy", ExternalKey: "sku-1"})
+	wantCode(t, err, codes.AlreadyExists)
+
+	_, err = env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: 1, Name: " "})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = env.goods.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: 100, Name: "first"})
+	wantCode(t, err, codes.NotFound)
+
+	_, err = env.goods.GetGood(ctx, &goodspb.GetGoodRequest{ProjectId: 1, Id: 100})
+	wantCode(t, err, codes.NotFound)
+
+	_, err = env.goods.GetGood(ctx, &goodspb.GetGoodRequest{ProjectId: 1})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestGoodsServer_ListGoods(t *testing.T) {
+	env := newTestEnv(t)
+
+	names := make([]string, 0, listPageSize+5)
+	for i := 0; i < listPageSize+5; i++ {
+		names = append(names, "good")
+	}
+	env.createGoods(t, names...)
+
+	goods, err := env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goods) != len(names) {
+		t.Fatalf("expected %d goods, got %d", len(names), len(goods))
+	}
+	for i, good := range goods {
+		if good.GetPriority() != int64(i+1) {
+			t.Fatalf("expected priority %d at %d, got %d", i+1, i, good.GetPriority())
+		}
+	}
+
+	from, to := int64(3), int64(5)
+	goods, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1, PriorityFrom: &from, PriorityTo: &to, Desc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goods) != 3 || goods[0].GetPriority() != 5 || goods[2].GetPriority() != 3 {
+		t.Errorf("unexpected filtered goods %v", goods)
+	}
+
+	goods, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1, Limit: listPageSize + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goods) != listPageSize+1 {
+		t.Errorf("expected %d goods, got %d", listPageSize+1, len(goods))
+	}
+
+	_, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 100})
+	wantCode(t, err, codes.NotFound)
+
+	_, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1, Limit: -1})
+	wantCode(t, err, codes.InvalidArgument)
+}
+
+func TestGoodsServer_UpdateGood(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	good := env.createGoods(t, "first")[0]
+
+	name := "renamed"
+	updated, err := env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{
+		ProjectId: 1,
+		Id:        good.GetId(),
+		Name:      &name,
+		Version:   good.GetVersion(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetName() != "renamed" || updated.GetVersion() != good.GetVersion()+1 {
+		t.Errorf("unexpected updated good %v", updated)
+	}
+
+	description := "desc"
+	_, err = env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{
+		ProjectId:   1,
+		Id:          good.GetId(),
+		Description: &description,
+		Version:     good.GetVersion(),
+	})
+	wantCode(t, err, codes.Aborted)
+
+	_, err = env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{ProjectId: 1, Id: good.GetId()})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{ProjectId: 1, Id: good.GetId(), Description: &description})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	_, err = env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{
+		ProjectId:   1,
+		Id:          good.GetId(),
+		Description: &description,
+		Version:     updated.GetVersion(),
+		AnyVersion:  true,
+	})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{
+		ProjectId:   1,
+		Id:          good.GetId(),
+		Description: &description,
+		AnyVersion:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.goods.UpdateGood(ctx, &goodspb.UpdateGoodRequest{ProjectId: 1, Id: 100, Name: &name, Version: 1})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestGoodsServer_RemoveAndRestore(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	goods := env.createGoods(t, "first", "second")
+
+	_, err := env.goods.RemoveGood(ctx, &goodspb.RemoveGoodRequest{ProjectId: 1, Id: goods[0].GetId(), Version: 100})
+	wantCode(t, err, codes.Aborted)
+
+	_, err = env.goods.RemoveGood(ctx, &goodspb.RemoveGoodRequest{ProjectId: 1, Id: goods[0].GetId()})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	_, err = env.goods.RemoveGood(ctx, &goodspb.RemoveGoodRequest{ProjectId: 1, Id: goods[0].GetId(), Version: -1})
+	wantCode(t, err, codes.InvalidArgument)
+
+	removed, err := env.goods.RemoveGood(ctx, &goodspb.RemoveGoodRequest{ProjectId: 1, Id: goods[0].GetId(), Version: goods[0].GetVersion()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed.GetRemoved() || removed.GetId() != goods[0].GetId() {
+		t.Errorf("unexpected response %v", removed)
+	}
+
+	listed, err := env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalStrings(goodNames(listed), []string{"second"}) {
+		t.Errorf("unexpected goods %v", goodNames(listed))
+	}
+
+	listed, err = env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1, IncludeRemoved: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 2 {
+		t.Errorf("expected 2 goods with removed, got %d", len(listed))
+	}
+
+	restored, err := env.goods.RestoreGood(ctx, &goodspb.RestoreGoodRequest{ProjectId: 1, Id: goods[0].GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetRemoved() || restored.GetRemovedAt() != nil || restored.GetPriority() != 3 {
+		t.Errorf("unexpected restored good %v", restored)
+	}
+
+	_, err = env.goods.RestoreGood(ctx, &goodspb.RestoreGoodRequest{ProjectId: 1, Id: 100})
+	wantCode(t, err, codes.NotFound)
+}
+
+func TestGoodsServer_ReprioritizeGood(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+	goods := env.createGoods(t, "first", "second", "third")
+
+	response, err := env.goods.ReprioritizeGood(ctx, &goodspb.ReprioritizeGoodRequest{
+		ProjectId: 1,
+		Id:        goods[2].GetId(),
+		Priority:  1,
+		Version:   goods[2].GetVersion(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.GetPriorities()) == 0 {
+		t.Fatal("expected changed priorities")
+	}
+
+	listed, err := env.listGoods(t, &goodspb.ListGoodsRequest{ProjectId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalStrings(goodNames(listed), []string{"third", "first", "second"}) {
+		t.Errorf("unexpected order %v", goodNames(listed))
+	}
+
+	_, err = env.goods.ReprioritizeGood(ctx, &goodspb.ReprioritizeGoodRequest{ProjectId: 1, Id: goods[0].GetId()})
+	wantCode(t, err, codes.InvalidArgument)
+
+	_, err = env.goods.ReprioritizeGood(ctx, &goodspb.ReprioritizeGoodRequest{ProjectId: 1, Id: goods[0].GetId(), Priority: 1})
+	wantCode(t, err, codes.FailedPrecondition)
+
+	_, err = env.goods.ReprioritizeGood(ctx, &goodspb.ReprioritizeGoodRequest{
+		ProjectId:  1,
+		Id:         goods[1].GetId(),
+		Priority:   1,
+		AnyVersion: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = env.goods.ReprioritizeGood(ctx, &goodspb.ReprioritizeGoodRequest{
+		ProjectId: 1,
+		Id:        goods[0].GetId(),
+		Priority:  1,
+		Version:   100,
+	})
+	wantCode(t, err, codes.Aborted)
+}
+
+func TestProjectsServer(t *testing.T) {
+	env := newTestEnv(t)
+	ctx := context.Background()
+
+	created, err := env.projects.CreateProject(ctx, &goodspb.CreateProjectRequest{Name: " second "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetName() != "second" || created.GetId() == 0 {
+		t.Errorf("unexpected created project %v", created)
+	}
+
+	_, err = env.projects.CreateProject(ctx, &goodspb.CreateProjectRequest{})
+	wantCode(t, err, codes.InvalidArgument)
+
+	renamed, err := env.projects.UpdateProject(ctx, &goodspb.UpdateProjectRequest{Id: created.GetId(), Name: "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.GetName() != "renamed" {
+		t.Errorf("unexpected renamed project %v", renamed)
+	}
+
+	project, err := env.projects.GetProject(ctx, &goodspb.GetProjectRequest{Id: created.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.GetName() != "renamed" {
+		t.Errorf("unexpected project %v", project)
+	}
+
+	stream, err := env.projects.ListProjects(ctx, &goodspb.ListProjectsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("expected 2 projects, got %d", count)
+	}
+
+	env.createGoods(t, "first")
+	removed, err := env.projects.RemoveProject(ctx, &goodspb.RemoveProjectRequest{Id: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed.GetRemoved() || removed.GetRemovedGoods() != 1 {
+		t.Errorf("unexpected response %v", removed)
+	}
+
+	_, err = env.projects.GetProject(ctx, &goodspb.GetProjectRequest{Id: 1})
+	wantCode(t, err, codes.NotFound)
+
+	_, err = env.projects.UpdateProject(ctx, &goodspb.UpdateProjectRequest{Id: 100, Name: "renamed"})
+	wantCode(t, err, codes.NotFound)
+}
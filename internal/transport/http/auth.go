@@ -0,0 +1,133 @@
+package http
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"goods-service/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// publicPaths Маршруты, доступные без аутентификации
+var publicPaths = map[string]bool{
+	"/api/openapi.json": true,
+}
+
+// authenticate Определяет клиента по заголовку X-API-Key или Authorization: Bearer
+// и передаёт его обработчику в контексте запроса. Запросы без учётных данных
+// или с непрошедшими проверку данными отклоняются с 401
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.authService == nil || publicPaths[routePath(r)] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := h.identify(r)
+		if err != nil {
+			if !errors.Is(err, models.ErrUnauthenticated) {
+				log.Printf("Failed to authenticate request: %v", err)
+				respondWithAuthError(w, r, errInternal)
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="goods-service"`)
+			respondWithAuthError(w, r, errUnauthenticated)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(models.WithIdentity(r.Context(), identity)))
+	})
+}
+
+// identify Проверяет учётные данные запроса. API ключ имеет приоритет над токеном
+func (h *Handler) identify(r *http.Request) (*models.Identity, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return h.authService.AuthenticateAPIKey(r.Context(), key)
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && token != "" {
+		return h.authService.AuthenticateToken(r.Context(), strings.TrimSpace(token))
+	}
+
+	return nil, models.ErrUnauthenticated
+}
+
+// authorize Проверяет доступ клиента к проекту запроса: GET - на чтение,
+// остальные методы - на изменение. Проект берётся из пути или параметра projectId,
+// запросы без проекта, например список и создание проектов, требуют доступа
+// ко всем проектам. Выполняется после проверки запроса по спецификации,
+// поэтому projectId уже проверен
+func (h *Handler) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity := models.IdentityFromContext(r.Context())
+		if identity == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		projectId := requestProjectId(r)
+		allowed := identity.CanWrite(projectId)
+		if isReadRequest(r) {
+			allowed = identity.CanRead(projectId)
+		}
+		if !allowed {
+			respondWithAuthError(w, r, errForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestProjectId Проект запроса из пути или параметра projectId,
+// models.AllProjects для запросов без проекта
+func requestProjectId(r *http.Request) int {
+	projectId := models.AllProjects
+	if value, ok := mux.Vars(r)["projectId"]; ok {
+		projectId, _ = strconv.Atoi(value)
+	} else if value := r.URL.Query().Get("projectId"); value != "" {
+		projectId, _ = strconv.Atoi(value)
+	}
+
+	return projectId
+}
+
+// isReadRequest Проверяет, что запрос только читает данные
+func isReadRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// routePath Шаблон пути маршрута запроса
+func routePath(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	path, _ := route.GetPathTemplate()
+	return path
+}
+
+// respondWithAuthError Отправляет ошибку аутентификации, доступа или лимита запросов
+// в формате версии API
+func respondWithAuthError(w http.ResponseWriter, r *http.Request, apiErr *APIError) {
+	if strings.HasPrefix(r.URL.Path, "/api/v2/") {
+		respondWithAPIError(w, apiErr)
+		return
+	}
+
+	switch apiErr {
+	case errUnauthenticated:
+		respondWithError(w, apiErr.Status, 10, "errors.common.unauthenticated")
+	case errForbidden:
+		respondWithError(w, apiErr.Status, 11, "errors.common.forbidden")
+	case errRateLimited:
+		respondWithError(w, apiErr.Status, 12, "errors.common.rateLimited")
+	default:
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+	}
+}
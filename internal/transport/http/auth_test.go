@@ -0,0 +1,228 @@
+package http
+
+import (
+	"encoding/base64"
+	"github.com/golang-jwt/jwt/v5"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"net/http"
+	"testing"
+	"time"
+)
+
+var testJWTSecret = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestAuthService Создаёт сервис с клиентами admin (изменение всех проектов),
+// editor (изменение проекта 1) и reader (чтение проекта 1)
+func newTestAuthService(t *testing.T) *service.AuthService {
+	t.Helper()
+
+	jwks, err := service.ParseJWKS([]byte(`{"keys":[{"kty":"oct","alg":"HS256","k":"` +
+		base64.RawURLEncoding.EncodeToString(testJWTSecret) + `"}]}`))
+	if err != nil {
+		t.Fatalf("failed to parse JWKS: %v", err)
+	}
+
+	store := repository.NewMemoryAuthStore()
+	for subject, grant := range map[string]models.ProjectGrant{
+		"admin":  {ProjectID: models.AllProjects, Access: models.AccessWrite},
+		"editor": {ProjectID: 1, Access: models.AccessWrite},
+		"reader": {ProjectID: 1, Access: models.AccessRead},
+	} {
+		store.AddAPIKey(&models.APIKey{Name: subject, Subject: subject}, service.HashAPIKey(subject+"-key"))
+		store.GrantProject(subject, grant)
+	}
+
+	return service.NewAuthService(store, service.JWTOptions{Keys: jwks}, 0)
+}
+
+func apiKey(subject string) map[string]string {
+	return map[string]string{"X-API-Key": subject + "-key"}
+}
+
+func bearer(t *testing.T, subject string) map[string]string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(testJWTSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestHandler_Auth(t *testing.T) {
+	env := newTestEnvWithAuth(t, newTestAuthService(t))
+	env.createGoods(t, "first")
+
+	challenge := map[string]string{"WWW-Authenticate": `Bearer realm="goods-service"`}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "no credentials",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   10,
+			wantHeader: challenge,
+		},
+		{
+			name:       "no credentials v2",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods/1",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  CodeUnauthenticated,
+			wantHeader: challenge,
+		},
+		{
+			name:       "unknown key",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			header:     apiKey("unknown"),
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   10,
+		},
+		{
+			name:       "invalid token",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			header:     map[string]string{"Authorization": "Bearer invalid"},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   10,
+		},
+		{
+			name:       "specification is public",
+			method:     http.MethodGet,
+			target:     "/api/openapi.json",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "read with read access",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			header:     apiKey("reader"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "write with read access",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"second"}`,
+			header:     apiKey("reader"),
+			wantStatus: http.StatusForbidden,
+			wantCode:   11,
+		},
+		{
+			name:       "write with read access v2",
+			method:     http.MethodDelete,
+			target:     "/api/v2/projects/1/goods/1",
+			header:     map[string]string{"X-API-Key": "reader-key", "If-Match": `"1"`},
+			wantStatus: http.StatusForbidden,
+			wantError:  CodeForbidden,
+		},
+		{
+			name:       "other project",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/2/goods",
+			header:     apiKey("editor"),
+			wantStatus: http.StatusForbidden,
+			wantError:  CodeForbidden,
+		},
+		{
+			name:       "project list requires access to all projects",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/list",
+			header:     apiKey("editor"),
+			wantStatus: http.StatusForbidden,
+			wantCode:   11,
+		},
+		{
+			name:       "project list",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/list",
+			header:     apiKey("admin"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "write with token",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"second"}`,
+			header:     bearer(t, "editor"),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "token of client without access",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods",
+			header:     bearer(t, "unknown"),
+			wantStatus: http.StatusForbidden,
+			wantError:  CodeForbidden,
+		},
+	})
+}
+
+func TestHandler_AuthActor(t *testing.T) {
+	env := newTestEnvWithAuth(t, newTestAuthService(t))
+
+	env.run(t, []handlerCase{
+		{
+			name:       "create with key",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"first"}`,
+			header:     apiKey("editor"),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "rename with token",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"renamed"}`,
+			header:     map[string]string{"Authorization": bearer(t, "editor")["Authorization"], "If-Match": `"1"`},
+			wantStatus: http.StatusOK,
+		},
+	})
+	env.deliverEvents(t)
+
+	env.run(t, []handlerCase{
+		{
+			name:       "history records actors",
+			method:     http.MethodGet,
+			target:     "/api/v1/good/history?projectId=1&id=1",
+			header:     apiKey("reader"),
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp HistoryResponse
+				decode(t, body, &resp)
+				if len(resp.Events) != 2 ||
+					resp.Events[0].Actor != "api_key:editor" || resp.Events[1].Actor != "jwt:editor" {
+					t.Errorf("unexpected events %+v", resp.Events)
+				}
+			},
+		},
+	})
+}
+
+func TestHandler_AuthIdempotency(t *testing.T) {
+	env := newTestEnvWithAuth(t, newTestAuthService(t))
+
+	// Ключи идемпотентности разных клиентов не пересекаются
+	for _, subject := range []string{"editor", "admin"} {
+		env.run(t, []handlerCase{
+			{
+				name:       "create as " + subject,
+				method:     http.MethodPost,
+				target:     "/api/v1/good/create?projectId=1",
+				body:       `{"name":"` + subject + `"}`,
+				header:     map[string]string{"X-API-Key": subject + "-key", "Idempotency-Key": "create-1"},
+				wantStatus: http.StatusCreated,
+				wantHeader: map[string]string{"Idempotent-Replayed": ""},
+			},
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goods-service/internal/models"
+	"net/http"
+)
+
+// maxBatchSize Максимальное количество операций в одном пакетном запросе
+const maxBatchSize = 1000
+
+// maxGoodNameLength Максимальная длина названия товара
+const maxGoodNameLength = 255
+
+// maxExternalKeyLength Максимальная длина внешнего ключа товара
+const maxExternalKeyLength = 255
+
+// BatchRequest Пакет операций над товарами проекта
+type BatchRequest struct {
+	Atomic bool          `json:"atomic"` // Пакет применяется только целиком
+	Items  []models.Good `json:"items"`
+
+	fields []map[string]json.RawMessage // Поля элементов в том виде, в котором переданы
+}
+
+// BatchResponse Результаты операций в порядке элементов запроса
+type BatchResponse struct {
+	Applied bool              `json:"applied"` // false - атомарный пакет отменён, изменения не сохранены
+	Results []BatchItemResult `json:"results"`
+}
+
+// BatchItemResult Результат операции над элементом пакета: товар после
+// изменения либо ошибка
+type BatchItemResult struct {
+	Good  *models.Good   `json:"good,omitempty"`
+	Error *ErrorResponse `json:"error,omitempty"`
+}
+
+// CreateGoods Создаёт пачку товаров проекта. Приоритеты назначаются в порядке элементов
+func (h *Handler) CreateGoods(w http.ResponseWriter, r *http.Request) {
+	projectId, req, ok := decodeBatchRequest(w, r)
+	if !ok {
+		return
+	}
+
+	results := make([]BatchItemResult, len(req.Items))
+	keys := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		switch {
+		case item.Name == "":
+			results[i].Error = itemError(4, "Good name is required")
+		case len([]rune(item.Name)) > maxGoodNameLength:
+			results[i].Error = itemError(4, "Good name is too long")
+		case len([]rune(item.ExternalKey)) > maxExternalKeyLength:
+			results[i].Error = itemError(4, "External key is too long")
+		case item.ExternalKey != "" && keys[item.ExternalKey]:
+			results[i].Error = itemError(4, "Duplicate external key")
+		}
+		keys[item.ExternalKey] = true
+	}
+
+	valid, ok := validBatchItems(w, req, results)
+	if !ok {
+		return
+	}
+
+	goods := make([]models.Good, 0, len(valid))
+	for _, i := range valid {
+		goods = append(goods, req.Items[i])
+	}
+
+	errs, err := h.goodService.CreateGoods(r.Context(), projectId, goods, req.Atomic)
+	if errors.Is(err, models.ErrNotFound) {
+		respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+		return
+	}
+	if errors.Is(err, models.ErrDuplicateExternalKey) {
+		respondWithError(w, http.StatusBadRequest, 4, "External key already exists")
+		return
+	}
+	if err != nil && !errors.Is(err, models.ErrBatchAborted) {
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	applied := err == nil
+	for j, i := range valid {
+		switch {
+		case errors.Is(errs[j], models.ErrDuplicateExternalKey):
+			results[i].Error = itemError(4, "External key already exists")
+		case errs[j] != nil:
+			results[i].Error = itemError(5, "Internal server error")
+		case applied:
+			results[i].Good = &goods[j]
+		}
+	}
+
+	if !applied {
+		respondWithJSON(w, http.StatusBadRequest, BatchResponse{Applied: false, Results: results})
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, BatchResponse{Applied: true, Results: results})
+}
+
+// UpdateGoods Частично обновляет пачку товаров проекта: как и в UpdateGood,
+// меняются только переданные в элементе name и description
+func (h *Handler) UpdateGoods(w http.ResponseWriter, r *http.Request) {
+	patches := make(map[int]models.GoodPatch)
+	h.changeGoods(w, r, func(req BatchRequest, i int) *ErrorResponse {
+		patch, err := parseGoodPatch(req.fields[i])
+		if err != nil {
+			return itemError(4, err.Error())
+		}
+
+		patches[i] = patch
+		return nil
+	}, func(ctx context.Context, projectID int, goods []models.Good, items []int, atomic bool) ([]error, error) {
+		goodPatches := make([]models.GoodPatch, 0, len(items))
+		for _, i := range items {
+			goodPatches = append(goodPatches, patches[i])
+		}
+
+		return h.goodService.UpdateGoods(ctx, projectID, goods, goodPatches, atomic)
+	})
+}
+
+// DeleteGoods Отмечает удалёнными пачку товаров проекта
+func (h *Handler) DeleteGoods(w http.ResponseWriter, r *http.Request) {
+	h.changeGoods(w, r, nil, func(ctx context.Context, projectID int, goods []models.Good, _ []int, atomic bool) ([]error, error) {
+		return h.goodService.DeleteGoods(ctx, projectID, goods, atomic)
+	})
+}
+
+// changeGoods Проверяет id элементов пакета и применяет к ним операцию change.
+// check дополнительно проверяет элемент i, change получает товары прошедших
+// проверку элементов и их индексы в пакете. Каждый элемент содержит версию
+// товара и изменяется, только если товар хранится в этой версии
+func (h *Handler) changeGoods(
+	w http.ResponseWriter,
+	r *http.Request,
+	check func(req BatchRequest, i int) *ErrorResponse,
+	change func(ctx context.Context, projectID int, goods []models.Good, items []int, atomic bool) ([]error, error),
+) {
+	projectId, req, ok := decodeBatchRequest(w, r)
+	if !ok {
+		return
+	}
+
+	results := make([]BatchItemResult, len(req.Items))
+	seen := make(map[int]bool, len(req.Items))
+	for i, item := range req.Items {
+		switch {
+		case item.ID <= 0:
+			results[i].Error = itemError(4, "Invalid good ID")
+		case seen[item.ID]:
+			results[i].Error = itemError(4, "Duplicate good ID")
+		case item.Version == 0:
+			results[i].Error = itemError(4, "Good version is required")
+		case item.Version < 0:
+			results[i].Error = itemError(4, "Invalid good version")
+		case check != nil:
+			results[i].Error = check(req, i)
+		}
+		seen[item.ID] = true
+	}
+
+	valid, ok := validBatchItems(w, req, results)
+	if !ok {
+		return
+	}
+
+	goods := make([]models.Good, 0, len(valid))
+	for _, i := range valid {
+		goods = append(goods, req.Items[i])
+		goods[len(goods)-1].ProjectID = projectId
+	}
+
+	errs, err := change(r.Context(), projectId, goods, valid, req.Atomic)
+	if err != nil && !errors.Is(err, models.ErrBatchAborted) {
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	applied := err == nil
+	for j, i := range valid {
+		switch {
+		case errors.Is(errs[j], models.ErrNotFound):
+			results[i].Error = itemError(3, "errors.common.notFound")
+		case errors.Is(errs[j], models.ErrVersionMismatch):
+			results[i].Error = itemError(6, "errors.common.versionMismatch")
+		case errs[j] != nil:
+			results[i].Error = itemError(5, "Internal server error")
+		case applied:
+			results[i].Good = &goods[j]
+		}
+	}
+
+	status := http.StatusOK
+	if !applied {
+		status = http.StatusBadRequest
+	}
+	respondWithJSON(w, status, BatchResponse{Applied: applied, Results: results})
+}
+
+// decodeBatchRequest Извлекает проект и пакет операций из запроса. При ошибке
+// отправляет ответ и возвращает ok = false
+func decodeBatchRequest(w http.ResponseWriter, r *http.Request) (projectId int, req BatchRequest, ok bool) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return 0, req, false
+	}
+
+	var body struct {
+		Atomic bool              `json:"atomic"`
+		Items  []json.RawMessage `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+		return 0, req, false
+	}
+
+	req.Atomic = body.Atomic
+	req.Items = make([]models.Good, len(body.Items))
+	req.fields = make([]map[string]json.RawMessage, len(body.Items))
+	for i, raw := range body.Items {
+		if json.Unmarshal(raw, &req.Items[i]) != nil || json.Unmarshal(raw, &req.fields[i]) != nil || req.fields[i] == nil {
+			respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+			return 0, req, false
+		}
+	}
+
+	if len(req.Items) == 0 {
+		respondWithError(w, http.StatusBadRequest, 4, "Items are required")
+		return 0, req, false
+	}
+	if len(req.Items) > maxBatchSize {
+		respondWithError(w, http.StatusBadRequest, 4, fmt.Sprintf("Batch size must not exceed %d", maxBatchSize))
+		return 0, req, false
+	}
+
+	return projectId, req, true
+}
+
+// validBatchItems Возвращает индексы элементов без ошибок проверки. Если пакет
+// атомарный и содержит ошибки, отправляет ответ и возвращает ok = false
+func validBatchItems(w http.ResponseWriter, req BatchRequest, results []BatchItemResult) ([]int, bool) {
+	valid := make([]int, 0, len(results))
+	for i := range results {
+		if results[i].Error == nil {
+			valid = append(valid, i)
+		}
+	}
+
+	if req.Atomic && len(valid) < len(results) {
+		respondWithJSON(w, http.StatusBadRequest, BatchResponse{Applied: false, Results: results})
+		return nil, false
+	}
+
+	return valid, true
+}
+
+// itemError Ошибка элемента пакета в формате respondWithError
+func itemError(code int, message string) *ErrorResponse {
+	return &ErrorResponse{
+		Code:    code,
+		Message: message,
+		Details: struct{}{},
+	}
+}
@@ -0,0 +1,366 @@
+package http
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// batchResults Возвращает товары и коды ошибок элементов пакета, 0 - элемент без ошибки
+func batchResults(t *testing.T, body []byte) (BatchResponse, []int) {
+	t.Helper()
+
+	var resp BatchResponse
+	decode(t, body, &resp)
+
+	codes := make([]int, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		if result.Error != nil {
+			codes = append(codes, result.Error.Code)
+		} else {
+			codes = append(codes, 0)
+		}
+	}
+	return resp, codes
+}
+
+func TestHandler_CreateGoods(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "existing")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "priorities in request order",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"items":[{"name":"first"},{"name":"second","description":"desc"},{"name":"third"}]}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !resp.Applied || !equalInts(codes, []int{0, 0, 0}) {
+					t.Fatalf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+				for i, result := range resp.Results {
+					if result.Good.ID == 0 || result.Good.ProjectID != 1 || result.Good.Priority != i+2 {
+						t.Errorf("unexpected good %d: %+v", i, result.Good)
+					}
+				}
+			},
+		},
+		{
+			name:       "invalid items are reported separately",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"items":[{"name":""},{"name":"fifth"},{"name":"` + strings.Repeat("a", 256) + `"}]}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !resp.Applied || !equalInts(codes, []int{4, 0, 4}) {
+					t.Fatalf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+				if resp.Results[1].Good.Priority != 5 {
+					t.Errorf("expected valid item to get priority 5, got %+v", resp.Results[1].Good)
+				}
+			},
+		},
+		{
+			name:       "atomic batch with invalid item",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"atomic":true,"items":[{"name":"sixth"},{"name":""}]}`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if resp.Applied || !equalInts(codes, []int{0, 4}) || resp.Results[0].Good != nil {
+					t.Errorf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+			},
+		},
+		{
+			name:       "unknown project",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=100",
+			body:       `{"items":[{"name":"orphan"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "empty batch",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"items":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "batch too large",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"items":[` + strings.Repeat(`{"name":"a"},`, maxBatchSize) + `{"name":"a"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+
+	total, _, err := env.repo.CountGoods(context.Background(), models.GoodsFilter{ProjectID: 1})
+	if err != nil || total != 5 {
+		t.Errorf("expected 5 goods after batches, got %d, err=%v", total, err)
+	}
+}
+
+func TestHandler_CreateGoodsExistingExternalKey(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.repo.CreateGoods(context.Background(), 1, []models.Good{{ExternalKey: "sku-1", Name: "keyed"}}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "existing key is reported per item",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"items":[{"externalKey":"sku-1","name":"again"},{"externalKey":"sku-2","name":"second"},{"name":"third"}]}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !resp.Applied || !equalInts(codes, []int{4, 0, 0}) {
+					t.Fatalf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+				if message := resp.Results[0].Error.Message; message != "External key already exists" {
+					t.Errorf("unexpected error message %q", message)
+				}
+				if good := resp.Results[1].Good; good.ExternalKey != "sku-2" || good.Priority != 2 {
+					t.Errorf("unexpected created good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "atomic batch with existing key",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/batch/create?projectId=1",
+			body:       `{"atomic":true,"items":[{"externalKey":"sku-3","name":"fourth"},{"externalKey":"sku-2","name":"again"}]}`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if resp.Applied || !equalInts(codes, []int{0, 4}) || resp.Results[0].Good != nil {
+					t.Errorf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+			},
+		},
+	})
+
+	total, _, err := env.repo.CountGoods(context.Background(), models.GoodsFilter{ProjectID: 1})
+	if err != nil || total != 3 {
+		t.Errorf("expected 3 goods after batches, got %d, err=%v", total, err)
+	}
+}
+
+func TestHandler_UpdateGoods(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second", "third")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "per item results",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=1",
+			body:       `{"items":[{"id":1,"name":"renamed","version":1},{"id":100,"name":"missing","version":1},{"id":1,"name":"again","version":1},{"id":0,"version":1},{"id":2,"name":"unversioned"}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !resp.Applied || !equalInts(codes, []int{0, 3, 4, 4, 4}) {
+					t.Fatalf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+				if good := resp.Results[0].Good; good.Name != "renamed" || good.Priority != 1 {
+					t.Errorf("unexpected updated good: %+v", good)
+				}
+				if message := resp.Results[4].Error.Message; message != "Good version is required" {
+					t.Errorf("unexpected error message %q", message)
+				}
+			},
+		},
+		{
+			name:       "atomic batch with unknown good",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=1",
+			body:       `{"atomic":true,"items":[{"id":2,"name":"changed","version":1},{"id":100,"name":"missing","version":1}]}`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if resp.Applied || !equalInts(codes, []int{0, 3}) || resp.Results[0].Good != nil {
+					t.Errorf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+			},
+		},
+		{
+			name:       "stale item version",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=1",
+			body:       `{"items":[{"id":1,"name":"stale","version":1},{"id":3,"name":"fresh","version":1}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !equalInts(codes, []int{6, 0}) || resp.Results[1].Good.Version != 2 {
+					t.Errorf("unexpected results: codes=%v, %+v", codes, resp.Results)
+				}
+			},
+		},
+		{
+			name:       "good of another project",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=2",
+			body:       `{"items":[{"id":3,"name":"moved","version":2}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				if _, codes := batchResults(t, body); !equalInts(codes, []int{3}) {
+					t.Errorf("expected not found, got codes %v", codes)
+				}
+			},
+		},
+	})
+
+	// Отменённый атомарный пакет ничего не меняет
+	good, err := env.repo.GetGood(context.Background(), 2, 1)
+	if err != nil || good.Name != "second" {
+		t.Errorf("expected good 2 to keep its name, got %+v, err=%v", good, err)
+	}
+}
+
+func TestHandler_UpdateGoodsPatch(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second")
+	description := "desc"
+	if _, err := env.repo.UpdateGood(context.Background(), &models.Good{ID: 1, ProjectID: 1}, models.GoodPatch{Description: &description}); err != nil {
+		t.Fatal(err)
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "invalid names",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=1",
+			body:       `{"items":[{"id":1,"version":2},{"id":2,"name":"","version":1},{"id":3,"name":"  ","version":1},{"id":4,"name":"` + strings.Repeat("a", maxGoodNameLength+1) + `","version":1}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !equalInts(codes, []int{4, 4, 4, 4}) {
+					t.Fatalf("unexpected results: codes=%v", codes)
+				}
+				if message := resp.Results[1].Error.Message; message != "Good name is required" {
+					t.Errorf("unexpected error message %q", message)
+				}
+			},
+		},
+		{
+			name:       "omitted fields are kept",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=1",
+			body:       `{"items":[{"id":1,"name":"renamed","version":2},{"id":2,"description":"second desc","version":1}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !equalInts(codes, []int{0, 0}) {
+					t.Fatalf("unexpected results: codes=%v", codes)
+				}
+				if good := resp.Results[0].Good; good.Name != "renamed" || good.Description != "desc" || good.Version != 3 {
+					t.Errorf("unexpected first good: %+v", good)
+				}
+				if good := resp.Results[1].Good; good.Name != "second" || good.Description != "second desc" || good.Version != 2 {
+					t.Errorf("unexpected second good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "null description and unchanged good",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/batch/update?projectId=1",
+			body:       `{"items":[{"id":1,"description":null,"version":3},{"id":2,"name":"second","version":2}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !equalInts(codes, []int{0, 0}) {
+					t.Fatalf("unexpected results: codes=%v", codes)
+				}
+				if good := resp.Results[0].Good; good.Name != "renamed" || good.Description != "" || good.Version != 4 {
+					t.Errorf("unexpected first good: %+v", good)
+				}
+				// Изменение без новых значений не увеличивает версию
+				if good := resp.Results[1].Good; good.Name != "second" || good.Version != 2 {
+					t.Errorf("unexpected second good: %+v", good)
+				}
+			},
+		},
+	})
+}
+
+func TestHandler_DeleteGoods(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second", "third")
+	env.deliverEvents(t)
+
+	env.run(t, []handlerCase{
+		{
+			name:       "atomic batch with unknown good",
+			method:     http.MethodDelete,
+			target:     "/api/v1/goods/batch/remove?projectId=1",
+			body:       `{"atomic":true,"items":[{"id":1,"version":1},{"id":100,"version":1}]}`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				if resp, codes := batchResults(t, body); resp.Applied || !equalInts(codes, []int{0, 3}) {
+					t.Errorf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+			},
+		},
+		{
+			name:       "atomic batch",
+			method:     http.MethodDelete,
+			target:     "/api/v1/goods/batch/remove?projectId=1",
+			body:       `{"atomic":true,"items":[{"id":1,"version":1},{"id":3,"version":1}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, codes := batchResults(t, body)
+				if !resp.Applied || !equalInts(codes, []int{0, 0}) {
+					t.Fatalf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+				for _, result := range resp.Results {
+					if !result.Good.Removed || result.Good.RemovedAt == nil {
+						t.Errorf("expected removed good, got %+v", result.Good)
+					}
+				}
+			},
+		},
+		{
+			name:       "item without version",
+			method:     http.MethodDelete,
+			target:     "/api/v1/goods/batch/remove?projectId=1",
+			body:       `{"items":[{"id":2},{"id":3,"version":0}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				if resp, codes := batchResults(t, body); !resp.Applied || !equalInts(codes, []int{4, 4}) {
+					t.Errorf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+			},
+		},
+		{
+			name:       "already removed good",
+			method:     http.MethodDelete,
+			target:     "/api/v1/goods/batch/remove?projectId=1",
+			body:       `{"items":[{"id":1,"version":2},{"id":2,"version":1}]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				if resp, codes := batchResults(t, body); !resp.Applied || !equalInts(codes, []int{3, 0}) {
+					t.Errorf("unexpected results: applied=%t, codes=%v", resp.Applied, codes)
+				}
+			},
+		},
+	})
+
+	// Событие удаления записывается один раз на каждый удалённый товар
+	env.deliverEvents(t)
+	events, err := env.eventLog.CountHistory(context.Background(), models.HistoryFilter{ProjectID: 1})
+	if err != nil || events != 6 {
+		t.Errorf("expected 3 created and 3 deleted events, got %d, err=%v", events, err)
+	}
+}
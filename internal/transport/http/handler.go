@@ -5,8 +5,11 @@ import (
 	"errors"
 	"goods-service/internal/models"
 	"goods-service/internal/service"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type ErrorResponse struct {
@@ -16,11 +19,30 @@ type ErrorResponse struct {
 }
 
 type Handler struct {
-	goodService *service.GoodService
+	goodService        *service.GoodService
+	projectService     *service.ProjectService
+	historyService     *service.HistoryService
+	idempotencyService *service.IdempotencyService
+	authService        *service.AuthService      // nil - аутентификация отключена
+	rateLimitService   *service.RateLimitService // nil - частота запросов не ограничивается
 }
 
-func NewHandler(goodService *service.GoodService) *Handler {
-	return &Handler{goodService: goodService}
+func NewHandler(
+	goodService *service.GoodService,
+	projectService *service.ProjectService,
+	historyService *service.HistoryService,
+	idempotencyService *service.IdempotencyService,
+	authService *service.AuthService,
+	rateLimitService *service.RateLimitService,
+) *Handler {
+	return &Handler{
+		goodService:        goodService,
+		projectService:     projectService,
+		historyService:     historyService,
+		idempotencyService: idempotencyService,
+		authService:        authService,
+		rateLimitService:   rateLimitService,
+	}
 }
 
 func (h *Handler) CreateGood(w http.ResponseWriter, r *http.Request) {
@@ -38,14 +60,32 @@ func (h *Handler) CreateGood(w http.ResponseWriter, r *http.Request) {
 
 	good.ProjectID = projectId
 
+	if len([]rune(good.ExternalKey)) > maxExternalKeyLength {
+		respondWithError(w, http.StatusBadRequest, 4, "External key is too long")
+		return
+	}
+
 	if err := h.goodService.CreateGood(r.Context(), &good); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+		if errors.Is(err, models.ErrDuplicateExternalKey) {
+			respondWithError(w, http.StatusBadRequest, 4, "External key already exists")
+			return
+		}
+
 		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
 		return
 	}
 
+	setETag(w, &good)
 	respondWithJSON(w, http.StatusCreated, good)
 }
 
+// UpdateGood Частично обновляет товар по JSON merge patch (RFC 7396): меняются
+// только переданные name и description, description: null очищает описание.
+// Требует заголовок If-Match с ETag товара, полученным при его чтении
 func (h *Handler) UpdateGood(w http.ResponseWriter, r *http.Request) {
 	id, err := getId(r)
 	if err != nil {
@@ -59,25 +99,39 @@ func (h *Handler) UpdateGood(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var good models.Good
-	if err := json.NewDecoder(r.Body).Decode(&good); err != nil {
-		respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
 
-	good.ID = id
-	good.ProjectID = projectId
+	if !isMergePatch(r) {
+		respondWithError(w, http.StatusUnsupportedMediaType, 4, "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	patch, err := decodeGoodPatch(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
 
-	if err := h.goodService.UpdateGood(r.Context(), &good); err != nil {
+	good := models.Good{ID: id, ProjectID: projectId, Version: version}
+
+	if err := h.goodService.UpdateGood(r.Context(), &good, patch); err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
 			return
 		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			respondWithError(w, http.StatusPreconditionFailed, 6, "errors.common.versionMismatch")
+			return
+		}
 
 		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
 		return
 	}
 
+	setETag(w, &good)
 	respondWithJSON(w, http.StatusOK, good)
 }
 
@@ -100,6 +154,7 @@ func (h *Handler) GetGood(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, good)
 	respondWithJSON(w, http.StatusOK, good)
 }
 
@@ -109,6 +164,7 @@ type DeleteResponse struct {
 	Removed    bool `json:"removed"`
 }
 
+// DeleteGood Отмечает товар удалённым. Требует заголовок If-Match с ETag товара
 func (h *Handler) DeleteGood(w http.ResponseWriter, r *http.Request) {
 	id, err := getId(r)
 	if err != nil {
@@ -122,13 +178,22 @@ func (h *Handler) DeleteGood(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.goodService.DeleteGood(r.Context(), id, projectId); err != nil {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.goodService.DeleteGood(r.Context(), id, projectId, version); err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
 			return
 		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			respondWithError(w, http.StatusPreconditionFailed, 6, "errors.common.versionMismatch")
+			return
+		}
 
-		respondWithError(w, http.StatusInternalServerError, 4, "Internal server error")
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
 		return
 	}
 
@@ -141,6 +206,36 @@ func (h *Handler) DeleteGood(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, deleteResponse)
 }
 
+func (h *Handler) RestoreGood(w http.ResponseWriter, r *http.Request) {
+	id, err := getId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid good ID")
+		return
+	}
+
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	good, err := h.goodService.RestoreGood(r.Context(), id, projectId)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	setETag(w, good)
+	respondWithJSON(w, http.StatusOK, good)
+}
+
+// ReprioritizeGood Перемещает товар на новую позицию. Требует заголовок If-Match
+// с ETag товара. Если приоритет товара изменился, новый ETag передаётся в ответе
 func (h *Handler) ReprioritizeGood(w http.ResponseWriter, r *http.Request) {
 	id, err := getId(r)
 	if err != nil {
@@ -154,6 +249,11 @@ func (h *Handler) ReprioritizeGood(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		NewPriority int `json:"newPriority"`
 	}
@@ -167,63 +267,150 @@ func (h *Handler) ReprioritizeGood(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	goods, err := h.goodService.ReprioritizeGood(r.Context(), id, projectId, req.NewPriority)
+	goods, err := h.goodService.ReprioritizeGood(r.Context(), id, projectId, req.NewPriority, version)
 	if err != nil {
 		if errors.Is(err, models.ErrNotFound) {
 			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
 			return
 		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			respondWithError(w, http.StatusPreconditionFailed, 6, "errors.common.versionMismatch")
+			return
+		}
 
 		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
 		return
 	}
 
+	for _, item := range goods.Priorities {
+		if item.ID == id {
+			setETag(w, &models.Good{Version: item.Version})
+		}
+	}
 	respondWithJSON(w, http.StatusOK, goods)
 }
 
+// ReorderGoods Меняет порядок товаров проекта полным списком id или списком перемещений
+func (h *Handler) ReorderGoods(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	var req models.GoodsReorder
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+		return
+	}
+
+	if err := validateGoodsReorder(req); err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	priorities, err := h.goodService.ReorderGoods(r.Context(), projectId, req)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidReorder) {
+			respondWithError(w, http.StatusBadRequest, 4, "Ids must list every active good of the project exactly once")
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, priorities)
+}
+
+// validateGoodsReorder Проверяет запрос изменения порядка товаров
+func validateGoodsReorder(req models.GoodsReorder) error {
+	if (len(req.IDs) == 0) == (len(req.Moves) == 0) {
+		return newFieldError("ids", FieldCodeRequired, "Either ids or moves must be provided")
+	}
+	for i, id := range req.IDs {
+		if id < 1 {
+			return newFieldError("ids["+strconv.Itoa(i)+"]", FieldCodeInvalid, "Invalid good ID")
+		}
+	}
+	for i, move := range req.Moves {
+		if move.ID < 1 {
+			return newFieldError("moves["+strconv.Itoa(i)+"].id", FieldCodeInvalid, "Invalid good ID")
+		}
+		if move.NewPriority < 1 {
+			return newFieldError("moves["+strconv.Itoa(i)+"].newPriority", FieldCodeOutOfRange, "Priority must be greater than 0")
+		}
+	}
+
+	return nil
+}
+
+// maxCursorLimit Максимальный размер страницы при обходе по курсору
+const maxCursorLimit = 1000
+
 type PaginatedResponse struct {
 	Meta struct {
-		Total   int `json:"total"`   // Общее количество записей
-		Removed int `json:"removed"` // Количество удаленных записей
-		Limit   int `json:"limit"`   // Размер страницы
-		Offset  int `json:"offset"`  // Смещение
+		Total      int    `json:"total"`                // Общее количество записей
+		Removed    int    `json:"removed"`              // Количество удаленных записей
+		Limit      int    `json:"limit"`                // Размер страницы
+		Offset     int    `json:"offset"`               // Смещение
+		NextCursor string `json:"nextCursor,omitempty"` // Курсор следующей страницы
 	} `json:"meta"`
 	Goods []models.Good `json:"goods"` // Список товаров
 }
 
 func (h *Handler) ListGoods(w http.ResponseWriter, r *http.Request) {
-	limit, offset := getPaginationParams(r)
-
-	// Получаем товары
-	goods, err := h.goodService.ListGoods(r.Context(), limit, offset)
+	filter, err := getGoodsFilter(r)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
 		return
 	}
 
-	// Получаем общее количество записей и количество удаленных записей
-	total, err := h.goodService.GetTotalCount(r.Context())
+	response, err := h.listGoods(r, filter)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			respondWithError(w, http.StatusBadRequest, 4, "Invalid cursor parameter")
+			return
+		}
+
 		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
 		return
 	}
 
-	removed, err := h.goodService.GetRemovedCount(r.Context())
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// listGoods Возвращает страницу товаров по фильтру вместе со счётчиками
+func (h *Handler) listGoods(r *http.Request, filter models.GoodsFilter) (*PaginatedResponse, error) {
+	// Получаем товары
+	goods, nextCursor, err := h.goodService.ListGoods(r.Context(), filter)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
-		return
+		return nil, err
+	}
+
+	// Получаем количество записей и количество удаленных записей, попадающих под фильтр
+	total, removed, err := h.goodService.CountGoods(r.Context(), filter)
+	if err != nil {
+		return nil, err
 	}
 
 	// Формируем ответ с пагинацией
-	response := PaginatedResponse{
+	response := &PaginatedResponse{
 		Goods: goods,
 	}
 	response.Meta.Total = total
 	response.Meta.Removed = removed
-	response.Meta.Limit = limit
-	response.Meta.Offset = offset
+	response.Meta.Limit = filter.Limit
+	response.Meta.Offset = filter.Offset
+	if nextCursor != nil {
+		response.Meta.NextCursor = nextCursor.Encode()
+	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	return response, nil
 }
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -235,14 +422,14 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 
 // getId Извлекает id из URL
 func getId(r *http.Request) (id int, err error) {
-	idStr := r.URL.Query().Get("projectId")
+	idStr := r.URL.Query().Get("id")
 	if idStr == "" {
-		return 0, errors.New("projectId is required")
+		return 0, errors.New("id is required")
 	}
 
 	id, err = strconv.Atoi(idStr)
 	if err != nil {
-		return 0, errors.New("invalid projectId parameter")
+		return 0, errors.New("invalid id parameter")
 	}
 
 	if id <= 0 {
@@ -271,6 +458,136 @@ func getProjectId(r *http.Request) (projectId int, err error) {
 	return projectId, nil
 }
 
+// errIfMatchRequired Запрос изменения товара не содержит заголовка If-Match
+var errIfMatchRequired = errors.New("If-Match header is required")
+
+// errInvalidIfMatch Заголовок If-Match не содержит ETag, выданный сервисом
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+// errInvalidPayload Тело запроса не разбирается как JSON
+var errInvalidPayload = errors.New("Invalid request payload")
+
+// errEmptyGoodPatch Изменение товара не содержит ни одного изменяемого поля
+var errEmptyGoodPatch = errors.New("Name or description is required")
+
+// getIfMatchVersion Извлекает ожидаемую версию товара из заголовка If-Match.
+// Заголовок содержит один ETag, выданный сервисом, либо *, что соответствует
+// любой версии и возвращается как 0
+func getIfMatchVersion(r *http.Request) (int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		return 0, errIfMatchRequired
+	}
+	if ifMatch == "*" {
+		return 0, nil
+	}
+
+	if len(ifMatch) < 2 || ifMatch[0] != '"' || ifMatch[len(ifMatch)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+
+	return version, nil
+}
+
+// requireIfMatch Извлекает ожидаемую версию товара из заголовка If-Match.
+// Если заголовка нет или он некорректен, отправляет ответ и возвращает ok = false
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	version, err := getIfMatchVersion(r)
+	if errors.Is(err, errIfMatchRequired) {
+		respondWithError(w, http.StatusPreconditionRequired, 7, "errors.common.preconditionRequired")
+		return 0, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid If-Match header")
+		return 0, false
+	}
+
+	return version, true
+}
+
+// setETag Передаёт версию товара в заголовке ETag
+func setETag(w http.ResponseWriter, good *models.Good) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(good.Version)))
+}
+
+// isMergePatch Проверяет, что тело запроса передано как JSON merge patch.
+// Обычный application/json и отсутствие Content-Type также принимаются
+func isMergePatch(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/merge-patch+json" || mediaType == "application/json")
+}
+
+// decodeGoodPatch Разбирает JSON merge patch товара. Поля, кроме name
+// и description, не изменяются и пропускаются. Ошибки значений полей
+// возвращаются как *FieldError
+func decodeGoodPatch(r *http.Request) (models.GoodPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil || fields == nil {
+		return models.GoodPatch{}, errInvalidPayload
+	}
+
+	return parseGoodPatch(fields)
+}
+
+// parseGoodPatch Извлекает изменение товара из полей JSON merge patch
+func parseGoodPatch(fields map[string]json.RawMessage) (models.GoodPatch, error) {
+	var patch models.GoodPatch
+
+	if raw, ok := fields["name"]; ok {
+		var name *string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return patch, newFieldError("name", FieldCodeInvalid, "Invalid name")
+		}
+		if name == nil {
+			return patch, newFieldError("name", FieldCodeRequired, "Good name is required")
+		}
+		if err := validateGoodName(*name); err != nil {
+			return patch, err
+		}
+		patch.Name = name
+	}
+
+	if raw, ok := fields["description"]; ok {
+		var description *string
+		if err := json.Unmarshal(raw, &description); err != nil {
+			return patch, newFieldError("description", FieldCodeInvalid, "Invalid description")
+		}
+		// null удаляет описание
+		if description == nil {
+			description = new(string)
+		}
+		patch.Description = description
+	}
+
+	if patch.Name == nil && patch.Description == nil {
+		return patch, errEmptyGoodPatch
+	}
+
+	return patch, nil
+}
+
+// validateGoodName Проверяет название товара
+func validateGoodName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return newFieldError("name", FieldCodeRequired, "Good name is required")
+	}
+	if len([]rune(name)) > maxGoodNameLength {
+		return newFieldError("name", FieldCodeTooLong, "Good name is too long")
+	}
+
+	return nil
+}
+
 // getPaginationParams Извлекает параметры пагинации из query параметров
 func getPaginationParams(r *http.Request) (limit int, offset int) {
 	limitStr := r.URL.Query().Get("limit")
@@ -300,6 +617,136 @@ func getPaginationParams(r *http.Request) (limit int, offset int) {
 	return limit, offset
 }
 
+// getGoodsFilter Извлекает проект и параметры фильтрации, сортировки и пагинации
+// товаров из query параметров
+func getGoodsFilter(r *http.Request) (models.GoodsFilter, error) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		return models.GoodsFilter{}, errors.New("Invalid project ID")
+	}
+
+	return parseGoodsFilter(r, projectId)
+}
+
+// parseGoodsFilter Извлекает параметры фильтрации, сортировки и пагинации товаров
+// проекта из query параметров. Ошибки параметров возвращаются как *FieldError
+func parseGoodsFilter(r *http.Request, projectId int) (models.GoodsFilter, error) {
+	filter := models.GoodsFilter{ProjectID: projectId}
+
+	var err error
+	query := r.URL.Query()
+	filter.Name = strings.TrimSpace(query.Get("name"))
+
+	if filter.PriorityFrom, err = getOptionalInt(query.Get("priorityFrom")); err != nil {
+		return filter, invalidParameter("priorityFrom")
+	}
+	if filter.PriorityTo, err = getOptionalInt(query.Get("priorityTo")); err != nil {
+		return filter, invalidParameter("priorityTo")
+	}
+	if filter.CreatedFrom, err = getOptionalTime(query.Get("createdFrom")); err != nil {
+		return filter, invalidParameter("createdFrom")
+	}
+	if filter.CreatedTo, err = getOptionalTime(query.Get("createdTo")); err != nil {
+		return filter, invalidParameter("createdTo")
+	}
+
+	if includeRemoved := query.Get("includeRemoved"); includeRemoved != "" {
+		filter.IncludeRemoved, err = strconv.ParseBool(includeRemoved)
+		if err != nil {
+			return filter, invalidParameter("includeRemoved")
+		}
+	}
+
+	filter.SortBy = models.SortByPriority
+	if sortBy := query.Get("sortBy"); sortBy != "" {
+		if !models.IsValidSortField(sortBy) {
+			return filter, invalidParameter("sortBy")
+		}
+		filter.SortBy = sortBy
+	}
+
+	switch strings.ToLower(query.Get("order")) {
+	case "", "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, invalidParameter("order")
+	}
+
+	filter.Limit, filter.Offset = getPaginationParams(r)
+
+	// Наличие параметра cursor (в т.ч. пустого) включает обход по курсору
+	if query.Has("cursor") {
+		if filter.SortBy != models.SortByPriority {
+			return filter, newFieldError("sortBy", FieldCodeInvalid, "Cursor pagination supports only sorting by priority")
+		}
+		if query.Get("offset") != "" {
+			return filter, newFieldError("offset", FieldCodeInvalid, "Offset cannot be combined with cursor")
+		}
+
+		filter.Keyset = true
+		filter.Offset = 0
+		filter.Limit = getCursorLimit(r)
+
+		if cursor := query.Get("cursor"); cursor != "" {
+			filter.After, err = models.DecodeGoodsCursor(cursor)
+			if err != nil {
+				return filter, invalidParameter("cursor")
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// invalidParameter Ошибка значения query параметра
+func invalidParameter(name string) *FieldError {
+	return newFieldError(name, FieldCodeInvalid, "Invalid "+name+" parameter")
+}
+
+// getCursorLimit Извлекает размер страницы для обхода по курсору
+func getCursorLimit(r *http.Request) int {
+	limit := 10
+
+	if parsedLimit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && parsedLimit > 0 {
+		limit = parsedLimit
+	}
+
+	if limit > maxCursorLimit {
+		limit = maxCursorLimit
+	}
+
+	return limit
+}
+
+// getOptionalInt Разбирает необязательный целочисленный параметр
+func getOptionalInt(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+// getOptionalTime Разбирает необязательный параметр даты в формате RFC 3339
+func getOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
 func respondWithError(w http.ResponseWriter, status int, code int, message string) {
 	response := ErrorResponse{
 		Code:    code,
@@ -0,0 +1,873 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEnv Обработчики поверх хранилищ в памяти
+type testEnv struct {
+	router   http.Handler
+	repo     *repository.MemoryRepository
+	eventLog *repository.MemoryEventLog
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	return newTestEnvWithAuth(t, nil)
+}
+
+// newTestEnvWithAuth Создаёт окружение с проверкой доступа authService,
+// nil - без аутентификации
+func newTestEnvWithAuth(t *testing.T, authService *service.AuthService) *testEnv {
+	t.Helper()
+
+	return newTestEnvWithServices(t, authService, nil)
+}
+
+// newTestEnvWithServices Создаёт окружение с проверкой доступа и лимитами запросов,
+// nil отключает соответствующую проверку
+func newTestEnvWithServices(t *testing.T, authService *service.AuthService, rateLimitService *service.RateLimitService) *testEnv {
+	t.Helper()
+
+	repo := repository.NewMemoryRepository()
+	cache := repository.NewMemoryCache()
+	eventLog := repository.NewMemoryEventLog()
+
+	handler := NewHandler(
+		service.NewGoodService(repo, cache),
+		service.NewProjectService(repo, cache),
+		service.NewHistoryService(eventLog),
+		service.NewIdempotencyService(cache, time.Hour, time.Minute),
+		authService,
+		rateLimitService,
+	)
+
+	return &testEnv{
+		router:   NewRouter(handler),
+		repo:     repo,
+		eventLog: eventLog,
+	}
+}
+
+// createGoods Создаёт товары в проекте 1 с приоритетами 1..n
+func (e *testEnv) createGoods(t *testing.T, names ...string) []models.Good {
+	t.Helper()
+
+	goods := make([]models.Good, 0, len(names))
+	for _, name := range names {
+		good := models.Good{ProjectID: 1, Name: name}
+		if err := e.repo.CreateGood(context.Background(), &good); err != nil {
+			t.Fatalf("failed to create good: %v", err)
+		}
+		goods = append(goods, good)
+	}
+
+	return goods
+}
+
+// deliverEvents Переносит события из outbox в лог событий
+func (e *testEnv) deliverEvents(t *testing.T) {
+	t.Helper()
+
+	publisher := service.NewMemoryPublisher(e.eventLog)
+	_, err := e.repo.ProcessOutbox(context.Background(), 1000, func(messages []models.OutboxMessage) []error {
+		return publisher.Publish(context.Background(), messages)
+	})
+	if err != nil {
+		t.Fatalf("failed to deliver events: %v", err)
+	}
+}
+
+type handlerCase struct {
+	name       string
+	method     string
+	target     string
+	body       string
+	header     map[string]string // Заголовки запроса
+	wantStatus int
+	wantCode   int               // Код ошибки в ответе, 0 - ответ без ошибки
+	wantError  string            // Код ошибки API v2 в ответе, пусто - ответ без ошибки
+	wantHeader map[string]string // Ожидаемые заголовки ответа
+	check      func(t *testing.T, body []byte)
+}
+
+func (e *testEnv) run(t *testing.T, cases []handlerCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			for key, value := range tc.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			e.router.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body.String())
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected application/json, got %s", contentType)
+			}
+			for key, value := range tc.wantHeader {
+				if got := rec.Header().Get(key); got != value {
+					t.Errorf("expected header %s: %s, got %q", key, value, got)
+				}
+			}
+
+			if tc.wantCode != 0 {
+				var errResp ErrorResponse
+				decode(t, rec.Body.Bytes(), &errResp)
+				if errResp.Code != tc.wantCode {
+					t.Errorf("expected error code %d, got %d (%s)", tc.wantCode, errResp.Code, errResp.Message)
+				}
+			}
+			if tc.wantError != "" {
+				var apiErr APIError
+				decode(t, rec.Body.Bytes(), &apiErr)
+				if apiErr.Code != tc.wantError {
+					t.Errorf("expected error %s, got %s (%s)", tc.wantError, apiErr.Code, apiErr.Message)
+				}
+			}
+
+			if tc.check != nil {
+				tc.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+// ifMatch Заголовок If-Match с ETag версии товара
+func ifMatch(version string) map[string]string {
+	return map[string]string{"If-Match": version}
+}
+
+func decode(t *testing.T, body []byte, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("failed to decode response %s: %v", body, err)
+	}
+}
+
+func TestHandler_CreateGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "created with next priority",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"second","description":"desc"}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.ID == 0 || good.ProjectID != 1 || good.Name != "second" || good.Priority != 2 {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "unknown project",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=100",
+			body:       `{"name":"good"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "missing project id",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create",
+			body:       `{"name":"good"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_UpdateGood(t *testing.T) {
+	env := newTestEnv(t)
+	goods := env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "updated",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"renamed","description":"new"}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.Name != "renamed" || good.Description != "new" || good.Priority != goods[0].Priority || good.Version != 2 {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "stale version",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"clobbered"}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   6,
+		},
+		{
+			name:       "missing If-Match",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"clobbered"}`,
+			wantStatus: http.StatusPreconditionRequired,
+			wantCode:   7,
+		},
+		{
+			name:       "weak ETag",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"clobbered"}`,
+			header:     ifMatch(`W/"2"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "any version",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"forced"}`,
+			header:     ifMatch("*"),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"3"`},
+		},
+		{
+			name:       "unknown good",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=100",
+			body:       `{"name":"renamed"}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "good of another project",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=2&id=1",
+			body:       `{"name":"renamed"}`,
+			header:     ifMatch(`"3"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "invalid id",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=abc",
+			body:       `{"name":"renamed"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `[]`,
+			header:     ifMatch(`"3"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_UpdateGoodPatch(t *testing.T) {
+	env := newTestEnv(t)
+	good := models.Good{ProjectID: 1, Name: "first", Description: "desc"}
+	if err := env.repo.CreateGood(context.Background(), &good); err != nil {
+		t.Fatal(err)
+	}
+
+	expectGood := func(name, description string) func(t *testing.T, body []byte) {
+		return func(t *testing.T, body []byte) {
+			var got models.Good
+			decode(t, body, &got)
+			if got.Name != name || got.Description != description {
+				t.Errorf("expected %q/%q, got %+v", name, description, got)
+			}
+		}
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "name only keeps description",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"renamed"}`,
+			header:     map[string]string{"If-Match": `"1"`, "Content-Type": "application/merge-patch+json"},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2"`},
+			check:      expectGood("renamed", "desc"),
+		},
+		{
+			name:       "null clears description",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"description":null,"priority":100}`,
+			header:     ifMatch(`"2"`),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"3"`},
+			check:      expectGood("renamed", ""),
+		},
+		{
+			name:       "unchanged values keep version",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"renamed","description":""}`,
+			header:     ifMatch(`"3"`),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"3"`},
+			check:      expectGood("renamed", ""),
+		},
+		{
+			name:       "null name",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":null}`,
+			header:     ifMatch(`"3"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "blank name",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"  "}`,
+			header:     ifMatch(`"3"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "nothing to update",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"priority":1}`,
+			header:     ifMatch(`"3"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "unsupported content type",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `name=renamed`,
+			header:     map[string]string{"If-Match": `"3"`, "Content-Type": "application/x-www-form-urlencoded"},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   4,
+		},
+	})
+
+	// События изменения перечисляют только изменившиеся поля
+	env.deliverEvents(t)
+	events, err := env.eventLog.GetHistory(context.Background(), models.HistoryFilter{ProjectID: 1, GoodID: 1, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changed []string
+	for _, event := range events {
+		if event.EventType == models.EventGoodUpdated {
+			changed = append(changed, strings.Join(event.ChangedFields, ","))
+		}
+	}
+	if strings.Join(changed, ";") != "name;description" {
+		t.Errorf("unexpected changed fields %q", changed)
+	}
+}
+
+func TestHandler_GetGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "found",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"1"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.ID != 1 || good.Name != "first" {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "not found",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=2",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "missing id",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "non-positive project id",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=0&id=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_DeleteGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "stale version",
+			method:     http.MethodDelete,
+			target:     "/api/v1/good/remove?projectId=1&id=1",
+			header:     ifMatch(`"2"`),
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   6,
+		},
+		{
+			name:       "missing If-Match",
+			method:     http.MethodDelete,
+			target:     "/api/v1/good/remove?projectId=1&id=1",
+			wantStatus: http.StatusPreconditionRequired,
+			wantCode:   7,
+		},
+		{
+			name:       "removed",
+			method:     http.MethodDelete,
+			target:     "/api/v1/good/remove?projectId=1&id=1",
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp DeleteResponse
+				decode(t, body, &resp)
+				if resp.Id != 1 || resp.CampaignId != 1 || !resp.Removed {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "already removed",
+			method:     http.MethodDelete,
+			target:     "/api/v1/good/remove?projectId=1&id=1",
+			header:     ifMatch(`"2"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "missing project id",
+			method:     http.MethodDelete,
+			target:     "/api/v1/good/remove?id=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_RestoreGood(t *testing.T) {
+	env := newTestEnv(t)
+	goods := env.createGoods(t, "first", "second")
+	if err := env.repo.MarkAsRemoved(context.Background(), &goods[0]); err != nil {
+		t.Fatalf("failed to remove good: %v", err)
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "restored to the end of the list",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/restore?projectId=1&id=1",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"3"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.Removed || good.Priority != 3 {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "not removed",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/restore?projectId=1&id=2",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "invalid id",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/restore?projectId=1&id=-1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_ReprioritizeGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second", "third")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "moved to the top",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?projectId=1&id=3",
+			body:       `{"newPriority":1}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2"`},
+			check: func(t *testing.T, body []byte) {
+				var resp models.PriorityResponse
+				decode(t, body, &resp)
+
+				got := make(map[int]int)
+				for _, item := range resp.Priorities {
+					got[item.ID] = item.Priority
+				}
+				want := map[int]int{3: 1, 1: 2, 2: 3}
+				for id, priority := range want {
+					if got[id] != priority {
+						t.Errorf("expected good %d to have priority %d, got %v", id, priority, resp.Priorities)
+					}
+				}
+			},
+		},
+		{
+			name:       "zero priority",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?projectId=1&id=1",
+			body:       `{"newPriority":0}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "unknown good",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?projectId=1&id=100",
+			body:       `{"newPriority":1}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?projectId=1&id=1",
+			body:       `{"newPriority":"1"}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			// Перемещение товара 3 сдвинуло товар 1 и увеличило его версию
+			name:       "version changed by another move",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?projectId=1&id=1",
+			body:       `{"newPriority":3}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   6,
+		},
+		{
+			name:       "missing If-Match",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?projectId=1&id=1",
+			body:       `{"newPriority":3}`,
+			wantStatus: http.StatusPreconditionRequired,
+			wantCode:   7,
+		},
+	})
+}
+
+func TestHandler_ReorderGoods(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second", "third", "fourth")
+	env.deliverEvents(t)
+
+	expectPriorities := func(want map[int]int) func(t *testing.T, body []byte) {
+		return func(t *testing.T, body []byte) {
+			var resp models.PriorityResponse
+			decode(t, body, &resp)
+
+			got := make(map[int]int)
+			for _, item := range resp.Priorities {
+				got[item.ID] = item.Priority
+			}
+			if len(got) != len(want) {
+				t.Fatalf("expected priorities %v, got %v", want, got)
+			}
+			for id, priority := range want {
+				if got[id] != priority {
+					t.Errorf("expected priorities %v, got %v", want, got)
+					break
+				}
+			}
+		}
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "full order",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{"ids":[4,3,2,1]}`,
+			wantStatus: http.StatusOK,
+			check:      expectPriorities(map[int]int{4: 1, 3: 2, 2: 3, 1: 4}),
+		},
+		{
+			name:       "moves",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{"moves":[{"id":1,"newPriority":1},{"id":1,"newPriority":2},{"id":2,"newPriority":1}]}`,
+			wantStatus: http.StatusOK,
+			check:      expectPriorities(map[int]int{2: 1, 4: 2, 1: 3, 3: 4}),
+		},
+		{
+			name:       "incomplete order",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{"ids":[1,2,3]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "both ids and moves",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{"ids":[1,2,3,4],"moves":[{"id":1,"newPriority":1}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "empty request",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "zero priority",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{"moves":[{"id":1,"newPriority":0}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "move of unknown good",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=1",
+			body:       `{"moves":[{"id":100,"newPriority":1}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "unknown project",
+			method:     http.MethodPatch,
+			target:     "/api/v1/goods/reorder?projectId=100",
+			body:       `{"ids":[1]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+	})
+
+	// Каждый изменившийся товар получает одно итоговое событие на операцию
+	env.deliverEvents(t)
+	events, err := env.eventLog.GetHistory(context.Background(), models.HistoryFilter{ProjectID: 1, GoodID: 1, Limit: 100})
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	var priorities []int
+	for _, event := range events {
+		if event.EventType == models.EventGoodReprioritized {
+			priorities = append(priorities, event.Priority)
+		}
+	}
+	if !equalInts(priorities, []int{4, 3}) {
+		t.Errorf("expected reprioritized events with priorities [4 3] for good 1, got %v", priorities)
+	}
+}
+
+func TestHandler_ListGoods(t *testing.T) {
+	env := newTestEnv(t)
+	goods := env.createGoods(t, "apple", "banana", "cherry", "apricot")
+	if err := env.repo.MarkAsRemoved(context.Background(), &goods[1]); err != nil {
+		t.Fatalf("failed to remove good: %v", err)
+	}
+
+	listIDs := func(t *testing.T, body []byte) (PaginatedResponse, []int) {
+		var resp PaginatedResponse
+		decode(t, body, &resp)
+
+		ids := make([]int, 0, len(resp.Goods))
+		for _, good := range resp.Goods {
+			ids = append(ids, good.ID)
+		}
+		return resp, ids
+	}
+	expectIDs := func(want ...int) func(t *testing.T, body []byte) {
+		return func(t *testing.T, body []byte) {
+			_, ids := listIDs(t, body)
+			if !equalInts(ids, want) {
+				t.Errorf("expected goods %v, got %v", want, ids)
+			}
+		}
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "default page with counters",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, ids := listIDs(t, body)
+				if !equalInts(ids, []int{1, 3, 4}) {
+					t.Errorf("expected goods [1 3 4], got %v", ids)
+				}
+				if resp.Meta.Total != 3 || resp.Meta.Removed != 1 || resp.Meta.Limit != 10 {
+					t.Errorf("unexpected meta: %+v", resp.Meta)
+				}
+			},
+		},
+		{
+			name:       "limit and offset",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&limit=1&offset=1",
+			wantStatus: http.StatusOK,
+			check:      expectIDs(3),
+		},
+		{
+			name:       "name filter",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&name=AP",
+			wantStatus: http.StatusOK,
+			check:      expectIDs(1, 4),
+		},
+		{
+			name:       "priority range with removed",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&priorityFrom=2&priorityTo=3&includeRemoved=true",
+			wantStatus: http.StatusOK,
+			check:      expectIDs(2, 3),
+		},
+		{
+			name:       "sort by name descending",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&sortBy=name&order=desc",
+			wantStatus: http.StatusOK,
+			check:      expectIDs(3, 4, 1),
+		},
+		{
+			name:       "first cursor page",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&cursor=&limit=2",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, ids := listIDs(t, body)
+				if !equalInts(ids, []int{1, 3}) {
+					t.Errorf("expected goods [1 3], got %v", ids)
+				}
+				if resp.Meta.NextCursor == "" {
+					t.Error("expected next cursor")
+				}
+			},
+		},
+		{
+			name:       "last cursor page",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&limit=2&cursor=" + models.NewGoodsCursor(&goods[2], false).Encode(),
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, ids := listIDs(t, body)
+				if !equalInts(ids, []int{4}) || resp.Meta.NextCursor != "" {
+					t.Errorf("unexpected last page: goods=%v, cursor=%q", ids, resp.Meta.NextCursor)
+				}
+			},
+		},
+		{
+			name:       "cursor of another project",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&cursor=" + models.NewGoodsCursor(&models.Good{ID: 1, ProjectID: 2}, false).Encode(),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "malformed cursor",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&cursor=abc",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "cursor with offset",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&cursor=&offset=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "invalid sort field",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&sortBy=description",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "invalid created date",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&createdFrom=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
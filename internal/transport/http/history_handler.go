@@ -0,0 +1,113 @@
+package http
+
+import (
+	"errors"
+	"goods-service/internal/models"
+	"net/http"
+	"strings"
+)
+
+type HistoryResponse struct {
+	Meta struct {
+		Total  int `json:"total"`  // Количество событий, попадающих под фильтр
+		Limit  int `json:"limit"`  // Размер страницы
+		Offset int `json:"offset"` // Смещение
+	} `json:"meta"`
+	Events []models.ClickhouseEvent `json:"events"` // События в порядке времени
+}
+
+// GetGoodHistory Возвращает историю изменений товара в хронологическом порядке
+func (h *Handler) GetGoodHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := getId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid good ID")
+		return
+	}
+
+	filter, err := getHistoryFilter(r, false)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+	filter.GoodID = id
+
+	h.respondWithHistory(w, r, filter)
+}
+
+// GetProjectHistory Возвращает ленту последних изменений всех товаров проекта
+func (h *Handler) GetProjectHistory(w http.ResponseWriter, r *http.Request) {
+	filter, err := getHistoryFilter(r, true)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	h.respondWithHistory(w, r, filter)
+}
+
+func (h *Handler) respondWithHistory(w http.ResponseWriter, r *http.Request, filter models.HistoryFilter) {
+	response, err := h.history(r, filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// history Возвращает страницу событий по фильтру
+func (h *Handler) history(r *http.Request, filter models.HistoryFilter) (*HistoryResponse, error) {
+	events, total, err := h.historyService.GetHistory(r.Context(), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &HistoryResponse{
+		Events: events,
+	}
+	response.Meta.Total = total
+	response.Meta.Limit = filter.Limit
+	response.Meta.Offset = filter.Offset
+
+	return response, nil
+}
+
+// getHistoryFilter Извлекает проект и параметры выборки истории из query параметров.
+// defaultDesc задаёт порядок сортировки, если параметр order не указан
+func getHistoryFilter(r *http.Request, defaultDesc bool) (models.HistoryFilter, error) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		return models.HistoryFilter{}, errors.New("Invalid project ID")
+	}
+
+	return parseHistoryFilter(r, projectId, defaultDesc)
+}
+
+// parseHistoryFilter Извлекает параметры выборки истории проекта из query
+// параметров. Ошибки параметров возвращаются как *FieldError
+func parseHistoryFilter(r *http.Request, projectId int, defaultDesc bool) (models.HistoryFilter, error) {
+	filter := models.HistoryFilter{ProjectID: projectId, Desc: defaultDesc}
+
+	var err error
+	query := r.URL.Query()
+	if filter.From, err = getOptionalTime(query.Get("from")); err != nil {
+		return filter, invalidParameter("from")
+	}
+	if filter.To, err = getOptionalTime(query.Get("to")); err != nil {
+		return filter, invalidParameter("to")
+	}
+
+	switch strings.ToLower(query.Get("order")) {
+	case "":
+	case "asc":
+		filter.Desc = false
+	case "desc":
+		filter.Desc = true
+	default:
+		return filter, invalidParameter("order")
+	}
+
+	filter.Limit, filter.Offset = getPaginationParams(r)
+
+	return filter, nil
+}
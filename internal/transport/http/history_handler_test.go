@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"net/http"
+	"testing"
+)
+
+func TestHandler_History(t *testing.T) {
+	env := newTestEnv(t)
+	goods := env.createGoods(t, "first", "second")
+	name := "renamed"
+	if _, err := env.repo.UpdateGood(context.Background(), &goods[0], models.GoodPatch{Name: &name}); err != nil {
+		t.Fatalf("failed to update good: %v", err)
+	}
+	env.deliverEvents(t)
+
+	eventTypes := func(t *testing.T, body []byte) (HistoryResponse, []string) {
+		var resp HistoryResponse
+		decode(t, body, &resp)
+
+		types := make([]string, 0, len(resp.Events))
+		for _, event := range resp.Events {
+			types = append(types, event.EventType)
+		}
+		return resp, types
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "good history in chronological order",
+			method:     http.MethodGet,
+			target:     "/api/v1/good/history?projectId=1&id=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, types := eventTypes(t, body)
+				if resp.Meta.Total != 2 || len(types) != 2 ||
+					types[0] != models.EventGoodCreated || types[1] != models.EventGoodUpdated {
+					t.Fatalf("unexpected history: total=%d, events=%v", resp.Meta.Total, types)
+				}
+				if name := resp.Events[1].PrevName; name == nil || *name != "first" {
+					t.Errorf("expected previous name first, got %v", name)
+				}
+			},
+		},
+		{
+			name:       "project feed starts with latest",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/history?projectId=1&limit=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, types := eventTypes(t, body)
+				if resp.Meta.Total != 3 || len(types) != 1 || types[0] != models.EventGoodUpdated {
+					t.Errorf("unexpected feed: total=%d, events=%v", resp.Meta.Total, types)
+				}
+			},
+		},
+		{
+			name:       "project feed in ascending order",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/history?projectId=1&order=asc",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				_, types := eventTypes(t, body)
+				if len(types) != 3 || types[0] != models.EventGoodCreated || types[2] != models.EventGoodUpdated {
+					t.Errorf("unexpected feed: %v", types)
+				}
+			},
+		},
+		{
+			name:       "invalid order",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/history?projectId=1&order=up",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "invalid time bound",
+			method:     http.MethodGet,
+			target:     "/api/v1/good/history?projectId=1&id=1&from=today",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "missing good id",
+			method:     http.MethodGet,
+			target:     "/api/v1/good/history?projectId=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"goods-service/internal/models"
+	"io"
+	"log"
+	"net/http"
+)
+
+// maxIdempotencyKeyLength Максимальная длина ключа идемпотентности
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder Передаёт ответ клиенту и запоминает его для повторов запроса
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.header != nil {
+		return
+	}
+
+	r.status = status
+	r.header = r.ResponseWriter.Header().Clone()
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.header == nil {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// errInvalidIdempotencyKey Ключ идемпотентности длиннее допустимого
+var errInvalidIdempotencyKey = errors.New("invalid Idempotency-Key header")
+
+// idempotent Выполняет запрос с заголовком Idempotency-Key не более одного раза.
+// Повтор запроса с тем же ключом получает сохранённый ответ с заголовком
+// Idempotent-Replayed, запрос с тем же ключом и другими параметрами отклоняется.
+// Пока запрос выполняется, повтор получает 409. Ответы с ошибкой сервера
+// не сохраняются, такой запрос можно повторить
+func (h *Handler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return h.withIdempotency(next, respondWithIdempotencyError)
+}
+
+// idempotentV2 То же, что idempotent, с ошибками в формате API v2
+func (h *Handler) idempotentV2(next http.HandlerFunc) http.HandlerFunc {
+	return h.withIdempotency(next, func(w http.ResponseWriter, err error) {
+		respondWithAPIError(w, apiError(err, nil))
+	})
+}
+
+// withIdempotency Оборачивает обработчик проверкой ключа идемпотентности.
+// fail отправляет ответ на ошибку обработки ключа
+func (h *Handler) withIdempotency(next http.HandlerFunc, fail func(w http.ResponseWriter, err error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			fail(w, errInvalidIdempotencyKey)
+			return
+		}
+		// Ключи разных клиентов не пересекаются, ответ не попадёт другому клиенту
+		if identity := models.IdentityFromContext(r.Context()); identity != nil {
+			key = identity.Actor() + ":" + key
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			fail(w, errInvalidPayload)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(r, body)
+		owner, record, err := h.idempotencyService.Begin(r.Context(), key, hash)
+		if err != nil {
+			if errors.Is(err, models.ErrIdempotencyKeyInProgress) {
+				w.Header().Set("Retry-After", "1")
+			}
+			fail(w, err)
+			return
+		}
+		if record != nil {
+			replayResponse(w, record)
+			return
+		}
+
+		// Ответ сохраняется, даже если клиент не дождался его и отменил запрос
+		ctx := context.WithoutCancel(r.Context())
+		rec := &responseRecorder{ResponseWriter: w}
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := h.idempotencyService.Release(ctx, key, owner); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+		}()
+
+		// Резерв продлевается, пока выполняется запрос
+		stop := h.idempotencyService.KeepAlive(key, owner)
+		defer stop()
+
+		next(rec, r)
+		stop()
+
+		if rec.header == nil || rec.status >= http.StatusInternalServerError {
+			return
+		}
+
+		err = h.idempotencyService.Complete(ctx, key, owner, &models.IdempotencyRecord{
+			StatusCode:  rec.status,
+			RequestHash: hash,
+			Header:      rec.header,
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			// Ключ остаётся зарезервированным до истечения блокировки,
+			// чтобы повтор не выполнил запрос второй раз сразу
+			log.Printf("Failed to save idempotent response: %v", err)
+		}
+		saved = true
+	}
+}
+
+// respondWithIdempotencyError Отправляет ошибку обработки ключа идемпотентности
+// в формате API v1
+func respondWithIdempotencyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errInvalidIdempotencyKey):
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid Idempotency-Key header")
+	case errors.Is(err, errInvalidPayload):
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+	case errors.Is(err, models.ErrIdempotencyKeyReused):
+		respondWithError(w, http.StatusUnprocessableEntity, 8, "errors.common.idempotencyKeyReused")
+	case errors.Is(err, models.ErrIdempotencyKeyInProgress):
+		respondWithError(w, http.StatusConflict, 9, "errors.common.idempotencyKeyInProgress")
+	default:
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+	}
+}
+
+// requestHash Хэш параметров запроса, которые должны совпадать при повторе
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+"\n"+r.URL.RequestURI()+"\n"+r.Header.Get("If-Match")+"\n")
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayResponse Отправляет сохранённый ответ на запрос
+func replayResponse(w http.ResponseWriter, record *models.IdempotencyRecord) {
+	for key, values := range record.Header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
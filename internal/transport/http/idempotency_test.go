@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHandler_Idempotency(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	var created models.Good
+	env.run(t, []handlerCase{
+		{
+			name:       "first request",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"second"}`,
+			header:     map[string]string{"Idempotency-Key": "create-1"},
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				decode(t, body, &created)
+			},
+		},
+		{
+			name:       "replay",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"second"}`,
+			header:     map[string]string{"Idempotency-Key": "create-1"},
+			wantStatus: http.StatusCreated,
+			wantHeader: map[string]string{"Idempotent-Replayed": "true", "ETag": `"1"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.ID != created.ID || !good.CreatedAt.Equal(created.CreatedAt) {
+					t.Errorf("expected original response %+v, got %+v", created, good)
+				}
+			},
+		},
+		{
+			name:       "different payload",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"third"}`,
+			header:     map[string]string{"Idempotency-Key": "create-1"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   8,
+		},
+		{
+			name:       "different endpoint",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"second"}`,
+			header:     map[string]string{"Idempotency-Key": "create-1", "If-Match": `"1"`},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   8,
+		},
+		{
+			// Ответ с ошибкой клиента тоже сохраняется
+			name:       "replayed error",
+			method:     http.MethodDelete,
+			target:     "/api/v1/good/remove?projectId=1&id=1",
+			header:     map[string]string{"Idempotency-Key": "remove-1", "If-Match": `"2"`},
+			wantStatus: http.StatusPreconditionFailed,
+			wantCode:   6,
+		},
+		{
+			name:       "too long key",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"third"}`,
+			header:     map[string]string{"Idempotency-Key": strings.Repeat("k", maxIdempotencyKeyLength+1)},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+
+	total, _, err := env.repo.CountGoods(context.Background(), models.GoodsFilter{ProjectID: 1})
+	if err != nil || total != 2 {
+		t.Errorf("expected 2 goods, got %d, err=%v", total, err)
+	}
+}
+
+func TestHandler_IdempotencyConcurrentRequests(t *testing.T) {
+	env := newTestEnv(t)
+
+	const requests = 20
+	var wg sync.WaitGroup
+	statuses := make([]int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/good/create?projectId=1", strings.NewReader(`{"name":"good"}`))
+			req.Header.Set("Idempotency-Key", "concurrent")
+			rec := httptest.NewRecorder()
+			env.router.ServeHTTP(rec, req)
+			statuses[i] = rec.Code
+		}(i)
+	}
+	wg.Wait()
+
+	// Каждый запрос либо создаёт товар, либо получает его ответ, либо ждёт завершения первого
+	for _, status := range statuses {
+		if status != http.StatusCreated && status != http.StatusConflict {
+			t.Errorf("unexpected status %d", status)
+		}
+	}
+
+	total, _, err := env.repo.CountGoods(context.Background(), models.GoodsFilter{ProjectID: 1})
+	if err != nil || total != 1 {
+		t.Errorf("expected exactly one good, got %d, err=%v", total, err)
+	}
+}
+
+func TestHandler_IdempotencyServerError(t *testing.T) {
+	h := &Handler{
+		idempotencyService: service.NewIdempotencyService(repository.NewMemoryCache(), time.Hour, time.Minute),
+	}
+
+	calls := 0
+	handler := h.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, struct{}{})
+	})
+
+	for i, want := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "retry")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != want {
+			t.Errorf("request %d: expected status %d, got %d", i, want, rec.Code)
+		}
+	}
+
+	// Запрос после ошибки сервера выполняется заново, следующий повтор получает сохранённый ответ
+	if calls != 2 {
+		t.Errorf("expected handler to be called twice, got %d", calls)
+	}
+}
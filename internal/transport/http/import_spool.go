@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"goods-service/internal/models"
+	"io"
+	"os"
+)
+
+// importSpoolMemoryRows Количество строк импорта, которые хранятся в памяти.
+// Остальные строки записываются во временный файл
+const importSpoolMemoryRows = 10000
+
+// spooledImportRow Строка импорта во временном файле
+type spooledImportRow struct {
+	Line        int    `json:"line"`
+	ExternalKey string `json:"externalKey"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Err         string `json:"err,omitempty"`
+}
+
+// importSpool Прочитанные и проверенные строки файла импорта. Импорт блокирует
+// проект, поэтому файл читается от клиента целиком до начала транзакции
+type importSpool struct {
+	rows []models.ImportRow
+	next int
+
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// spoolImportRows Читает строки reader до конца, проверяет их функцией validate
+// и сохраняет: первые memoryRows строк в памяти, остальные во временном файле.
+// Spool нужно закрыть, чтобы удалить временный файл
+func spoolImportRows(reader goodsReader, memoryRows int, validate func(row *models.ImportRow)) (*importSpool, error) {
+	spool := &importSpool{}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			spool.Close()
+			return nil, err
+		}
+
+		validate(row)
+		if err := spool.add(row, memoryRows); err != nil {
+			spool.Close()
+			return nil, err
+		}
+	}
+
+	if err := spool.rewind(); err != nil {
+		spool.Close()
+		return nil, err
+	}
+
+	return spool, nil
+}
+
+// add Сохраняет строку в памяти или, после memoryRows строк, во временном файле
+func (s *importSpool) add(row *models.ImportRow, memoryRows int) error {
+	if s.file == nil && len(s.rows) < memoryRows {
+		s.rows = append(s.rows, *row)
+		return nil
+	}
+
+	if s.file == nil {
+		file, err := os.CreateTemp("", "goods-import-*.ndjson")
+		if err != nil {
+			return err
+		}
+		s.file = file
+		s.buf = bufio.NewWriter(file)
+		s.enc = json.NewEncoder(s.buf)
+	}
+
+	spooled := spooledImportRow{
+		Line:        row.Line,
+		ExternalKey: row.Good.ExternalKey,
+		Name:        row.Good.Name,
+		Description: row.Good.Description,
+	}
+	if row.Err != nil {
+		spooled.Err = row.Err.Error()
+	}
+
+	return s.enc.Encode(spooled)
+}
+
+// rewind Готовит временный файл к чтению строк
+func (s *importSpool) rewind() error {
+	if s.file == nil {
+		return nil
+	}
+
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.dec = json.NewDecoder(bufio.NewReader(s.file))
+
+	return nil
+}
+
+// Next Возвращает следующую строку, io.EOF - строки закончились
+func (s *importSpool) Next() (*models.ImportRow, error) {
+	if s.next < len(s.rows) {
+		s.next++
+		return &s.rows[s.next-1], nil
+	}
+	if s.dec == nil {
+		return nil, io.EOF
+	}
+
+	var spooled spooledImportRow
+	if err := s.dec.Decode(&spooled); err != nil {
+		return nil, err
+	}
+
+	row := &models.ImportRow{
+		Line: spooled.Line,
+		Good: models.Good{
+			ExternalKey: spooled.ExternalKey,
+			Name:        spooled.Name,
+			Description: spooled.Description,
+		},
+	}
+	if spooled.Err != "" {
+		row.Err = errors.New(spooled.Err)
+	}
+
+	return row, nil
+}
+
+// Close Удаляет временный файл
+func (s *importSpool) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"errors"
+	"goods-service/internal/models"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestImportSpool(t *testing.T) {
+	reader := newNDJSONGoodsReader(strings.NewReader(
+		`{"externalKey":"a","name":"first"}` + "\n" +
+			`{"externalKey":"b","name":"second","description":"text"}` + "\n" +
+			`{` + "\n" +
+			`{"externalKey":"c","name":""}` + "\n",
+	))
+
+	spool, err := spoolImportRows(reader, 1, func(row *models.ImportRow) {
+		if row.Err == nil {
+			row.Err = validateImportGood(&row.Good)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spool.file == nil {
+		t.Fatal("expected rows after the first one to be spooled to a file")
+	}
+	name := spool.file.Name()
+
+	want := []struct {
+		line int
+		key  string
+		err  string
+	}{
+		{1, "a", ""},
+		{2, "b", ""},
+		{3, "", "Invalid JSON"},
+		{4, "c", "Good name is required"},
+	}
+	for _, w := range want {
+		row, err := spool.Next()
+		if err != nil {
+			t.Fatalf("line %d: %v", w.line, err)
+		}
+		var rowErr string
+		if row.Err != nil {
+			rowErr = row.Err.Error()
+		}
+		if row.Line != w.line || row.Good.ExternalKey != w.key || rowErr != w.err {
+			t.Errorf("expected line %d key %q error %q, got %+v", w.line, w.key, w.err, row)
+		}
+	}
+	if row, err := spool.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %+v, %v", row, err)
+	}
+
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected spool file to be removed, got %v", err)
+	}
+}
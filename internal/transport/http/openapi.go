@@ -0,0 +1,358 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// openAPISpec Спецификация API. Запросы проверяются по ней до вызова обработчика,
+// при изменении маршрутов и тел запросов её нужно обновлять
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// openAPIDocument Загруженная спецификация API, общая для всех роутеров
+var openAPIDocument = sync.OnceValues(loadOpenAPI)
+
+// loadOpenAPI Загружает и проверяет спецификацию API
+func loadOpenAPI() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// serveOpenAPI Отдаёт спецификацию API в JSON
+func serveOpenAPI(doc *openapi3.T) http.HandlerFunc {
+	spec, err := json.Marshal(doc)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// requestValidator Проверяет запросы по операциям спецификации API:
+// обязательные параметры, типы параметров и схемы JSON тел
+type requestValidator struct {
+	routes map[string]*routers.Route // Операции по методу и шаблону пути
+}
+
+func newRequestValidator(doc *openapi3.T) *requestValidator {
+	routes := make(map[string]*routers.Route)
+	for path, item := range doc.Paths.Map() {
+		for method, operation := range item.Operations() {
+			routes[method+" "+path] = &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			}
+		}
+	}
+
+	return &requestValidator{routes: routes}
+}
+
+// Middleware Отклоняет запросы, не соответствующие операции маршрута mux.
+// Ошибка возвращается в формате версии API маршрута. Тела не в JSON,
+// например файлы импорта, не проверяются и не читаются
+func (v *requestValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := v.route(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if apiErr := validateRequest(r, route); apiErr != nil {
+			if strings.HasPrefix(route.Path, "/api/v2/") {
+				respondWithAPIError(w, apiErr)
+			} else {
+				respondWithValidationErrorV1(w, apiErr)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// route Возвращает операцию спецификации для маршрута mux запроса
+func (v *requestValidator) route(r *http.Request) *routers.Route {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return nil
+	}
+
+	path, err := current.GetPathTemplate()
+	if err != nil {
+		return nil
+	}
+
+	return v.routes[r.Method+" "+path]
+}
+
+// nonBlankPattern Шаблон строки, которая не может состоять только из пробелов.
+// Нарушение шаблона - незаполненное обязательное поле
+const nonBlankPattern = `\S`
+
+// validateRequest Проверяет запрос по операции route. Тело проверяется на копии
+// запроса, исходное тело остаётся доступным обработчику
+func validateRequest(r *http.Request, route *routers.Route) *APIError {
+	input := &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: mux.Vars(r),
+		Route:      route,
+		Options: &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			ExcludeRequestBody:  true,
+			// Учётные данные проверяет authenticate
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+
+	var data []byte
+	if body := route.Operation.RequestBody; body != nil && isJSONContent(body.Value.Content) {
+		mediaType := "application/json"
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			parsed, _, err := mime.ParseMediaType(contentType)
+			if err != nil || body.Value.Content.Get(parsed) == nil {
+				return &APIError{
+					Status:  http.StatusUnsupportedMediaType,
+					Code:    CodeUnsupportedMediaType,
+					Message: "Unsupported Content-Type " + contentType,
+				}
+			}
+			mediaType = parsed
+		}
+
+		var err error
+		if data, err = io.ReadAll(r.Body); err != nil {
+			return invalidRequest(errInvalidPayload)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+
+		input.Request = r.Clone(r.Context())
+		input.Request.Body = io.NopCloser(bytes.NewReader(data))
+		input.Request.Header.Set("Content-Type", mediaType)
+		input.Options.ExcludeRequestBody = false
+	}
+
+	if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+		apiErr := validationError(err)
+		sortBodyFieldErrors(apiErr.Details, data)
+		return apiErr
+	}
+
+	return nil
+}
+
+// sortBodyFieldErrors Упорядочивает ошибки полей по порядку полей в теле запроса,
+// как их возвращают обработчики. Ошибки отсутствующих полей и параметров
+// остаются в конце в исходном порядке
+func sortBodyFieldErrors(details []FieldError, body []byte) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if token, err := dec.Token(); err != nil || token != json.Delim('{') {
+		return
+	}
+
+	positions := make(map[string]int)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return
+		}
+		if key, ok := token.(string); ok {
+			if _, seen := positions[key]; !seen {
+				positions[key] = len(positions)
+			}
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return
+		}
+	}
+
+	position := func(field string) int {
+		if i := strings.IndexAny(field, ".["); i >= 0 {
+			field = field[:i]
+		}
+		if pos, ok := positions[field]; ok {
+			return pos
+		}
+		return len(positions)
+	}
+	sort.SliceStable(details, func(i, j int) bool {
+		return position(details[i].Field) < position(details[j].Field)
+	})
+}
+
+// isJSONContent Проверяет, что тело запроса описано схемой JSON
+func isJSONContent(content openapi3.Content) bool {
+	for mediaType := range content {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validationError Преобразует ошибки проверки запроса в ошибку API v2.
+// Ошибки параметров дают invalid_request, ошибки полей тела - validation_failed
+func validationError(err error) *APIError {
+	apiErr := &APIError{Status: http.StatusUnprocessableEntity, Code: CodeValidationFailed, Message: "Validation failed"}
+
+	for _, e := range unwrapMultiError(err) {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(e, &reqErr) {
+			return invalidRequest(e)
+		}
+
+		if reqErr.Parameter != nil {
+			apiErr.Status, apiErr.Code, apiErr.Message = http.StatusBadRequest, CodeInvalidRequest, "Invalid request"
+			apiErr.Details = append(apiErr.Details, parameterError(reqErr.Parameter, reqErr.Err))
+			continue
+		}
+
+		fields := bodyFieldErrors(reqErr.Err)
+		if len(fields) == 0 {
+			return invalidRequest(errInvalidPayload)
+		}
+		apiErr.Details = append(apiErr.Details, fields...)
+	}
+
+	if len(apiErr.Details) == 1 {
+		apiErr.Message = apiErr.Details[0].Message
+	}
+
+	return apiErr
+}
+
+// parameterError Ошибка параметра пути, запроса или заголовка
+func parameterError(param *openapi3.Parameter, err error) FieldError {
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		return FieldError{Field: param.Name, Code: FieldCodeRequired, Message: param.Name + " is required"}
+	}
+
+	message := "Invalid " + param.Name + " parameter"
+	if param.In == openapi3.ParameterInHeader {
+		message = "Invalid " + param.Name + " header"
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) && schemaErr.SchemaField == "maxLength" {
+		return FieldError{Field: param.Name, Code: FieldCodeTooLong, Message: message}
+	}
+
+	return FieldError{Field: param.Name, Code: FieldCodeInvalid, Message: message}
+}
+
+// bodyFieldErrors Ошибки полей тела запроса. Пустой результат означает,
+// что тело не разбирается или не является объектом
+func bodyFieldErrors(err error) []FieldError {
+	var fields []FieldError
+	// Поле, нарушающее несколько правил схемы, попадает в ошибки один раз
+	seen := make(map[string]bool)
+	for _, e := range unwrapMultiError(err) {
+		var schemaErr *openapi3.SchemaError
+		if !errors.As(e, &schemaErr) {
+			return nil
+		}
+
+		pointer := schemaErr.JSONPointer()
+		if len(pointer) == 0 {
+			return nil
+		}
+
+		field := pointer[0]
+		for _, part := range pointer[1:] {
+			if _, err := strconv.Atoi(part); err == nil {
+				field += "[" + part + "]"
+			} else {
+				field += "." + part
+			}
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+
+		switch schemaErr.SchemaField {
+		case "pattern":
+			code, message := FieldCodeInvalid, "Field "+field+" is invalid"
+			if schemaErr.Schema != nil && schemaErr.Schema.Pattern == nonBlankPattern {
+				code, message = FieldCodeRequired, "Field "+field+" is required"
+			}
+			fields = append(fields, FieldError{Field: field, Code: code, Message: message})
+		case "required", "nullable", "minLength", "minItems":
+			fields = append(fields, FieldError{Field: field, Code: FieldCodeRequired, Message: "Field " + field + " is required"})
+		case "maxLength", "maxItems":
+			fields = append(fields, FieldError{Field: field, Code: FieldCodeTooLong, Message: "Field " + field + " is too long"})
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			fields = append(fields, FieldError{Field: field, Code: FieldCodeOutOfRange, Message: "Field " + field + " is out of range"})
+		default:
+			fields = append(fields, FieldError{Field: field, Code: FieldCodeInvalid, Message: "Field " + field + " is invalid"})
+		}
+	}
+
+	return fields
+}
+
+// unwrapMultiError Раскрывает вложенные openapi3.MultiError в список ошибок
+func unwrapMultiError(err error) []error {
+	multi, ok := err.(openapi3.MultiError)
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, e := range multi {
+		errs = append(errs, unwrapMultiError(e)...)
+	}
+
+	return errs
+}
+
+// respondWithValidationErrorV1 Отправляет ошибку проверки запроса в формате API v1
+func respondWithValidationErrorV1(w http.ResponseWriter, apiErr *APIError) {
+	status := http.StatusBadRequest
+	if apiErr.Status == http.StatusUnsupportedMediaType {
+		status = apiErr.Status
+	}
+
+	message := apiErr.Message
+	if len(apiErr.Details) > 0 {
+		message = apiErr.Details[0].Message
+	}
+
+	respondWithError(w, status, 4, message)
+}
@@ -0,0 +1,354 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPI_RoutesMatchSpec(t *testing.T) {
+	doc, err := openAPIDocument()
+	if err != nil {
+		t.Fatalf("failed to load specification: %v", err)
+	}
+
+	router := newTestEnv(t).router.(*mux.Router)
+	routes := make(map[string]bool)
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routes[method+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operations := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			operations[method+" "+path] = true
+		}
+	}
+
+	for route := range routes {
+		if !operations[route] {
+			t.Errorf("route %s is missing from specification", route)
+		}
+	}
+	for operation := range operations {
+		if !routes[operation] {
+			t.Errorf("operation %s has no route", operation)
+		}
+	}
+}
+
+// contractCase Запрос к операции спецификации и ожидаемый статус ответа
+type contractCase struct {
+	operationId string
+	method      string
+	target      string
+	body        string
+	header      map[string]string
+	wantStatus  int
+}
+
+// TestOpenAPI_Contract Выполняет запросы к каждой операции спецификации
+// и проверяет ответы по её схемам
+func TestOpenAPI_Contract(t *testing.T) {
+	doc, err := openAPIDocument()
+	if err != nil {
+		t.Fatalf("failed to load specification: %v", err)
+	}
+
+	env := newTestEnv(t)
+	router := env.router.(*mux.Router)
+	validator := newRequestValidator(doc)
+	anyVersion := map[string]string{"If-Match": "*"}
+
+	cases := []contractCase{
+		{"getOpenAPI", http.MethodGet, "/api/openapi.json", "", nil, http.StatusOK},
+
+		{"createProjectV1", http.MethodPost, "/api/v1/project/create", `{"name":"second"}`, nil, http.StatusCreated},
+		{"createProjectV1", http.MethodPost, "/api/v1/project/create", `{"name":5}`, nil, http.StatusBadRequest},
+		{"listProjectsV1", http.MethodGet, "/api/v1/project/list?limit=1", "", nil, http.StatusOK},
+		{"getProjectV1", http.MethodGet, "/api/v1/project/get?projectId=1", "", nil, http.StatusOK},
+		{"getProjectV1", http.MethodGet, "/api/v1/project/get?projectId=100", "", nil, http.StatusBadRequest},
+		{"renameProjectV1", http.MethodPatch, "/api/v1/project/update?projectId=2", `{"name":"renamed"}`, nil, http.StatusOK},
+		{"getProjectHistoryV1", http.MethodGet, "/api/v1/project/history?projectId=1&order=ASC", "", nil, http.StatusOK},
+
+		{"createGoodV1", http.MethodPost, "/api/v1/good/create?projectId=1", `{"name":"first","externalKey":"sku-1"}`, nil, http.StatusCreated},
+		{"createGoodsV1", http.MethodPost, "/api/v1/goods/batch/create?projectId=1", `{"items":[{"name":"second"},{"name":"third"}]}`, nil, http.StatusCreated},
+		{"createGoodsV1", http.MethodPost, "/api/v1/goods/batch/create?projectId=1", `{"atomic":true,"items":[{"name":""}]}`, nil, http.StatusBadRequest},
+		{"getGoodV1", http.MethodGet, "/api/v1/goods?id=1&projectId=1", "", nil, http.StatusOK},
+		{"listGoodsV1", http.MethodGet, "/api/v1/goods/list?projectId=1&sortBy=name&order=desc", "", nil, http.StatusOK},
+		{"listGoodsV1", http.MethodGet, "/api/v1/goods/list?projectId=1&cursor=&limit=1", "", nil, http.StatusOK},
+		{"updateGoodV1", http.MethodPatch, "/api/v1/good/update?id=1&projectId=1", `{"description":"desc"}`, anyVersion, http.StatusOK},
+		{"updateGoodV1", http.MethodPatch, "/api/v1/good/update?id=1&projectId=1", `{"description":"desc"}`, nil, http.StatusPreconditionRequired},
+		{"updateGoodsV1", http.MethodPatch, "/api/v1/goods/batch/update?projectId=1", `{"items":[{"id":2,"name":"second!","version":1}]}`, nil, http.StatusOK},
+		{"reprioritizeGoodV1", http.MethodPatch, "/api/v1/good/reprioritiize?id=3&projectId=1", `{"newPriority":1}`, anyVersion, http.StatusOK},
+		{"reorderGoodsV1", http.MethodPatch, "/api/v1/goods/reorder?projectId=1", `{"moves":[{"id":1,"newPriority":1}]}`, nil, http.StatusOK},
+		{"exportGoodsV1", http.MethodGet, "/api/v1/goods/export?projectId=1", "", nil, http.StatusOK},
+		{"exportGoodsV1", http.MethodGet, "/api/v1/goods/export?projectId=1&format=ndjson", "", nil, http.StatusOK},
+		{"importGoodsV1", http.MethodPost, "/api/v1/goods/import?projectId=1", "externalKey,name\nsku-9,imported\n", map[string]string{"Content-Type": "text/csv"}, http.StatusOK},
+		{"importGoodsV1", http.MethodPost, "/api/v1/goods/import?projectId=1", "externalKey,name\n,no key\n", nil, http.StatusBadRequest},
+		{"deleteGoodV1", http.MethodDelete, "/api/v1/good/remove?id=2&projectId=1", "", anyVersion, http.StatusOK},
+		{"restoreGoodV1", http.MethodPatch, "/api/v1/good/restore?id=2&projectId=1", "", nil, http.StatusOK},
+		{"deleteGoodsV1", http.MethodDelete, "/api/v1/goods/batch/remove?projectId=1", `{"items":[{"id":2,"version":5}]}`, nil, http.StatusOK},
+		{"getGoodHistoryV1", http.MethodGet, "/api/v1/good/history?id=1&projectId=1", "", nil, http.StatusOK},
+		{"deleteProjectV1", http.MethodDelete, "/api/v1/project/remove?projectId=2", "", nil, http.StatusOK},
+
+		{"createProjectV2", http.MethodPost, "/api/v2/projects", `{"name":"third"}`, nil, http.StatusCreated},
+		{"createProjectV2", http.MethodPost, "/api/v2/projects", `{}`, nil, http.StatusUnprocessableEntity},
+		{"listProjectsV2", http.MethodGet, "/api/v2/projects?offset=1", "", nil, http.StatusOK},
+		{"getProjectV2", http.MethodGet, "/api/v2/projects/3", "", nil, http.StatusOK},
+		{"getProjectV2", http.MethodGet, "/api/v2/projects/100", "", nil, http.StatusNotFound},
+		{"renameProjectV2", http.MethodPatch, "/api/v2/projects/3", `{"name":"renamed"}`, nil, http.StatusOK},
+		{"getProjectHistoryV2", http.MethodGet, "/api/v2/projects/1/history", "", nil, http.StatusOK},
+		{"createGoodV2", http.MethodPost, "/api/v2/projects/1/goods", `{"name":"fourth"}`, nil, http.StatusCreated},
+		{"createGoodV2", http.MethodPost, "/api/v2/projects/1/goods", `{"name":"copy","externalKey":"sku-1"}`, nil, http.StatusConflict},
+		{"createGoodV2", http.MethodPost, "/api/v2/projects/1/goods", `{"name":""}`, nil, http.StatusUnprocessableEntity},
+		{"listGoodsV2", http.MethodGet, "/api/v2/projects/1/goods?includeRemoved=true", "", nil, http.StatusOK},
+		{"listGoodsV2", http.MethodGet, "/api/v2/projects/1/goods?limit=0", "", nil, http.StatusBadRequest},
+		{"getGoodV2", http.MethodGet, "/api/v2/projects/1/goods/1", "", nil, http.StatusOK},
+		{"getGoodV2", http.MethodGet, "/api/v2/projects/1/goods/100", "", nil, http.StatusNotFound},
+		{"updateGoodV2", http.MethodPatch, "/api/v2/projects/1/goods/1", `{"name":"renamed"}`, anyVersion, http.StatusOK},
+		{"updateGoodV2", http.MethodPatch, "/api/v2/projects/1/goods/1", `{"name":"renamed"}`, map[string]string{"If-Match": `"1"`}, http.StatusPreconditionFailed},
+		{"updateGoodV2", http.MethodPatch, "/api/v2/projects/1/goods/1", `{"name":"renamed"}`, nil, http.StatusPreconditionRequired},
+		{"updateGoodV2", http.MethodPatch, "/api/v2/projects/1/goods/1", `name=renamed`, map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"reprioritizeGoodV2", http.MethodPut, "/api/v2/projects/1/goods/1/priority", `{"priority":2}`, anyVersion, http.StatusOK},
+		{"reorderGoodsV2", http.MethodPut, "/api/v2/projects/1/goods/order", `{"moves":[{"id":1,"newPriority":1}]}`, nil, http.StatusOK},
+		{"reorderGoodsV2", http.MethodPut, "/api/v2/projects/1/goods/order", `{"ids":[1]}`, nil, http.StatusUnprocessableEntity},
+		{"deleteGoodV2", http.MethodDelete, "/api/v2/projects/1/goods/3", "", anyVersion, http.StatusNoContent},
+		{"restoreGoodV2", http.MethodPost, "/api/v2/projects/1/goods/3/restore", "", nil, http.StatusOK},
+		{"getGoodHistoryV2", http.MethodGet, "/api/v2/projects/1/goods/1/history?order=desc", "", nil, http.StatusOK},
+		{"deleteProjectV2", http.MethodDelete, "/api/v2/projects/3", "", nil, http.StatusOK},
+	}
+
+	covered := make(map[string]bool)
+	for _, tc := range cases {
+		covered[tc.operationId] = true
+
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			for key, value := range tc.header {
+				req.Header.Set(key, value)
+			}
+
+			var match mux.RouteMatch
+			if !router.Match(req, &match) || match.MatchErr != nil {
+				t.Fatalf("no route for %s %s", tc.method, tc.target)
+			}
+			path, _ := match.Route.GetPathTemplate()
+			route := validator.routes[tc.method+" "+path]
+			if route == nil {
+				t.Fatal("no operation for route")
+			}
+			if route.Operation.OperationID != tc.operationId {
+				t.Fatalf("expected operation %s, got %s", tc.operationId, route.Operation.OperationID)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			err := openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    req,
+					PathParams: match.Vars,
+					Route:      route,
+				},
+				Status:  rec.Code,
+				Header:  rec.Header(),
+				Body:    io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+				Options: &openapi3filter.Options{IncludeResponseStatus: true, MultiError: true},
+			})
+			if err != nil {
+				t.Errorf("response does not match specification: %v\n%s", err, rec.Body.String())
+			}
+		})
+	}
+
+	var missing []string
+	for _, item := range doc.Paths.Map() {
+		for _, operation := range item.Operations() {
+			if !covered[operation.OperationID] {
+				missing = append(missing, operation.OperationID)
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("operations without contract cases: %v", missing)
+	}
+}
+
+func TestOpenAPI_RequestValidation(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "v1 missing required parameter",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+			check: func(t *testing.T, body []byte) {
+				var errResp ErrorResponse
+				decode(t, body, &errResp)
+				if errResp.Message != "projectId is required" {
+					t.Errorf("unexpected message %s", errResp.Message)
+				}
+			},
+		},
+		{
+			name:       "v1 parameter type",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/list?projectId=1&priorityFrom=high",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "v1 body field type",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/reprioritiize?id=1&projectId=1",
+			body:       `{"newPriority":"1"}`,
+			header:     ifMatch("*"),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "v1 unsupported content type",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `name=second`,
+			header:     map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   4,
+		},
+		{
+			name:       "v2 body field type",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":5}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:invalid"),
+		},
+		{
+			name:       "v2 required field",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"description":"no name"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:required"),
+		},
+		{
+			name:       "v2 blank field",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":" \t"}`,
+			header:     ifMatch("*"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:required"),
+		},
+		{
+			name:       "v2 fields in body order",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"","description":"desc","externalKey":"` + strings.Repeat("k", maxExternalKeyLength+1) + `"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:required,externalKey:too_long"),
+		},
+		{
+			name:       "v2 nested field",
+			method:     http.MethodPut,
+			target:     "/api/v2/projects/1/goods/order",
+			body:       `{"moves":[{"id":1}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("moves[0].newPriority:required"),
+		},
+		{
+			name:       "v2 missing body",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+		},
+		{
+			name:       "v2 body is not an object",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `["first"]`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+		},
+		{
+			name:       "v2 all invalid parameters",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods?limit=many&includeRemoved=maybe",
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("includeRemoved:invalid,limit:invalid"),
+		},
+		{
+			name:       "v2 header",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects",
+			body:       `{"name":"second"}`,
+			header:     map[string]string{"Idempotency-Key": strings.Repeat("k", maxIdempotencyKeyLength+1)},
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("Idempotency-Key:too_long"),
+		},
+		{
+			name:       "import body is not validated",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/import?projectId=1&format=ndjson",
+			body:       `{"externalKey":"sku-1","name":"imported"}`,
+			header:     map[string]string{"Content-Type": "application/x-ndjson"},
+			wantStatus: http.StatusOK,
+		},
+	})
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	env := newTestEnv(t)
+
+	rec := httptest.NewRecorder()
+	env.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	served, err := openapi3.NewLoader().LoadFromData(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("failed to load served specification: %v", err)
+	}
+	if served.OpenAPI != "3.0.3" || served.Paths.Value("/api/v2/projects/{projectId}/goods/{id}") == nil {
+		t.Errorf("unexpected specification: %s %v", served.OpenAPI, served.Paths.InMatchingOrder())
+	}
+}
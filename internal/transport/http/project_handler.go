@@ -0,0 +1,181 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"goods-service/internal/models"
+	"net/http"
+	"strings"
+)
+
+const maxProjectNameLength = 255
+
+type ProjectRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+		return
+	}
+
+	name, err := validateProjectName(req.Name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	project := models.Project{Name: name}
+	if err := h.projectService.CreateProject(r.Context(), &project); err != nil {
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, project)
+}
+
+func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	project, err := h.projectService.GetProject(r.Context(), projectId)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, project)
+}
+
+type ProjectsPaginatedResponse struct {
+	Meta struct {
+		Total  int `json:"total"`  // Общее количество проектов
+		Limit  int `json:"limit"`  // Размер страницы
+		Offset int `json:"offset"` // Смещение
+	} `json:"meta"`
+	Projects []models.Project `json:"projects"` // Список проектов
+}
+
+func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
+	response, err := h.listProjects(r)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// listProjects Возвращает страницу проектов с общим количеством
+func (h *Handler) listProjects(r *http.Request) (*ProjectsPaginatedResponse, error) {
+	limit, offset := getPaginationParams(r)
+
+	projects, err := h.projectService.ListProjects(r.Context(), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := h.projectService.GetProjectsCount(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ProjectsPaginatedResponse{
+		Projects: projects,
+	}
+	response.Meta.Total = total
+	response.Meta.Limit = limit
+	response.Meta.Offset = offset
+
+	return response, nil
+}
+
+func (h *Handler) RenameProject(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid request payload")
+		return
+	}
+
+	name, err := validateProjectName(req.Name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	project := models.Project{ID: projectId, Name: name}
+	if err := h.projectService.RenameProject(r.Context(), &project); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, project)
+}
+
+// DeleteProjectResponse Ответ на удаление проекта.
+// RemovedGoods - количество товаров, помеченных удалёнными вместе с проектом
+type DeleteProjectResponse struct {
+	Id           int  `json:"id"`
+	Removed      bool `json:"removed"`
+	RemovedGoods int  `json:"removedGoods"`
+}
+
+func (h *Handler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	removedGoods, err := h.projectService.DeleteProject(r.Context(), projectId)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, DeleteProjectResponse{
+		Id:           projectId,
+		Removed:      true,
+		RemovedGoods: removedGoods,
+	})
+}
+
+// validateProjectName Проверяет и нормализует название проекта
+func validateProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", newFieldError("name", FieldCodeRequired, "Project name is required")
+	}
+
+	if len([]rune(name)) > maxProjectNameLength {
+		return "", newFieldError("name", FieldCodeTooLong, "Project name is too long")
+	}
+
+	return name, nil
+}
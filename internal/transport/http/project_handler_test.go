@@ -0,0 +1,202 @@
+package http
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateProject(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.run(t, []handlerCase{
+		{
+			name:       "created with trimmed name",
+			method:     http.MethodPost,
+			target:     "/api/v1/project/create",
+			body:       `{"name":"  second  "}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				var project models.Project
+				decode(t, body, &project)
+				if project.ID != 2 || project.Name != "second" || project.Removed {
+					t.Errorf("unexpected project: %+v", project)
+				}
+			},
+		},
+		{
+			name:       "empty name",
+			method:     http.MethodPost,
+			target:     "/api/v1/project/create",
+			body:       `{"name":"   "}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "name too long",
+			method:     http.MethodPost,
+			target:     "/api/v1/project/create",
+			body:       `{"name":"` + strings.Repeat("я", maxProjectNameLength+1) + `"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPost,
+			target:     "/api/v1/project/create",
+			body:       `name`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_GetProject(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.run(t, []handlerCase{
+		{
+			name:       "found",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/get?projectId=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var project models.Project
+				decode(t, body, &project)
+				if project.ID != 1 {
+					t.Errorf("unexpected project: %+v", project)
+				}
+			},
+		},
+		{
+			name:       "not found",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/get?projectId=100",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "invalid project id",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/get?projectId=abc",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_ListProjects(t *testing.T) {
+	env := newTestEnv(t)
+	for _, name := range []string{"second", "third"} {
+		project := models.Project{Name: name}
+		if err := env.repo.CreateProject(context.Background(), &project); err != nil {
+			t.Fatalf("failed to create project: %v", err)
+		}
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "page",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/list?limit=2&offset=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp ProjectsPaginatedResponse
+				decode(t, body, &resp)
+				if resp.Meta.Total != 3 || resp.Meta.Limit != 2 || resp.Meta.Offset != 1 {
+					t.Errorf("unexpected meta: %+v", resp.Meta)
+				}
+				if len(resp.Projects) != 2 || resp.Projects[0].ID != 2 || resp.Projects[1].ID != 3 {
+					t.Errorf("unexpected projects: %+v", resp.Projects)
+				}
+			},
+		},
+		{
+			name:       "limit is capped",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/list?limit=1000",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp ProjectsPaginatedResponse
+				decode(t, body, &resp)
+				if resp.Meta.Limit != 100 || len(resp.Projects) != 3 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+	})
+}
+
+func TestHandler_RenameProject(t *testing.T) {
+	env := newTestEnv(t)
+
+	env.run(t, []handlerCase{
+		{
+			name:       "renamed",
+			method:     http.MethodPatch,
+			target:     "/api/v1/project/update?projectId=1",
+			body:       `{"name":"renamed"}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var project models.Project
+				decode(t, body, &project)
+				if project.ID != 1 || project.Name != "renamed" {
+					t.Errorf("unexpected project: %+v", project)
+				}
+			},
+		},
+		{
+			name:       "not found",
+			method:     http.MethodPatch,
+			target:     "/api/v1/project/update?projectId=100",
+			body:       `{"name":"renamed"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "empty name",
+			method:     http.MethodPatch,
+			target:     "/api/v1/project/update?projectId=1",
+			body:       `{"name":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_DeleteProject(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "removed with goods",
+			method:     http.MethodDelete,
+			target:     "/api/v1/project/remove?projectId=1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp DeleteProjectResponse
+				decode(t, body, &resp)
+				if resp.Id != 1 || !resp.Removed || resp.RemovedGoods != 2 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "goods are not visible",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "already removed",
+			method:     http.MethodDelete,
+			target:     "/api/v1/project/remove?projectId=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"goods-service/internal/models"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimitAddress Ограничивает частоту запросов с одного адреса до аутентификации,
+// чтобы перебор учётных данных не нагружал хранилище клиентов. Если хранилище
+// лимитов недоступно, запрос пропускается без ограничения
+func (h *Handler) rateLimitAddress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.rateLimitService == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result, err := h.rateLimitService.AllowAddress(r.Context(), remoteHost(r))
+		if err != nil {
+			log.Printf("Failed to check rate limit: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if result != nil && !result.Allowed {
+			writeRateLimitHeaders(w, result)
+			respondWithAuthError(w, r, errRateLimited)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimit Ограничивает частоту запросов клиента и проекта. Клиент - субъект
+// аутентификации, без неё - адрес клиента. Ответ содержит заголовки X-RateLimit-*
+// по самому строгому из бакетов запроса, при исчерпании лимита запрос
+// отклоняется с 429 и Retry-After. Если хранилище лимитов недоступно,
+// запрос пропускается без ограничения
+func (h *Handler) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.rateLimitService == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route := r.Method + " " + routePath(r)
+		result, err := h.rateLimitService.Allow(r.Context(), rateLimitClient(r), route, requestProjectId(r), !isReadRequest(r))
+		if err != nil {
+			log.Printf("Failed to check rate limit: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if result == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writeRateLimitHeaders(w, result)
+		if !result.Allowed {
+			respondWithAuthError(w, r, errRateLimited)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeRateLimitHeaders Записывает состояние бакета в заголовки X-RateLimit-*,
+// при исчерпании лимита - и Retry-After
+func writeRateLimitHeaders(w http.ResponseWriter, result *models.RateLimitResult) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+	}
+}
+
+// rateLimitClient Клиент, по которому считаются лимиты
+func rateLimitClient(r *http.Request) string {
+	if identity := models.IdentityFromContext(r.Context()); identity != nil {
+		return identity.Actor()
+	}
+
+	return "ip:" + remoteHost(r)
+}
+
+// remoteHost Адрес клиента без порта
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// ceilSeconds Длительность в целых секундах с округлением вверх
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"goods-service/internal/repository"
+	"goods-service/internal/service"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestRateLimitService() *service.RateLimitService {
+	return service.NewRateLimitService(repository.NewMemoryCache(), service.RateLimits{
+		ClientRead:   models.RateLimit{Requests: 2, Period: time.Minute},
+		ClientWrite:  models.RateLimit{Requests: 5, Period: time.Minute},
+		ProjectWrite: models.RateLimit{Requests: 1, Period: time.Minute},
+		Routes: map[string]service.RouteRateLimit{
+			"PATCH /api/v1/good/update": {Client: models.RateLimit{Requests: 10, Period: time.Minute}},
+		},
+	})
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	env := newTestEnvWithServices(t, nil, newTestRateLimitService())
+	env.createGoods(t, "first")
+	if err := env.repo.CreateProject(context.Background(), &models.Project{Name: "second"}); err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "read",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": "30"},
+		},
+		{
+			name:       "write uses separate budget",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"second"}`,
+			wantStatus: http.StatusCreated,
+			wantHeader: map[string]string{"X-RateLimit-Limit": "1", "X-RateLimit-Remaining": "0"},
+		},
+		{
+			name:       "project write limit",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=1",
+			body:       `{"name":"third"}`,
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   12,
+			wantHeader: map[string]string{"Retry-After": "60", "X-RateLimit-Limit": "1", "X-RateLimit-Remaining": "0"},
+		},
+		{
+			name:       "project write limit v2",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"third"}`,
+			wantStatus: http.StatusTooManyRequests,
+			wantError:  CodeRateLimited,
+			wantHeader: map[string]string{"Retry-After": "60"},
+		},
+		{
+			name:       "route with own limit",
+			method:     http.MethodPatch,
+			target:     "/api/v1/good/update?projectId=1&id=1",
+			body:       `{"name":"renamed"}`,
+			header:     ifMatch("*"),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "9"},
+		},
+		{
+			name:       "other project",
+			method:     http.MethodPost,
+			target:     "/api/v1/good/create?projectId=2",
+			body:       `{"name":"other"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "read",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods?projectId=1&id=1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "client read limit",
+			method:     http.MethodGet,
+			target:     "/api/v1/project/list",
+			wantStatus: http.StatusTooManyRequests,
+			wantCode:   12,
+			wantHeader: map[string]string{"Retry-After": "30"},
+		},
+	})
+}
+
+func TestHandler_RateLimitPerClient(t *testing.T) {
+	env := newTestEnvWithServices(t, newTestAuthService(t), newTestRateLimitService())
+	env.createGoods(t, "first")
+
+	// Лимит чтения считается отдельно для каждого клиента
+	for _, subject := range []string{"reader", "editor"} {
+		env.run(t, []handlerCase{
+			{
+				name:       subject + " read",
+				method:     http.MethodGet,
+				target:     "/api/v2/projects/1/goods/1",
+				header:     apiKey(subject),
+				wantStatus: http.StatusOK,
+				wantHeader: map[string]string{"X-RateLimit-Remaining": "1"},
+			},
+		})
+	}
+
+	// Запрос без доступа к проекту отклоняется до списания токенов
+	env.run(t, []handlerCase{
+		{
+			name:       "forbidden",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/2/goods",
+			header:     apiKey("reader"),
+			wantStatus: http.StatusForbidden,
+			wantHeader: map[string]string{"X-RateLimit-Remaining": ""},
+		},
+		{
+			name:       "reader read",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods",
+			header:     apiKey("reader"),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"X-RateLimit-Remaining": "0"},
+		},
+	})
+}
+
+func TestHandler_RateLimitAddress(t *testing.T) {
+	rateLimits := service.NewRateLimitService(repository.NewMemoryCache(), service.RateLimits{
+		Address: models.RateLimit{Requests: 2, Period: time.Minute},
+	})
+	env := newTestEnvWithServices(t, newTestAuthService(t), rateLimits)
+
+	// Лимит адреса проверяется до аутентификации и расходуется любыми запросами
+	env.run(t, []handlerCase{
+		{
+			name:       "unknown key",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects",
+			header:     map[string]string{"X-API-Key": "unknown"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "reader read",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1",
+			header:     apiKey("reader"),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "address limit",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects",
+			header:     map[string]string{"X-API-Key": "unknown"},
+			wantStatus: http.StatusTooManyRequests,
+			wantError:  CodeRateLimited,
+			wantHeader: map[string]string{"Retry-After": "30", "X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "0", "WWW-Authenticate": ""},
+		},
+	})
+}
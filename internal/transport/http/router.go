@@ -3,9 +3,17 @@ package http
 import (
 	"github.com/gorilla/mux"
 	"net/http"
+	"regexp"
+	"strings"
 )
 
 func NewRouter(h *Handler) *mux.Router {
+	doc, err := openAPIDocument()
+	if err != nil {
+		// Спецификация встроена в сборку, ошибка в ней - ошибка программы
+		panic("invalid OpenAPI specification: " + err.Error())
+	}
+
 	r := mux.NewRouter()
 
 	// Middleware
@@ -15,17 +23,97 @@ func NewRouter(h *Handler) *mux.Router {
 			next.ServeHTTP(w, r)
 		})
 	})
+	// Клиент определяется до проверки запроса, доступ к проекту и лимиты - после,
+	// когда projectId уже проверен. Лимит адреса проверяется до аутентификации
+	r.Use(h.rateLimitAddress)
+	r.Use(h.authenticate)
+	r.Use(newRequestValidator(doc).Middleware)
+	r.Use(h.authorize)
+	r.Use(h.rateLimit)
+
+	r.HandleFunc("/api/openapi.json", serveOpenAPI(doc)).Methods(http.MethodGet)
 
 	// API routes
 	api := r.PathPrefix("/api/v1").Subrouter()
 
-	// Goods endpoints
+	// Goods endpoints. Изменяющие запросы принимают заголовок Idempotency-Key,
+	// кроме импорта, тело которого не хранится целиком
 	api.HandleFunc("/goods/list", h.ListGoods).Methods(http.MethodGet)
-	api.HandleFunc("/good/create", h.CreateGood).Methods(http.MethodPost)
+	api.HandleFunc("/good/create", h.idempotent(h.CreateGood)).Methods(http.MethodPost)
 	api.HandleFunc("/goods", h.GetGood).Methods(http.MethodGet)
-	api.HandleFunc("/good/update", h.UpdateGood).Methods(http.MethodPatch)
-	api.HandleFunc("/good/remove", h.DeleteGood).Methods(http.MethodDelete)
-	api.HandleFunc("/good/reprioritiize", h.ReprioritizeGood).Methods(http.MethodPatch)
+	api.HandleFunc("/good/update", h.idempotent(h.UpdateGood)).Methods(http.MethodPatch)
+	api.HandleFunc("/good/remove", h.idempotent(h.DeleteGood)).Methods(http.MethodDelete)
+	api.HandleFunc("/good/reprioritiize", h.idempotent(h.ReprioritizeGood)).Methods(http.MethodPatch)
+	api.HandleFunc("/goods/reorder", h.idempotent(h.ReorderGoods)).Methods(http.MethodPatch)
+	api.HandleFunc("/goods/batch/create", h.idempotent(h.CreateGoods)).Methods(http.MethodPost)
+	api.HandleFunc("/goods/batch/update", h.idempotent(h.UpdateGoods)).Methods(http.MethodPatch)
+	api.HandleFunc("/goods/batch/remove", h.idempotent(h.DeleteGoods)).Methods(http.MethodDelete)
+	api.HandleFunc("/goods/export", h.ExportGoods).Methods(http.MethodGet)
+	api.HandleFunc("/goods/import", h.ImportGoods).Methods(http.MethodPost)
+	api.HandleFunc("/good/restore", h.idempotent(h.RestoreGood)).Methods(http.MethodPatch)
+	api.HandleFunc("/good/history", h.GetGoodHistory).Methods(http.MethodGet)
+
+	// Projects endpoints
+	api.HandleFunc("/project/list", h.ListProjects).Methods(http.MethodGet)
+	api.HandleFunc("/project/create", h.idempotent(h.CreateProject)).Methods(http.MethodPost)
+	api.HandleFunc("/project/get", h.GetProject).Methods(http.MethodGet)
+	api.HandleFunc("/project/update", h.idempotent(h.RenameProject)).Methods(http.MethodPatch)
+	api.HandleFunc("/project/remove", h.idempotent(h.DeleteProject)).Methods(http.MethodDelete)
+	api.HandleFunc("/project/history", h.GetProjectHistory).Methods(http.MethodGet)
+
+	// API v2: ресурсы адресуются путём, ошибки возвращаются по каталогу из v2_errors.go.
+	// Изменяющие запросы так же принимают заголовок Idempotency-Key
+	v2 := r.PathPrefix("/api/v2").Subrouter()
+	v2.NotFoundHandler = routeNotFoundV2(v2)
+	v2.MethodNotAllowedHandler = routeNotFoundV2(v2)
+
+	// Projects endpoints
+	v2.HandleFunc("/projects", h.ListProjectsV2).Methods(http.MethodGet)
+	v2.HandleFunc("/projects", h.idempotentV2(h.CreateProjectV2)).Methods(http.MethodPost)
+	v2.HandleFunc("/projects/{projectId}", h.GetProjectV2).Methods(http.MethodGet)
+	v2.HandleFunc("/projects/{projectId}", h.idempotentV2(h.RenameProjectV2)).Methods(http.MethodPatch)
+	v2.HandleFunc("/projects/{projectId}", h.idempotentV2(h.DeleteProjectV2)).Methods(http.MethodDelete)
+	v2.HandleFunc("/projects/{projectId}/history", h.GetProjectHistoryV2).Methods(http.MethodGet)
+
+	// Goods endpoints. Порядок товаров объявлен до пути товара, чтобы order не разбирался как id
+	v2.HandleFunc("/projects/{projectId}/goods", h.ListGoodsV2).Methods(http.MethodGet)
+	v2.HandleFunc("/projects/{projectId}/goods", h.idempotentV2(h.CreateGoodV2)).Methods(http.MethodPost)
+	v2.HandleFunc("/projects/{projectId}/goods/order", h.idempotentV2(h.ReorderGoodsV2)).Methods(http.MethodPut)
+	v2.HandleFunc("/projects/{projectId}/goods/{id}", h.GetGoodV2).Methods(http.MethodGet)
+	v2.HandleFunc("/projects/{projectId}/goods/{id}", h.idempotentV2(h.UpdateGoodV2)).Methods(http.MethodPatch)
+	v2.HandleFunc("/projects/{projectId}/goods/{id}", h.idempotentV2(h.DeleteGoodV2)).Methods(http.MethodDelete)
+	v2.HandleFunc("/projects/{projectId}/goods/{id}/restore", h.idempotentV2(h.RestoreGoodV2)).Methods(http.MethodPost)
+	v2.HandleFunc("/projects/{projectId}/goods/{id}/priority", h.idempotentV2(h.ReprioritizeGoodV2)).Methods(http.MethodPut)
+	v2.HandleFunc("/projects/{projectId}/goods/{id}/history", h.GetGoodHistoryV2).Methods(http.MethodGet)
 
 	return r
 }
+
+// routeNotFoundV2 Отвечает на запрос, для которого в router нет обработчика.
+// Если путь поддерживает другие методы, отвечает 405 со списком методов в Allow.
+// mux не отличает неподдерживаемый метод от неизвестного пути в подроутере,
+// поэтому методы пути определяются обходом маршрутов
+func routeNotFoundV2(router *mux.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var methods []string
+		router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			pathRegexp, err := route.GetPathRegexp()
+			if err != nil {
+				return nil
+			}
+			if matched, _ := regexp.MatchString(pathRegexp, r.URL.Path); matched {
+				routeMethods, _ := route.GetMethods()
+				methods = append(methods, routeMethods...)
+			}
+			return nil
+		})
+
+		if len(methods) == 0 {
+			respondWithAPIError(w, errRouteNotFound)
+			return
+		}
+
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		respondWithAPIError(w, errMethodNotAllowed)
+	}
+}
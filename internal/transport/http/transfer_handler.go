@@ -0,0 +1,446 @@
+package http
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goods-service/internal/models"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	formatCSV    = "csv"
+	formatNDJSON = "ndjson"
+)
+
+// maxImportSize Максимальный размер файла импорта
+const maxImportSize = 64 << 20
+
+// maxNDJSONLineSize Максимальная длина строки NDJSON
+const maxNDJSONLineSize = 1 << 20
+
+// utf8BOM Метка порядка байтов, по которой табличные редакторы определяют кодировку CSV
+const utf8BOM = "\uFEFF"
+
+// csvExportColumns Колонки выгрузки CSV. При импорте используются externalKey,
+// name и description, остальные колонки пропускаются
+var csvExportColumns = []string{"id", "externalKey", "name", "description", "priority", "createdAt"}
+
+// csvFormulaPrefixes Первые символы ячейки, с которых табличные редакторы начинают формулу
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// errInvalidImportFile Файл импорта не удаётся прочитать целиком
+var errInvalidImportFile = errors.New("invalid import file")
+
+// ImportResponse Результат импорта товаров
+type ImportResponse struct {
+	Applied    bool              `json:"applied"` // false - в файле есть ошибки, изменения не сохранены
+	Created    int               `json:"created"`
+	Updated    int               `json:"updated"`
+	Unchanged  int               `json:"unchanged"`
+	ErrorCount int               `json:"errorCount"` // Общее количество строк с ошибками
+	Errors     []ImportLineError `json:"errors"`     // Первые models.MaxImportErrors ошибок
+}
+
+// ImportLineError Ошибка строки файла импорта
+type ImportLineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ExportGoods Выгружает неудалённые товары проекта в CSV или NDJSON в порядке приоритета
+func (h *Handler) ExportGoods(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	format, err := getTransferFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectId); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	var writer goodsWriter
+	if format == formatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		writer = newCSVGoodsWriter(w)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		writer = newNDJSONGoodsWriter(w)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="goods-%d.%s"`, projectId, format))
+	w.WriteHeader(http.StatusOK)
+
+	// После начала выгрузки статус ответа уже отправлен, ошибку можно только записать в лог
+	err = h.goodService.ExportGoods(r.Context(), projectId, writer.Write)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		log.Printf("Failed to export goods of project %d: %v", projectId, err)
+	}
+}
+
+// ImportGoods Создаёт или обновляет товары проекта по внешнему ключу из CSV или NDJSON.
+// Файл применяется только целиком, ошибки возвращаются с номерами строк
+func (h *Handler) ImportGoods(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getProjectId(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, "Invalid project ID")
+		return
+	}
+
+	format, err := getTransferFormat(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var reader goodsReader
+	if format == formatCSV {
+		reader, err = newCSVGoodsReader(body)
+	} else {
+		reader = newNDJSONGoodsReader(body)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, 4, err.Error())
+		return
+	}
+
+	// Первая строка с каждым внешним ключом
+	keyLines := make(map[string]int)
+	validate := func(row *models.ImportRow) {
+		if row.Err == nil {
+			row.Err = validateImportGood(&row.Good)
+		}
+		if row.Err == nil {
+			if line, ok := keyLines[row.Good.ExternalKey]; ok {
+				row.Err = fmt.Errorf("Duplicate external key, first used on line %d", line)
+			} else {
+				keyLines[row.Good.ExternalKey] = row.Line
+			}
+		}
+	}
+
+	// Файл читается целиком до транзакции импорта, чтобы медленный клиент
+	// не держал блокировку проекта
+	spool, err := spoolImportRows(reader, importSpoolMemoryRows, validate)
+	if err != nil {
+		if errors.Is(err, errInvalidImportFile) {
+			respondWithError(w, http.StatusBadRequest, 4, err.Error())
+			return
+		}
+
+		log.Printf("Failed to read import file: %v", err)
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+	defer spool.Close()
+
+	result, err := h.goodService.ImportGoods(r.Context(), projectId, spool.Next)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			respondWithError(w, http.StatusBadRequest, 3, "errors.common.notFound")
+			return
+		}
+
+		respondWithError(w, http.StatusInternalServerError, 5, "Internal server error")
+		return
+	}
+
+	response := ImportResponse{
+		Applied:    result.Applied,
+		Created:    result.Created,
+		Updated:    result.Updated,
+		Unchanged:  result.Unchanged,
+		ErrorCount: result.ErrorCount,
+		Errors:     make([]ImportLineError, 0, len(result.Errors)),
+	}
+	for _, lineErr := range result.Errors {
+		message := lineErr.Err.Error()
+		if errors.Is(lineErr.Err, models.ErrExternalKeyRemoved) {
+			message = "Good with this external key is removed"
+		}
+		response.Errors = append(response.Errors, ImportLineError{Line: lineErr.Line, Message: message})
+	}
+	sort.SliceStable(response.Errors, func(i, j int) bool {
+		return response.Errors[i].Line < response.Errors[j].Line
+	})
+
+	if !result.Applied {
+		respondWithJSON(w, http.StatusBadRequest, response)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// validateImportGood Проверяет поля товара из строки импорта
+func validateImportGood(good *models.Good) error {
+	switch {
+	case good.ExternalKey == "":
+		return errors.New("External key is required")
+	case len([]rune(good.ExternalKey)) > maxExternalKeyLength:
+		return errors.New("External key is too long")
+	case good.Name == "":
+		return errors.New("Good name is required")
+	case len([]rune(good.Name)) > maxGoodNameLength:
+		return errors.New("Good name is too long")
+	}
+
+	return nil
+}
+
+// getTransferFormat Извлекает формат файла из URL, по умолчанию CSV
+func getTransferFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", formatCSV:
+		return formatCSV, nil
+	case formatNDJSON:
+		return formatNDJSON, nil
+	default:
+		return "", errors.New("Invalid format parameter")
+	}
+}
+
+// goodsWriter Записывает товары выгрузки
+type goodsWriter interface {
+	Write(good *models.Good) error
+	Flush() error
+}
+
+// csvGoodsWriter Записывает товары в CSV с заголовком csvExportColumns
+type csvGoodsWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVGoodsWriter(w io.Writer) *csvGoodsWriter {
+	return &csvGoodsWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvGoodsWriter) Write(good *models.Good) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		strconv.Itoa(good.ID),
+		escapeCSVFormula(good.ExternalKey),
+		escapeCSVFormula(good.Name),
+		escapeCSVFormula(good.Description),
+		strconv.Itoa(good.Priority),
+		good.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+func (c *csvGoodsWriter) Flush() error {
+	// Заголовок записывается и для проекта без товаров
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvGoodsWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+
+	header := append([]string{utf8BOM + csvExportColumns[0]}, csvExportColumns[1:]...)
+	return c.w.Write(header)
+}
+
+// needsCSVFormulaEscape Проверяет, что табличный редактор примет ячейку за формулу
+// или что ячейка сама начинается с экранирующего апострофа
+func needsCSVFormulaEscape(value string) bool {
+	if value == "" {
+		return false
+	}
+	if value[0] == '\'' {
+		return needsCSVFormulaEscape(value[1:])
+	}
+
+	return strings.IndexByte(csvFormulaPrefixes, value[0]) >= 0
+}
+
+// escapeCSVFormula Добавляет апостроф перед значением, которое табличный редактор
+// выполнил бы как формулу. unescapeCSVFormula убирает его при импорте
+func escapeCSVFormula(value string) string {
+	if needsCSVFormulaEscape(value) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// unescapeCSVFormula Убирает апостроф, добавленный escapeCSVFormula
+func unescapeCSVFormula(value string) string {
+	if strings.HasPrefix(value, "'") && needsCSVFormulaEscape(value[1:]) {
+		return value[1:]
+	}
+
+	return value
+}
+
+// ndjsonGoodsWriter Записывает товары по одному JSON-объекту в строке
+type ndjsonGoodsWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONGoodsWriter(w io.Writer) *ndjsonGoodsWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonGoodsWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (n *ndjsonGoodsWriter) Write(good *models.Good) error {
+	return n.enc.Encode(good)
+}
+
+func (n *ndjsonGoodsWriter) Flush() error {
+	return n.buf.Flush()
+}
+
+// goodsReader Читает строки файла импорта. Ошибка отдельной строки возвращается
+// в ImportRow.Err, ошибка чтения файла целиком - вторым значением, io.EOF - конец файла
+type goodsReader interface {
+	Read() (*models.ImportRow, error)
+}
+
+// csvGoodsReader Читает товары из CSV. Колонки определяются по заголовку
+type csvGoodsReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVGoodsReader(r io.Reader) (*csvGoodsReader, error) {
+	buf := bufio.NewReader(r)
+	if prefix, err := buf.Peek(len(utf8BOM)); err == nil && string(prefix) == utf8BOM {
+		buf.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buf)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV header is required")
+		}
+		return nil, fmt.Errorf("Invalid CSV header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, required := range []string{"externalkey", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV column %s is required", required)
+		}
+	}
+
+	return &csvGoodsReader{r: reader, columns: columns}, nil
+}
+
+func (c *csvGoodsReader) Read() (*models.ImportRow, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return &models.ImportRow{Line: parseErr.StartLine, Err: errors.New("Invalid CSV row")}, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+	}
+
+	line, _ := c.r.FieldPos(0)
+	field := func(name string) string {
+		i, ok := c.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return unescapeCSVFormula(record[i])
+	}
+
+	return &models.ImportRow{
+		Line: line,
+		Good: models.Good{
+			ExternalKey: strings.TrimSpace(field("externalkey")),
+			Name:        strings.TrimSpace(field("name")),
+			Description: field("description"),
+		},
+	}, nil
+}
+
+// ndjsonGoodsReader Читает товары из NDJSON, пустые строки пропускаются
+type ndjsonGoodsReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONGoodsReader(r io.Reader) *ndjsonGoodsReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+	return &ndjsonGoodsReader{scanner: scanner}
+}
+
+func (n *ndjsonGoodsReader) Read() (*models.ImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+
+		data := n.scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		var good models.Good
+		if err := json.Unmarshal(data, &good); err != nil {
+			return &models.ImportRow{Line: n.line, Err: errors.New("Invalid JSON")}, nil
+		}
+
+		return &models.ImportRow{
+			Line: n.line,
+			Good: models.Good{
+				ExternalKey: strings.TrimSpace(good.ExternalKey),
+				Name:        strings.TrimSpace(good.Name),
+				Description: good.Description,
+			},
+		}, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidImportFile, err)
+	}
+
+	return nil, io.EOF
+}
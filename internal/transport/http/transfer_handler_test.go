@@ -0,0 +1,252 @@
+package http
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"goods-service/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// importLines Возвращает номера строк с ошибками из ответа импорта
+func importLines(t *testing.T, body []byte) (ImportResponse, []int) {
+	t.Helper()
+
+	var resp ImportResponse
+	decode(t, body, &resp)
+
+	lines := make([]int, 0, len(resp.Errors))
+	for _, lineErr := range resp.Errors {
+		lines = append(lines, lineErr.Line)
+	}
+	return resp, lines
+}
+
+func TestHandler_ExportGoods(t *testing.T) {
+	env := newTestEnv(t)
+	goods := []models.Good{
+		{ExternalKey: "sku-1", Name: "first", Description: "with, comma"},
+		{Name: "second"},
+		{ExternalKey: "sku-3", Name: "third", Description: "multi\nline"},
+		{ExternalKey: "@sku-4", Name: "=HYPERLINK(\"http://example.com\")", Description: "'-1"},
+	}
+	if _, err := env.repo.CreateGoods(context.Background(), 1, goods, true); err != nil {
+		t.Fatalf("failed to create goods: %v", err)
+	}
+	if err := env.repo.MarkAsRemoved(context.Background(), &goods[1]); err != nil {
+		t.Fatalf("failed to remove good: %v", err)
+	}
+
+	export := func(t *testing.T, target string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		rec := httptest.NewRecorder()
+		env.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		rec := export(t, "/api/v1/goods/export?projectId=1")
+		if contentType := rec.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+			t.Errorf("unexpected content type %s", contentType)
+		}
+
+		body := rec.Body.String()
+		if !strings.HasPrefix(body, utf8BOM) {
+			t.Error("expected CSV to start with UTF-8 BOM")
+		}
+
+		records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(body, utf8BOM))).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to parse CSV: %v", err)
+		}
+		if len(records) != 4 || strings.Join(records[0], ",") != strings.Join(csvExportColumns, ",") {
+			t.Fatalf("unexpected records: %q", records)
+		}
+		if records[1][1] != "sku-1" || records[1][3] != "with, comma" || records[2][2] != "third" || records[2][3] != "multi\nline" {
+			t.Errorf("unexpected records: %q", records)
+		}
+		// Текст, похожий на формулу, экранируется апострофом
+		if records[3][1] != "'@sku-4" || records[3][2] != `'=HYPERLINK("http://example.com")` || records[3][3] != "''-1" {
+			t.Errorf("expected formulas to be escaped, got %q", records[3])
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		rec := export(t, "/api/v1/goods/export?projectId=1&format=ndjson")
+		if contentType := rec.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+			t.Errorf("unexpected content type %s", contentType)
+		}
+
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 lines, got %q", lines)
+		}
+		var good models.Good
+		if err := json.Unmarshal([]byte(lines[1]), &good); err != nil || good.ExternalKey != "sku-3" {
+			t.Errorf("unexpected line %s: %v", lines[1], err)
+		}
+	})
+
+	t.Run("empty project", func(t *testing.T) {
+		project := models.Project{Name: "empty"}
+		if err := env.repo.CreateProject(context.Background(), &project); err != nil {
+			t.Fatal(err)
+		}
+
+		rec := export(t, fmt.Sprintf("/api/v1/goods/export?projectId=%d&format=csv", project.ID))
+		if !strings.Contains(rec.Body.String(), "externalKey") {
+			t.Error("expected CSV header")
+		}
+	})
+
+	env.run(t, []handlerCase{
+		{
+			name:       "unknown project",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/export?projectId=100",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+		{
+			name:       "unknown format",
+			method:     http.MethodGet,
+			target:     "/api/v1/goods/export?projectId=1&format=xlsx",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+	})
+}
+
+func TestHandler_ImportGoods(t *testing.T) {
+	env := newTestEnv(t)
+	goods := []models.Good{
+		{ExternalKey: "sku-1", Name: "first"},
+		{ExternalKey: "sku-2", Name: "second"},
+		{ExternalKey: "sku-removed", Name: "removed"},
+	}
+	if _, err := env.repo.CreateGoods(context.Background(), 1, goods, true); err != nil {
+		t.Fatalf("failed to create goods: %v", err)
+	}
+	if err := env.repo.MarkAsRemoved(context.Background(), &goods[2]); err != nil {
+		t.Fatalf("failed to remove good: %v", err)
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:   "errors by line",
+			method: http.MethodPost,
+			target: "/api/v1/goods/import?projectId=1",
+			body: "externalKey,name\n" +
+				"sku-new,new\n" +
+				",no key\n" +
+				"sku-new,duplicate\n" +
+				"sku-removed,again\n" +
+				"sku-empty,\n",
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				resp, lines := importLines(t, body)
+				if resp.Applied || resp.ErrorCount != 4 || !equalInts(lines, []int{3, 4, 5, 6}) {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:   "upsert by external key",
+			method: http.MethodPost,
+			target: "/api/v1/goods/import?projectId=1",
+			body: utf8BOM + "id,externalKey,name,description\n" +
+				"1,sku-1,first,\n" +
+				"2,sku-2,renamed,\"multi\nline\"\n" +
+				",sku-3,third,new\n",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, _ := importLines(t, body)
+				if !resp.Applied || resp.Created != 1 || resp.Updated != 1 || resp.Unchanged != 1 || resp.ErrorCount != 0 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:   "ndjson",
+			method: http.MethodPost,
+			target: "/api/v1/goods/import?projectId=1&format=ndjson",
+			body: `{"externalKey":"sku-4","name":"fourth"}` + "\n\n" +
+				`{"externalKey":"sku-1","name":"first","description":"changed"}` + "\n",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				resp, _ := importLines(t, body)
+				if !resp.Applied || resp.Created != 1 || resp.Updated != 1 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "invalid json line",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/import?projectId=1&format=ndjson",
+			body:       `{"externalKey":"sku-5","name":"fifth"}` + "\n" + `{"externalKey":` + "\n",
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				if resp, lines := importLines(t, body); resp.Applied || !equalInts(lines, []int{2}) {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "escaped formulas",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/import?projectId=1",
+			body:       "externalKey,name,description\n'@sku-5,'=1+1,'hello\n",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				if resp, _ := importLines(t, body); !resp.Applied || resp.Created != 1 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "missing column",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/import?projectId=1",
+			body:       "name,description\nfirst,\n",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   4,
+		},
+		{
+			name:       "unknown project",
+			method:     http.MethodPost,
+			target:     "/api/v1/goods/import?projectId=100",
+			body:       "externalKey,name\nsku-1,first\n",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   3,
+		},
+	})
+
+	// Новые товары получают приоритеты в конце списка в порядке строк
+	stored, err := env.repo.ListGoods(context.Background(), models.GoodsFilter{ProjectID: 1, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(stored))
+	for _, good := range stored {
+		keys = append(keys, good.ExternalKey)
+	}
+	if strings.Join(keys, ",") != "sku-1,sku-2,sku-3,sku-4,@sku-5" {
+		t.Errorf("unexpected goods order %v", keys)
+	}
+	if stored[1].Name != "renamed" || stored[1].Description != "multi\nline" || stored[0].Description != "changed" {
+		t.Errorf("unexpected imported goods: %+v", stored)
+	}
+	// Апостроф убирается только перед текстом, похожим на формулу
+	if stored[4].Name != "=1+1" || stored[4].Description != "'hello" {
+		t.Errorf("unexpected unescaped good: %+v", stored[4])
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"errors"
+	"goods-service/internal/models"
+	"net/http"
+)
+
+// Каталог ошибок API v2. Ответ с ошибкой содержит машиночитаемый code,
+// текст message для человека и details со списком ошибок отдельных полей
+const (
+	// CodeInvalidRequest 400 Тело запроса не разбирается как JSON, либо некорректен
+	// параметр пути, параметр запроса или заголовок. Поле указывается в details
+	CodeInvalidRequest = "invalid_request"
+	// CodeUnauthenticated 401 Запрос без API ключа или токена, либо они не прошли проверку
+	CodeUnauthenticated = "unauthenticated"
+	// CodeForbidden 403 У клиента нет доступа к проекту на чтение или изменение
+	CodeForbidden = "forbidden"
+	// CodeRouteNotFound 404 Нет обработчика для пути запроса
+	CodeRouteNotFound = "route_not_found"
+	// CodeProjectNotFound 404 Проект не найден
+	CodeProjectNotFound = "project_not_found"
+	// CodeGoodNotFound 404 Товар не найден в проекте
+	CodeGoodNotFound = "good_not_found"
+	// CodeMethodNotAllowed 405 Путь не поддерживает метод запроса
+	CodeMethodNotAllowed = "method_not_allowed"
+	// CodeExternalKeyConflict 409 Товар с таким внешним ключом уже есть в проекте
+	CodeExternalKeyConflict = "external_key_conflict"
+	// CodeIdempotencyKeyInProgress 409 Запрос с тем же Idempotency-Key ещё выполняется,
+	// повторить его можно через Retry-After секунд
+	CodeIdempotencyKeyInProgress = "idempotency_key_in_progress"
+	// CodeVersionMismatch 412 Версия товара из If-Match устарела
+	CodeVersionMismatch = "version_mismatch"
+	// CodeUnsupportedMediaType 415 Тело запроса передано в неподдерживаемом формате
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	// CodeValidationFailed 422 Значения полей тела запроса не прошли проверку,
+	// ошибки полей перечислены в details
+	CodeValidationFailed = "validation_failed"
+	// CodeIdempotencyKeyReused 422 Idempotency-Key уже использован запросом с другими параметрами
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	// CodePreconditionRequired 428 Запрос изменения товара не содержит заголовка If-Match
+	CodePreconditionRequired = "precondition_required"
+	// CodeRateLimited 429 Клиент или проект исчерпал лимит запросов,
+	// повторить запрос можно через Retry-After секунд
+	CodeRateLimited = "rate_limited"
+	// CodeInternal 500 Внутренняя ошибка сервиса, запрос можно повторить
+	CodeInternal = "internal_error"
+)
+
+// Причины ошибок полей в details
+const (
+	FieldCodeRequired   = "required"     // Поле обязательно
+	FieldCodeTooLong    = "too_long"     // Значение длиннее допустимого
+	FieldCodeInvalid    = "invalid"      // Значение имеет неверный тип или формат
+	FieldCodeOutOfRange = "out_of_range" // Число вне допустимого диапазона
+	FieldCodeDuplicate  = "duplicate"    // Значение уже занято
+)
+
+// APIError Ответ API v2 с ошибкой
+type APIError struct {
+	Status  int          `json:"-"`
+	Code    string       `json:"code"`              // Код из каталога ошибок
+	Message string       `json:"message"`           // Описание ошибки
+	Details []FieldError `json:"details,omitempty"` // Ошибки отдельных полей
+}
+
+// FieldError Ошибка значения поля тела запроса, параметра или заголовка.
+// Message совпадает с текстом ошибки API v1
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// newFieldError Создаёт ошибку значения поля
+func newFieldError(field, code, message string) *FieldError {
+	return &FieldError{Field: field, Code: code, Message: message}
+}
+
+var (
+	errUnauthenticated  = &APIError{Status: http.StatusUnauthorized, Code: CodeUnauthenticated, Message: "Authentication required"}
+	errForbidden        = &APIError{Status: http.StatusForbidden, Code: CodeForbidden, Message: "Access to the project is denied"}
+	errRouteNotFound    = &APIError{Status: http.StatusNotFound, Code: CodeRouteNotFound, Message: "Route not found"}
+	errProjectNotFound  = &APIError{Status: http.StatusNotFound, Code: CodeProjectNotFound, Message: "Project not found"}
+	errGoodNotFound     = &APIError{Status: http.StatusNotFound, Code: CodeGoodNotFound, Message: "Good not found"}
+	errMethodNotAllowed = &APIError{Status: http.StatusMethodNotAllowed, Code: CodeMethodNotAllowed, Message: "Method not allowed"}
+	errRateLimited      = &APIError{Status: http.StatusTooManyRequests, Code: CodeRateLimited, Message: "Rate limit exceeded"}
+	errInternal         = &APIError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "Internal server error"}
+)
+
+// invalidRequest Ошибка разбора запроса. err типа *FieldError попадает в details
+func invalidRequest(err error) *APIError {
+	apiErr := &APIError{Status: http.StatusBadRequest, Code: CodeInvalidRequest, Message: err.Error()}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		apiErr.Details = []FieldError{*fieldErr}
+	}
+
+	return apiErr
+}
+
+// validationFailed Ошибка проверки полей тела запроса
+func validationFailed(details ...FieldError) *APIError {
+	apiErr := &APIError{Status: http.StatusUnprocessableEntity, Code: CodeValidationFailed, Message: "Validation failed"}
+	if len(details) == 1 {
+		apiErr.Message = details[0].Message
+	}
+	apiErr.Details = details
+
+	return apiErr
+}
+
+// bodyError Ошибка разбора или проверки тела запроса: некорректный JSON
+// даёт invalid_request, ошибки значений полей - validation_failed
+func bodyError(err error) *APIError {
+	if errors.Is(err, errInvalidPayload) {
+		return invalidRequest(err)
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return validationFailed(*fieldErr)
+	}
+
+	apiErr := validationFailed()
+	apiErr.Message = err.Error()
+	return apiErr
+}
+
+// apiError Сопоставляет ошибку сервиса ошибке из каталога. Ошибка models.ErrNotFound
+// означает отсутствие ресурса notFound, неизвестные ошибки - внутреннюю ошибку
+func apiError(err error, notFound *APIError) *APIError {
+	var fieldErr *FieldError
+
+	switch {
+	case errors.Is(err, models.ErrNotFound) && notFound != nil:
+		return notFound
+	case errors.Is(err, models.ErrVersionMismatch):
+		return &APIError{Status: http.StatusPreconditionFailed, Code: CodeVersionMismatch, Message: "Good version has changed"}
+	case errors.Is(err, models.ErrDuplicateExternalKey):
+		return &APIError{
+			Status:  http.StatusConflict,
+			Code:    CodeExternalKeyConflict,
+			Message: "External key already exists",
+			Details: []FieldError{{Field: "externalKey", Code: FieldCodeDuplicate, Message: "External key already exists"}},
+		}
+	case errors.Is(err, models.ErrInvalidReorder):
+		return validationFailed(FieldError{
+			Field:   "ids",
+			Code:    FieldCodeInvalid,
+			Message: "Ids must list every active good of the project exactly once",
+		})
+	case errors.Is(err, models.ErrInvalidCursor):
+		return invalidRequest(newFieldError("cursor", FieldCodeInvalid, "Invalid cursor parameter"))
+	case errors.Is(err, models.ErrIdempotencyKeyInProgress):
+		return &APIError{Status: http.StatusConflict, Code: CodeIdempotencyKeyInProgress, Message: "Request with this Idempotency-Key is in progress"}
+	case errors.Is(err, models.ErrIdempotencyKeyReused):
+		return &APIError{Status: http.StatusUnprocessableEntity, Code: CodeIdempotencyKeyReused, Message: "Idempotency-Key was used with another request"}
+	case errors.Is(err, errIfMatchRequired):
+		return &APIError{Status: http.StatusPreconditionRequired, Code: CodePreconditionRequired, Message: "If-Match header is required"}
+	case errors.Is(err, errInvalidIfMatch):
+		return invalidRequest(newFieldError("If-Match", FieldCodeInvalid, "Invalid If-Match header"))
+	case errors.Is(err, errInvalidIdempotencyKey):
+		return invalidRequest(newFieldError("Idempotency-Key", FieldCodeTooLong, "Invalid Idempotency-Key header"))
+	case errors.Is(err, errInvalidPayload):
+		return invalidRequest(errInvalidPayload)
+	case errors.As(err, &fieldErr):
+		return invalidRequest(fieldErr)
+	}
+
+	return errInternal
+}
+
+// respondWithAPIError Отправляет ошибку API v2
+func respondWithAPIError(w http.ResponseWriter, err *APIError) {
+	respondWithJSON(w, err.Status, err)
+}
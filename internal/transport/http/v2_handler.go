@@ -0,0 +1,319 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"goods-service/internal/models"
+	"net/http"
+	"strconv"
+)
+
+// CreateGoodV2 Создаёт товар проекта. Ответ содержит ETag и Location нового товара
+func (h *Handler) CreateGoodV2(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getPathId(r, "projectId")
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	var good models.Good
+	if err := json.NewDecoder(r.Body).Decode(&good); err != nil {
+		respondWithAPIError(w, invalidRequest(errInvalidPayload))
+		return
+	}
+
+	if details := validateNewGood(&good); len(details) > 0 {
+		respondWithAPIError(w, validationFailed(details...))
+		return
+	}
+
+	good.ProjectID = projectId
+
+	if err := h.goodService.CreateGood(r.Context(), &good); err != nil {
+		respondWithAPIError(w, apiError(err, errProjectNotFound))
+		return
+	}
+
+	w.Header().Set("Location", goodLocation(&good))
+	setETag(w, &good)
+	respondWithJSON(w, http.StatusCreated, good)
+}
+
+// GetGoodV2 Возвращает товар проекта с версией в ETag
+func (h *Handler) GetGoodV2(w http.ResponseWriter, r *http.Request) {
+	projectId, id, ok := getGoodPath(w, r)
+	if !ok {
+		return
+	}
+
+	good, err := h.goodService.GetGood(r.Context(), id, projectId)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, errGoodNotFound))
+		return
+	}
+
+	setETag(w, good)
+	respondWithJSON(w, http.StatusOK, good)
+}
+
+// ListGoodsV2 Возвращает страницу товаров проекта. Параметры фильтрации,
+// сортировки и пагинации совпадают с API v1
+func (h *Handler) ListGoodsV2(w http.ResponseWriter, r *http.Request) {
+	projectId, ok := h.getProjectPath(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseGoodsFilter(r, projectId)
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	response, err := h.listGoods(r, filter)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// UpdateGoodV2 Частично обновляет товар по JSON merge patch. Требует If-Match
+func (h *Handler) UpdateGoodV2(w http.ResponseWriter, r *http.Request) {
+	projectId, id, ok := getGoodPath(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	if !isMergePatch(r) {
+		respondWithAPIError(w, &APIError{
+			Status:  http.StatusUnsupportedMediaType,
+			Code:    CodeUnsupportedMediaType,
+			Message: "Content-Type must be application/merge-patch+json",
+		})
+		return
+	}
+
+	patch, err := decodeGoodPatch(r)
+	if err != nil {
+		respondWithAPIError(w, bodyError(err))
+		return
+	}
+
+	good := models.Good{ID: id, ProjectID: projectId, Version: version}
+
+	if err := h.goodService.UpdateGood(r.Context(), &good, patch); err != nil {
+		respondWithAPIError(w, apiError(err, errGoodNotFound))
+		return
+	}
+
+	setETag(w, &good)
+	respondWithJSON(w, http.StatusOK, good)
+}
+
+// DeleteGoodV2 Отмечает товар удалённым. Требует If-Match, отвечает без тела
+func (h *Handler) DeleteGoodV2(w http.ResponseWriter, r *http.Request) {
+	projectId, id, ok := getGoodPath(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	if err := h.goodService.DeleteGood(r.Context(), id, projectId, version); err != nil {
+		respondWithAPIError(w, apiError(err, errGoodNotFound))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreGoodV2 Восстанавливает удалённый товар в конец списка
+func (h *Handler) RestoreGoodV2(w http.ResponseWriter, r *http.Request) {
+	projectId, id, ok := getGoodPath(w, r)
+	if !ok {
+		return
+	}
+
+	good, err := h.goodService.RestoreGood(r.Context(), id, projectId)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, errGoodNotFound))
+		return
+	}
+
+	setETag(w, good)
+	respondWithJSON(w, http.StatusOK, good)
+}
+
+// PriorityRequest Новая позиция товара в списке проекта
+type PriorityRequest struct {
+	Priority int `json:"priority"`
+}
+
+// ReprioritizeGoodV2 Перемещает товар на новую позицию. Требует If-Match
+func (h *Handler) ReprioritizeGoodV2(w http.ResponseWriter, r *http.Request) {
+	projectId, id, ok := getGoodPath(w, r)
+	if !ok {
+		return
+	}
+
+	version, err := getIfMatchVersion(r)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	var req PriorityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithAPIError(w, invalidRequest(errInvalidPayload))
+		return
+	}
+
+	if req.Priority < 1 {
+		respondWithAPIError(w, validationFailed(FieldError{
+			Field:   "priority",
+			Code:    FieldCodeOutOfRange,
+			Message: "Priority must be greater than 0",
+		}))
+		return
+	}
+
+	goods, err := h.goodService.ReprioritizeGood(r.Context(), id, projectId, req.Priority, version)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, errGoodNotFound))
+		return
+	}
+
+	for _, item := range goods.Priorities {
+		if item.ID == id {
+			setETag(w, &models.Good{Version: item.Version})
+		}
+	}
+	respondWithJSON(w, http.StatusOK, goods)
+}
+
+// ReorderGoodsV2 Меняет порядок товаров проекта полным списком id или списком перемещений
+func (h *Handler) ReorderGoodsV2(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getPathId(r, "projectId")
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	var req models.GoodsReorder
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithAPIError(w, invalidRequest(errInvalidPayload))
+		return
+	}
+
+	if err := validateGoodsReorder(req); err != nil {
+		respondWithAPIError(w, bodyError(err))
+		return
+	}
+
+	priorities, err := h.goodService.ReorderGoods(r.Context(), projectId, req)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, errProjectNotFound))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, priorities)
+}
+
+// GetGoodHistoryV2 Возвращает историю изменений товара в хронологическом порядке
+func (h *Handler) GetGoodHistoryV2(w http.ResponseWriter, r *http.Request) {
+	projectId, id, ok := getGoodPath(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseHistoryFilter(r, projectId, false)
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+	filter.GoodID = id
+
+	response, err := h.history(r, filter)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// validateNewGood Проверяет поля создаваемого товара и возвращает все найденные ошибки
+func validateNewGood(good *models.Good) []FieldError {
+	var details []FieldError
+
+	var fieldErr *FieldError
+	if errors.As(validateGoodName(good.Name), &fieldErr) {
+		details = append(details, *fieldErr)
+	}
+	if len([]rune(good.ExternalKey)) > maxExternalKeyLength {
+		details = append(details, FieldError{Field: "externalKey", Code: FieldCodeTooLong, Message: "External key is too long"})
+	}
+
+	return details
+}
+
+// getPathId Извлекает положительный целочисленный параметр пути
+func getPathId(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil || id <= 0 {
+		return 0, newFieldError(name, FieldCodeInvalid, "Invalid "+name+" parameter")
+	}
+
+	return id, nil
+}
+
+// getGoodPath Извлекает проект и товар из пути. При ошибке отправляет ответ
+// и возвращает ok = false
+func getGoodPath(w http.ResponseWriter, r *http.Request) (projectId, id int, ok bool) {
+	projectId, err := getPathId(r, "projectId")
+	if err == nil {
+		id, err = getPathId(r, "id")
+	}
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return 0, 0, false
+	}
+
+	return projectId, id, true
+}
+
+// getProjectPath Извлекает проект из пути и проверяет, что он существует.
+// При ошибке отправляет ответ и возвращает ok = false
+func (h *Handler) getProjectPath(w http.ResponseWriter, r *http.Request) (projectId int, ok bool) {
+	projectId, err := getPathId(r, "projectId")
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return 0, false
+	}
+
+	if _, err := h.projectService.GetProject(r.Context(), projectId); err != nil {
+		respondWithAPIError(w, apiError(err, errProjectNotFound))
+		return 0, false
+	}
+
+	return projectId, true
+}
+
+// goodLocation Путь товара в API v2
+func goodLocation(good *models.Good) string {
+	return fmt.Sprintf("/api/v2/projects/%d/goods/%d", good.ProjectID, good.ID)
+}
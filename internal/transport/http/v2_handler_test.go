@@ -0,0 +1,518 @@
+package http
+
+import (
+	"context"
+	"goods-service/internal/models"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// errorFields Возвращает ошибки полей ответа API v2 в виде field:code
+func errorFields(t *testing.T, body []byte) string {
+	t.Helper()
+
+	var apiErr APIError
+	decode(t, body, &apiErr)
+
+	fields := make([]string, 0, len(apiErr.Details))
+	for _, detail := range apiErr.Details {
+		fields = append(fields, detail.Field+":"+detail.Code)
+	}
+	return strings.Join(fields, ",")
+}
+
+// wantFields Проверяет ошибки полей ответа API v2
+func wantFields(fields string) func(t *testing.T, body []byte) {
+	return func(t *testing.T, body []byte) {
+		t.Helper()
+
+		if got := errorFields(t, body); got != fields {
+			t.Errorf("expected details %s, got %s", fields, got)
+		}
+	}
+}
+
+func TestHandlerV2_CreateGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+	if _, err := env.repo.CreateGoods(context.Background(), 1, []models.Good{{ExternalKey: "sku-1", Name: "keyed"}}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "created",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"third","description":"desc"}`,
+			wantStatus: http.StatusCreated,
+			wantHeader: map[string]string{"Location": "/api/v2/projects/1/goods/3", "ETag": `"1"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.ID != 3 || good.ProjectID != 1 || good.Priority != 3 {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "all invalid fields",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":" ","externalKey":"` + strings.Repeat("k", maxExternalKeyLength+1) + `"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:required,externalKey:too_long"),
+		},
+		{
+			name:       "duplicate external key",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"copy","externalKey":"sku-1"}`,
+			wantStatus: http.StatusConflict,
+			wantError:  CodeExternalKeyConflict,
+			check:      wantFields("externalKey:duplicate"),
+		},
+		{
+			name:       "unknown project",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/100/goods",
+			body:       `{"name":"good"}`,
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeProjectNotFound,
+		},
+		{
+			name:       "invalid project id",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/first/goods",
+			body:       `{"name":"good"}`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("projectId:invalid"),
+		},
+		{
+			name:       "invalid payload",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+		},
+	})
+}
+
+func TestHandlerV2_GetGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "found",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods/1",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"1"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.ID != 1 || good.Name != "first" {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "other project",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/2/goods/1",
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeGoodNotFound,
+		},
+		{
+			name:       "invalid id",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods/0",
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("id:invalid"),
+		},
+		{
+			name:       "unknown route",
+			method:     http.MethodGet,
+			target:     "/api/v2/goods/1",
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeRouteNotFound,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods/1",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantError:  CodeMethodNotAllowed,
+			wantHeader: map[string]string{"Allow": "GET, PATCH, DELETE"},
+		},
+	})
+}
+
+func TestHandlerV2_ListGoods(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second", "third")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "filtered page",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods?limit=2&order=desc",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp PaginatedResponse
+				decode(t, body, &resp)
+				if resp.Meta.Total != 3 || len(resp.Goods) != 2 || resp.Goods[0].Name != "third" {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "invalid parameter",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods?sortBy=unknown",
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("sortBy:invalid"),
+		},
+		{
+			name:       "invalid cursor",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/goods?cursor=broken",
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("cursor:invalid"),
+		},
+		{
+			name:       "unknown project",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/100/goods",
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeProjectNotFound,
+		},
+	})
+}
+
+func TestHandlerV2_UpdateGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "updated",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":"renamed"}`,
+			header:     map[string]string{"If-Match": `"1"`, "Content-Type": "application/merge-patch+json"},
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2"`},
+		},
+		{
+			name:       "stale version",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":"again"}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusPreconditionFailed,
+			wantError:  CodeVersionMismatch,
+		},
+		{
+			name:       "missing If-Match",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":"again"}`,
+			wantStatus: http.StatusPreconditionRequired,
+			wantError:  CodePreconditionRequired,
+		},
+		{
+			name:       "weak If-Match",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":"again"}`,
+			header:     ifMatch(`W/"2"`),
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("If-Match:invalid"),
+		},
+		{
+			name:       "null name",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":null}`,
+			header:     ifMatch(`"2"`),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:required"),
+		},
+		{
+			name:       "empty patch",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"priority":5}`,
+			header:     ifMatch(`"2"`),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+		},
+		{
+			name:       "unsupported content type",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/1",
+			body:       `{"name":"again"}`,
+			header:     map[string]string{"If-Match": `"2"`, "Content-Type": "application/json-patch+json"},
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantError:  CodeUnsupportedMediaType,
+		},
+		{
+			name:       "unknown good",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/1/goods/100",
+			body:       `{"name":"again"}`,
+			header:     ifMatch("*"),
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeGoodNotFound,
+		},
+	})
+}
+
+func TestHandlerV2_DeleteAndRestoreGood(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "stale version",
+			method:     http.MethodDelete,
+			target:     "/api/v2/projects/1/goods/1",
+			header:     ifMatch(`"5"`),
+			wantStatus: http.StatusPreconditionFailed,
+			wantError:  CodeVersionMismatch,
+		},
+		{
+			name:       "deleted",
+			method:     http.MethodDelete,
+			target:     "/api/v2/projects/1/goods/1",
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusNoContent,
+			check: func(t *testing.T, body []byte) {
+				if len(body) != 0 {
+					t.Errorf("expected empty body, got %s", body)
+				}
+			},
+		},
+		{
+			name:       "already deleted",
+			method:     http.MethodDelete,
+			target:     "/api/v2/projects/1/goods/1",
+			header:     ifMatch("*"),
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeGoodNotFound,
+		},
+		{
+			name:       "restored to the end",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods/1/restore",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"3"`},
+			check: func(t *testing.T, body []byte) {
+				var good models.Good
+				decode(t, body, &good)
+				if good.Removed || good.Priority != 3 {
+					t.Errorf("unexpected good: %+v", good)
+				}
+			},
+		},
+		{
+			name:       "restore unknown good",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods/100/restore",
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeGoodNotFound,
+		},
+	})
+}
+
+func TestHandlerV2_Priorities(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first", "second", "third")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "moved",
+			method:     http.MethodPut,
+			target:     "/api/v2/projects/1/goods/3/priority",
+			body:       `{"priority":1}`,
+			header:     ifMatch(`"1"`),
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"ETag": `"2"`},
+		},
+		{
+			name:       "priority out of range",
+			method:     http.MethodPut,
+			target:     "/api/v2/projects/1/goods/3/priority",
+			body:       `{"priority":0}`,
+			header:     ifMatch(`"2"`),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("priority:out_of_range"),
+		},
+		{
+			name:       "reordered",
+			method:     http.MethodPut,
+			target:     "/api/v2/projects/1/goods/order",
+			body:       `{"ids":[1,2,3]}`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp models.PriorityResponse
+				decode(t, body, &resp)
+				if len(resp.Priorities) != 3 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "incomplete order",
+			method:     http.MethodPut,
+			target:     "/api/v2/projects/1/goods/order",
+			body:       `{"ids":[1,2]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("ids:invalid"),
+		},
+		{
+			name:       "invalid move",
+			method:     http.MethodPut,
+			target:     "/api/v2/projects/1/goods/order",
+			body:       `{"moves":[{"id":1,"newPriority":2},{"id":2,"newPriority":0}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("moves[1].newPriority:out_of_range"),
+		},
+	})
+}
+
+func TestHandlerV2_Projects(t *testing.T) {
+	env := newTestEnv(t)
+	env.createGoods(t, "first")
+
+	env.run(t, []handlerCase{
+		{
+			name:       "created",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects",
+			body:       `{"name":"second"}`,
+			wantStatus: http.StatusCreated,
+			wantHeader: map[string]string{"Location": "/api/v2/projects/2"},
+		},
+		{
+			name:       "name required",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects",
+			body:       `{"name":" "}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeValidationFailed,
+			check:      wantFields("name:required"),
+		},
+		{
+			name:       "listed",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp ProjectsPaginatedResponse
+				decode(t, body, &resp)
+				if resp.Meta.Total != 2 || len(resp.Projects) != 2 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "renamed",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/2",
+			body:       `{"name":"renamed"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "rename unknown project",
+			method:     http.MethodPatch,
+			target:     "/api/v2/projects/100",
+			body:       `{"name":"renamed"}`,
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeProjectNotFound,
+		},
+		{
+			name:       "history of unknown project",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/100/history",
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeProjectNotFound,
+		},
+		{
+			name:       "invalid history parameter",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1/history?from=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantError:  CodeInvalidRequest,
+			check:      wantFields("from:invalid"),
+		},
+		{
+			name:       "deleted with goods",
+			method:     http.MethodDelete,
+			target:     "/api/v2/projects/1",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp DeleteProjectResponse
+				decode(t, body, &resp)
+				if !resp.Removed || resp.RemovedGoods != 1 {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "get deleted project",
+			method:     http.MethodGet,
+			target:     "/api/v2/projects/1",
+			wantStatus: http.StatusNotFound,
+			wantError:  CodeProjectNotFound,
+		},
+	})
+}
+
+func TestHandlerV2_Idempotency(t *testing.T) {
+	env := newTestEnv(t)
+	key := map[string]string{"Idempotency-Key": "create-1"}
+
+	env.run(t, []handlerCase{
+		{
+			name:       "first request",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"first"}`,
+			header:     key,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "replayed",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"first"}`,
+			header:     key,
+			wantStatus: http.StatusCreated,
+			wantHeader: map[string]string{"Idempotent-Replayed": "true", "Location": "/api/v2/projects/1/goods/1"},
+		},
+		{
+			name:       "reused with another body",
+			method:     http.MethodPost,
+			target:     "/api/v2/projects/1/goods",
+			body:       `{"name":"second"}`,
+			header:     key,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  CodeIdempotencyKeyReused,
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/json"
+	"goods-service/internal/models"
+	"net/http"
+	"strconv"
+)
+
+// CreateProjectV2 Создаёт проект. Ответ содержит Location нового проекта
+func (h *Handler) CreateProjectV2(w http.ResponseWriter, r *http.Request) {
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithAPIError(w, invalidRequest(errInvalidPayload))
+		return
+	}
+
+	name, err := validateProjectName(req.Name)
+	if err != nil {
+		respondWithAPIError(w, bodyError(err))
+		return
+	}
+
+	project := models.Project{Name: name}
+	if err := h.projectService.CreateProject(r.Context(), &project); err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	w.Header().Set("Location", "/api/v2/projects/"+strconv.Itoa(project.ID))
+	respondWithJSON(w, http.StatusCreated, project)
+}
+
+// GetProjectV2 Возвращает проект
+func (h *Handler) GetProjectV2(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getPathId(r, "projectId")
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	project, err := h.projectService.GetProject(r.Context(), projectId)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, errProjectNotFound))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, project)
+}
+
+// ListProjectsV2 Возвращает страницу проектов
+func (h *Handler) ListProjectsV2(w http.ResponseWriter, r *http.Request) {
+	response, err := h.listProjects(r)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+// RenameProjectV2 Переименовывает проект
+func (h *Handler) RenameProjectV2(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getPathId(r, "projectId")
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	var req ProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithAPIError(w, invalidRequest(errInvalidPayload))
+		return
+	}
+
+	name, err := validateProjectName(req.Name)
+	if err != nil {
+		respondWithAPIError(w, bodyError(err))
+		return
+	}
+
+	project := models.Project{ID: projectId, Name: name}
+	if err := h.projectService.RenameProject(r.Context(), &project); err != nil {
+		respondWithAPIError(w, apiError(err, errProjectNotFound))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, project)
+}
+
+// DeleteProjectV2 Удаляет проект вместе с его товарами
+func (h *Handler) DeleteProjectV2(w http.ResponseWriter, r *http.Request) {
+	projectId, err := getPathId(r, "projectId")
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	removedGoods, err := h.projectService.DeleteProject(r.Context(), projectId)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, errProjectNotFound))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, DeleteProjectResponse{
+		Id:           projectId,
+		Removed:      true,
+		RemovedGoods: removedGoods,
+	})
+}
+
+// GetProjectHistoryV2 Возвращает ленту последних изменений всех товаров проекта
+func (h *Handler) GetProjectHistoryV2(w http.ResponseWriter, r *http.Request) {
+	projectId, ok := h.getProjectPath(w, r)
+	if !ok {
+		return
+	}
+
+	filter, err := parseHistoryFilter(r, projectId, true)
+	if err != nil {
+		respondWithAPIError(w, invalidRequest(err))
+		return
+	}
+
+	response, err := h.history(r, filter)
+	if err != nil {
+		respondWithAPIError(w, apiError(err, nil))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, response)
+}
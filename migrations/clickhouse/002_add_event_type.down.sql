@@ -0,0 +1,8 @@
+ALTER TABLE goods_log
+    DROP COLUMN IF EXISTS EventType,
+    DROP COLUMN IF EXISTS ChangedFields,
+    DROP COLUMN IF EXISTS PrevName,
+    DROP COLUMN IF EXISTS PrevDescription,
+    DROP COLUMN IF EXISTS PrevPriority,
+    DROP COLUMN IF EXISTS PrevRemoved,
+    DROP COLUMN IF EXISTS LoggedAt;
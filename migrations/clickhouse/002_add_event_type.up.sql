@@ -0,0 +1,8 @@
+ALTER TABLE goods_log
+    ADD COLUMN IF NOT EXISTS EventType LowCardinality(String) DEFAULT '',
+    ADD COLUMN IF NOT EXISTS ChangedFields Array(String),
+    ADD COLUMN IF NOT EXISTS PrevName Nullable(String),
+    ADD COLUMN IF NOT EXISTS PrevDescription Nullable(String),
+    ADD COLUMN IF NOT EXISTS PrevPriority Nullable(Int32),
+    ADD COLUMN IF NOT EXISTS PrevRemoved Nullable(Boolean),
+    ADD COLUMN IF NOT EXISTS LoggedAt DateTime DEFAULT now();
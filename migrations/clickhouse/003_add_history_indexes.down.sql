@@ -0,0 +1,3 @@
+ALTER TABLE goods_log
+    DROP INDEX IF EXISTS idx_project_id,
+    DROP INDEX IF EXISTS idx_id;
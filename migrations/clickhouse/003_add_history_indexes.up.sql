@@ -0,0 +1,3 @@
+ALTER TABLE goods_log
+    ADD INDEX IF NOT EXISTS idx_project_id ProjectId TYPE bloom_filter GRANULARITY 4,
+    ADD INDEX IF NOT EXISTS idx_id Id TYPE bloom_filter GRANULARITY 4;
@@ -0,0 +1,2 @@
+ALTER TABLE goods_log
+    DROP COLUMN IF EXISTS Actor;
@@ -0,0 +1,2 @@
+ALTER TABLE goods_log
+    ADD COLUMN IF NOT EXISTS Actor String DEFAULT '';
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS goods_log_dedup (
+    EventId UUID,
+    Id Int32,
+    ProjectId Int32,
+    Name String,
+    Description String,
+    Priority Int32,
+    Removed Boolean,
+    EventTime DateTime,
+    EventType LowCardinality(String) DEFAULT '',
+    ChangedFields Array(String),
+    PrevName Nullable(String),
+    PrevDescription Nullable(String),
+    PrevPriority Nullable(Int32),
+    PrevRemoved Nullable(Boolean),
+    LoggedAt DateTime DEFAULT now(),
+    Actor String DEFAULT '',
+    INDEX idx_project_id ProjectId TYPE bloom_filter GRANULARITY 4,
+    INDEX idx_id Id TYPE bloom_filter GRANULARITY 4
+) ENGINE = ReplacingMergeTree()
+ORDER BY (EventTime, Id, EventId);
+
+INSERT INTO goods_log_dedup
+SELECT
+    generateUUIDv4(), Id, ProjectId, Name, Description, Priority, Removed, EventTime,
+    EventType, ChangedFields, PrevName, PrevDescription, PrevPriority, PrevRemoved, LoggedAt, Actor
+FROM goods_log;
+
+RENAME TABLE goods_log TO goods_log_old, goods_log_dedup TO goods_log;
+
+DROP TABLE goods_log_old;
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS removed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_goods_project_priority ON goods(project_id, priority, id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_goods_removed_at;
+ALTER TABLE goods DROP COLUMN IF EXISTS removed_at;
@@ -0,0 +1,6 @@
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS removed_at TIMESTAMP;
+
+-- Срок хранения ранее удалённых записей отсчитывается с момента миграции
+UPDATE goods SET removed_at = NOW() WHERE removed AND removed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_goods_removed_at ON goods(removed_at) WHERE removed;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_goods_project_external_key;
+ALTER TABLE goods DROP COLUMN IF EXISTS external_key;
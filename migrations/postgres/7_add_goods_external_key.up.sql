@@ -0,0 +1,4 @@
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS external_key VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Внешний ключ уникален в проекте, товары без ключа не ограничиваются
+CREATE UNIQUE INDEX IF NOT EXISTS idx_goods_project_external_key ON goods(project_id, external_key) WHERE external_key <> '';
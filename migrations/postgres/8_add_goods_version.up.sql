@@ -0,0 +1,2 @@
+-- Версия товара для оптимистичной блокировки, увеличивается при каждом изменении строки
+ALTER TABLE goods ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
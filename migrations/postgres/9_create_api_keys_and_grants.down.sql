@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS project_grants;
+DROP TABLE IF EXISTS api_keys;
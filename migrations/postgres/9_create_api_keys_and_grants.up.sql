@@ -0,0 +1,18 @@
+-- API ключи клиентов. Хранится только SHA-256 хэш ключа в hex
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP
+);
+
+-- Доступ клиентов к проектам. subject - владелец API ключа или sub токена JWT,
+-- project_id 0 - все проекты. Доступ write включает read
+CREATE TABLE IF NOT EXISTS project_grants (
+    subject VARCHAR(255) NOT NULL,
+    project_id INTEGER NOT NULL DEFAULT 0,
+    access VARCHAR(8) NOT NULL CHECK (access IN ('read', 'write')),
+    PRIMARY KEY (subject, project_id)
+);